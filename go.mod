@@ -3,9 +3,11 @@ module github.com/trevex/terraform-provider-ldap
 go 1.15
 
 require (
-	github.com/go-ldap/ldap/v3 v3.2.4
+	github.com/go-asn1-ber/asn1-ber v1.5.7
+	github.com/go-ldap/ldap/v3 v3.4.10
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.0.4
-	golang.org/x/text v0.3.3
+	golang.org/x/crypto v0.31.0
+	golang.org/x/text v0.21.0
 )
 
 replace google.golang.org/api v0.28.0 => github.com/googleapis/google-api-go-client v0.28.0
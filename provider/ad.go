@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// adBaseDN returns the DN the Active Directory lookup data sources search
+// under: the configured base_dn, the whole forest when querying a global
+// catalog, or else the domain of the server.
+func adBaseDN(client ldap.Client, d *schema.ResourceData) (string, error) {
+	if baseDN, ok := d.GetOk("base_dn"); ok {
+		return baseDN.(string), nil
+	}
+	if d.Get("global_catalog").(bool) {
+		return "", nil
+	}
+	rootDSE, err := readRootDSE(client, "defaultNamingContext")
+	if err != nil {
+		return "", err
+	}
+	if rootDSE == nil || rootDSE.GetEqualFoldAttributeValue("defaultNamingContext") == "" {
+		return "", fmt.Errorf("the root DSE has no defaultNamingContext; set base_dn explicitly")
+	}
+	return rootDSE.GetEqualFoldAttributeValue("defaultNamingContext"), nil
+}
+
+// adLookupSchema adds the arguments read by adBaseDN to a schema.
+func adLookupSchema(s map[string]*schema.Schema) map[string]*schema.Schema {
+	s["base_dn"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Description:   "The DN to search under; defaults to the domain of the server.",
+		Optional:      true,
+		ConflictsWith: []string{"global_catalog"},
+	}
+	s["global_catalog"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Whether to search the whole forest, when the provider is connected to a global catalog (port 3268 or 3269).",
+		Optional:    true,
+		Default:     false,
+	}
+	return s
+}
+
+// adObjectIdentifiers returns the textual objectGUID and objectSid of an
+// entry, empty when missing.
+func adObjectIdentifiers(entry *ldap.Entry) (guid, sid string, err error) {
+	if b := entry.GetEqualFoldRawAttributeValue("objectGUID"); len(b) > 0 {
+		if guid, err = util.GUIDToString(b); err != nil {
+			return "", "", err
+		}
+	}
+	if b := entry.GetEqualFoldRawAttributeValue("objectSid"); len(b) > 0 {
+		if sid, err = util.SIDToString(b); err != nil {
+			return "", "", err
+		}
+	}
+	return guid, sid, nil
+}
+
+// adFlagNames returns the sorted names of the flags set in a bit field such
+// as userAccountControl, given the values of the flags by name.
+func adFlagNames(value int, flags map[string]int) []string {
+	names := []string{}
+	for name, flag := range flags {
+		if value&flag != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// the well-known binary attributes of Active Directory holding GUIDs or SIDs,
+// by lowercase name
+var adBinaryAttributeDecoders = map[string]func([]byte) (string, error){
+	"objectguid":            util.GUIDToString,
+	"schemaidguid":          util.GUIDToString,
+	"attributesecurityguid": util.GUIDToString,
+	"ms-ds-consistencyguid": util.GUIDToString,
+	"msexchmailboxguid":     util.GUIDToString,
+	"objectsid":             util.SIDToString,
+	"sidhistory":            util.SIDToString,
+	"tokengroups":           util.SIDToString,
+	"securityidentifier":    util.SIDToString,
+}
+
+// decodeADBinaryAttributes replaces the string values of the well-known
+// binary attributes of an entry with their text form (e.g. S-1-5-21-...),
+// leaving the raw bytes in place and values that do not decode unchanged.
+func decodeADBinaryAttributes(entry *ldap.Entry) {
+	for _, attribute := range entry.Attributes {
+		decode, ok := adBinaryAttributeDecoders[strings.ToLower(attribute.Name)]
+		if !ok || len(attribute.ByteValues) != len(attribute.Values) {
+			continue
+		}
+		for i, b := range attribute.ByteValues {
+			if decoded, err := decode(b); err == nil {
+				attribute.Values[i] = decoded
+			}
+		}
+	}
+}
+
+// hasRangedAttributes returns whether Active Directory returned some of the
+// attributes of an entry in ranges, see readRangedAttributes.
+func hasRangedAttributes(entry *ldap.Entry) bool {
+	for _, attribute := range entry.Attributes {
+		if _, _, _, ok := util.ParseAttributeRange(attribute.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readRangedAttributes completes the attributes of an entry that Active
+// Directory returned in ranges (e.g. member;range=0-1499), as it does for
+// values beyond its MaxValRange, by reading the following ranges from the
+// server until the last one; the values are then stored under the attribute
+// name without the range option, so that large groups are not truncated.
+func readRangedAttributes(client ldap.Client, entry *ldap.Entry) error {
+	for i, attribute := range entry.Attributes {
+		name, _, high, ok := util.ParseAttributeRange(attribute.Name)
+		if !ok {
+			continue
+		}
+		values, byteValues := attribute.Values, attribute.ByteValues
+		for high >= 0 {
+			next := fmt.Sprintf("%s;range=%d-*", name, high+1)
+			log.Printf("[DEBUG] reading %s of %q", next, entry.DN)
+			request := ldap.NewSearchRequest(
+				entry.DN,
+				ldap.ScopeBaseObject,
+				ldap.NeverDerefAliases,
+				0,
+				0,
+				false,
+				"(objectClass=*)",
+				[]string{next},
+				nil,
+			)
+			sr, err := client.Search(request)
+			if err != nil {
+				return fmt.Errorf("error reading %s of %q: %v", next, entry.DN, err)
+			}
+			if len(sr.Entries) == 0 {
+				break
+			}
+			last := high
+			high = -1
+			for _, ranged := range sr.Entries[0].Attributes {
+				if rangedName, low, rangedHigh, ok := util.ParseAttributeRange(ranged.Name); ok && strings.EqualFold(rangedName, name) && low == last+1 {
+					values = append(values, ranged.Values...)
+					byteValues = append(byteValues, ranged.ByteValues...)
+					high = rangedHigh
+				}
+			}
+		}
+		entry.Attributes[i] = &ldap.EntryAttribute{Name: name, Values: values, ByteValues: byteValues}
+	}
+	return nil
+}
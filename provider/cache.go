@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// the time entries that rarely change, the Root DSE and the subschema, are
+// cached for, so that large plans do not read them once per resource
+const ldapEntryCacheTTL = 5 * time.Minute
+
+type cachedLDAPEntry struct {
+	entry   *ldap.Entry
+	expires time.Time
+}
+
+// ldapEntryCache holds entries read with given attributes, by DN, and the
+// attribute rules parsed from the subschema, see readAttributeRules.
+type ldapEntryCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedLDAPEntry
+
+	rules       map[string]ldapAttributeRules
+	rulesSource *ldap.Entry // the cached subschema entry rules were parsed from
+}
+
+// readCachedEntry returns the entry read by read for dn and attributes,
+// from the cache of the connection pool of the provider while it is fresh.
+// Cached entries are shared and must not be modified.
+func readCachedEntry(client ldap.Client, dn string, attributes []string, read func() (*ldap.Entry, error)) (*ldap.Entry, error) {
+	pool, ok := client.(*ldapPool)
+	if !ok {
+		return read()
+	}
+	sorted := append([]string{}, attributes...)
+	sort.Strings(sorted)
+	key := strings.ToLower(dn + "\x00" + strings.Join(sorted, "\x00"))
+
+	pool.cache.mu.Lock()
+	cached, ok := pool.cache.entries[key]
+	pool.cache.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.entry, nil
+	}
+
+	entry, err := read()
+	if err != nil {
+		return nil, err
+	}
+	pool.cache.mu.Lock()
+	if pool.cache.entries == nil {
+		pool.cache.entries = map[string]cachedLDAPEntry{}
+	}
+	pool.cache.entries[key] = cachedLDAPEntry{entry: entry, expires: time.Now().Add(ldapEntryCacheTTL)}
+	pool.cache.mu.Unlock()
+	return entry, nil
+}
+
+// readRootDSE reads the given attributes of the Root DSE, through the cache;
+// it is nil when the server does not expose one.
+func readRootDSE(client ldap.Client, attributes ...string) (*ldap.Entry, error) {
+	return readCachedEntry(client, "", attributes, func() (*ldap.Entry, error) {
+		return readConfigEntry(client, "", attributes...)
+	})
+}
+
+// invalidate empties the cache on changes to the configuration of the
+// server or to its schema (under cn=config, cn=schema or AD's
+// CN=Schema,CN=Configuration), which may change the subschema or the
+// features advertised by the Root DSE.
+func (c *ldapEntryCache) invalidate(dn string) {
+	dn = strings.ToLower(strings.Replace(dn, " ", "", -1))
+	if !strings.Contains(dn, "cn=config") && !strings.Contains(dn, "cn=schema") {
+		return
+	}
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
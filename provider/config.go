@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// readConfigEntry reads the given attributes of an entry in cn=config (or any
+// other entry that must exist for a resource to be meaningful), returning a
+// nil entry if the entry does not exist.
+func readConfigEntry(client ldap.Client, dn string, attributes ...string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=*)",
+		attributes,
+		nil,
+	)
+
+	sr, err := client.Search(request)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := readRangedAttributes(client, sr.Entries[0]); err != nil {
+		return nil, err
+	}
+	return sr.Entries[0], nil
+}
+
+// the number of values sent at most in one modify request when changing
+// attributes with many values, well below the limits of Active Directory,
+// unless set with the modify_batch_size setting of the provider
+const ldapModifyBatchSize = 1000
+
+// modifyInBatches applies a modify request in batches of the configured
+// size, see splitModifyRequest; the batches already applied are kept when
+// one fails. Retries are left to the pool, which repeats a batch when the
+// server closes its connection and per the retry_policy of the provider.
+func modifyInBatches(client ldap.Client, modify *ldap.ModifyRequest) error {
+	size := ldapModifyBatchSize
+	if pool, ok := client.(*ldapPool); ok && pool.batchSize > 0 {
+		size = pool.batchSize
+	}
+	batches := splitModifyRequest(modify, size)
+	for i, batch := range batches {
+		if err := client.Modify(batch); err != nil {
+			if len(batches) > 1 {
+				return fmt.Errorf("error applying batch %d of %d to %q: %v", i+1, len(batches), modify.DN, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// splitModifyRequest splits a modify request into consecutive requests of at
+// most size values each, so that changing thousands of values (e.g. the
+// members of a large group) is not rejected by the server or timed out.
+// Only the values of the attribute with the most of them are spread over
+// several requests: the changes to the other attributes go whole into the
+// first one, applied atomically with them (e.g. an objectClass with the
+// attributes it requires). A replace split this way becomes a replace of its
+// first values followed by adds of the others. Smaller requests are returned
+// as they are.
+func splitModifyRequest(modify *ldap.ModifyRequest, size int) []*ldap.ModifyRequest {
+	count := func(change ldap.Change) int {
+		if len(change.Modification.Vals) == 0 {
+			return 1
+		}
+		return len(change.Modification.Vals)
+	}
+	total := 0
+	values := map[string]int{}
+	for _, change := range modify.Changes {
+		total += count(change)
+		values[strings.ToLower(change.Modification.Type)] += len(change.Modification.Vals)
+	}
+	if total <= size {
+		return []*ldap.ModifyRequest{modify}
+	}
+	large := ""
+	for attribute, n := range values {
+		if n > values[large] || (n == values[large] && attribute < large) {
+			large = attribute
+		}
+	}
+	split := func(change ldap.Change) bool {
+		return len(change.Modification.Vals) > 0 && strings.EqualFold(change.Modification.Type, large)
+	}
+
+	requests := []*ldap.ModifyRequest{ldap.NewModifyRequest(modify.DN, modify.Controls)}
+	room := size
+	for _, change := range modify.Changes {
+		if !split(change) {
+			room -= count(change)
+		}
+	}
+	if room < 0 {
+		room = 0
+	}
+	for _, change := range modify.Changes {
+		if !split(change) {
+			requests[0].Changes = append(requests[0].Changes, change)
+			continue
+		}
+		operation, values := change.Operation, change.Modification.Vals
+		for len(values) > 0 {
+			if room == 0 {
+				requests = append(requests, ldap.NewModifyRequest(modify.DN, modify.Controls))
+				room = size
+			}
+			n := room
+			if n > len(values) {
+				n = len(values)
+			}
+			current := requests[len(requests)-1]
+			current.Changes = append(current.Changes, ldap.Change{
+				Operation:    operation,
+				Modification: ldap.PartialAttribute{Type: change.Modification.Type, Vals: values[:n]},
+			})
+			room -= n
+			values = values[n:]
+			if operation == ldap.ReplaceAttribute {
+				operation = ldap.AddAttribute
+			}
+		}
+	}
+	return requests
+}
+
+// addOrderedDeltas adds to the modify request the operations required to turn
+// the old values of an X-ORDERED 'VALUES' attribute into the new ones; the
+// values must not carry the {n} index prefix. Values are deleted by index and
+// inserted at their final position, so rules that did not change (or only
+// moved because of their neighbours) are left untouched.
+func addOrderedDeltas(modify *ldap.ModifyRequest, attribute string, old, new []string) {
+	deletes, inserts := util.OrderedDiff(old, new)
+	for _, i := range deletes {
+		modify.Delete(attribute, []string{fmt.Sprintf("{%d}", i)})
+	}
+	for _, insert := range inserts {
+		modify.Add(attribute, []string{fmt.Sprintf("{%d}%s", insert.Index, insert.Value)})
+	}
+}
+
+// configAttributeSchema returns the schema of a set of name => values blocks,
+// used by the resources that only own some of the attributes of an entry.
+func configAttributeSchema(description, nameDescription string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Description: description,
+		Required:    true,
+		MinItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Description: nameDescription,
+					Required:    true,
+				},
+				"values": {
+					Type:        schema.TypeList,
+					Description: "The ordered values of the attribute.",
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Required:    true,
+					MinItems:    1,
+				},
+			},
+		},
+	}
+}
+
+// configAttributes converts a set of name => values blocks into a map.
+func configAttributes(set *schema.Set) map[string][]string {
+	attributes := map[string][]string{}
+	for _, attribute := range set.List() {
+		m := attribute.(map[string]interface{})
+		attributes[m["name"].(string)] = toStringSlice(m["values"].([]interface{}))
+	}
+	return attributes
+}
+
+// flattenConfigAttributes reads back the configured attributes from an entry
+// as a list of name => values blocks. Servers may change the case of keywords
+// (e.g. slapd turns "stats" into "Stats"), so values matching the configured
+// ones regardless of case are kept as configured.
+func flattenConfigAttributes(entry *ldap.Entry, configured map[string][]string) []interface{} {
+	attributes := []interface{}{}
+	for name, wanted := range configured {
+		values := util.SortOrderedValues(entry.GetEqualFoldAttributeValues(name))
+		if len(values) == 0 {
+			continue
+		}
+		for i := range values {
+			if i < len(wanted) && strings.EqualFold(values[i], wanted[i]) {
+				values[i] = wanted[i]
+			}
+		}
+		attributes = append(attributes, map[string]interface{}{
+			"name":   name,
+			"values": values,
+		})
+	}
+	return attributes
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadOlcModules makes sure slapd has loaded the given dynamic modules (e.g.
+// "memberof"), adding them to the first olcModuleList entry of cn=config, or
+// to a new cn=module entry if there is none. Modules are never unloaded, as
+// other parts of the configuration may depend on them.
+func loadOlcModules(client ldap.Client, modules ...string) error {
+	request := ldap.NewSearchRequest(
+		"cn=config",
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=olcModuleList)",
+		[]string{"olcModuleLoad"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		return err
+	}
+
+	loaded := map[string]bool{}
+	for _, entry := range sr.Entries {
+		for _, value := range entry.GetAttributeValues("olcModuleLoad") {
+			_, module := util.SplitOrderedValue(value)
+			module = strings.TrimSuffix(strings.TrimSuffix(path.Base(module), ".la"), ".so")
+			loaded[module] = true
+		}
+	}
+	missing := []string{}
+	for _, module := range modules {
+		if !loaded[module] {
+			missing = append(missing, module+".la")
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] ldap_olc::modules - loading modules %v", missing)
+
+	if len(sr.Entries) == 0 {
+		add := ldap.NewAddRequest("cn=module,cn=config", []ldap.Control{})
+		add.Attribute("objectClass", []string{"olcModuleList"})
+		add.Attribute("cn", []string{"module"})
+		add.Attribute("olcModuleLoad", missing)
+		return client.Add(add)
+	}
+	modify := ldap.NewModifyRequest(sr.Entries[0].DN, []ldap.Control{})
+	modify.Add("olcModuleLoad", missing)
+	return client.Modify(modify)
+}
+
+// findOlcOverlay returns the overlay entry of the given object class attached
+// to a database, whatever index the server assigned to it, or nil if the
+// overlay is not configured.
+func findOlcOverlay(client ldap.Client, databaseDN, objectClass string, attributes ...string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		databaseDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(objectClass=%s)", ldap.EscapeFilter(objectClass)),
+		attributes,
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(sr.Entries) == 0 {
+		return nil, nil
+	}
+	return sr.Entries[0], nil
+}
+
+// deleteOlcOverlay detaches an overlay from its database. Servers that cannot
+// remove overlays at runtime leave it in place with a warning.
+func deleteOlcOverlay(client ldap.Client, dn string) error {
+	if err := client.Del(ldap.NewDelRequest(dn, nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultUnwillingToPerform {
+			// slapd releases before 2.5 cannot remove overlays at runtime
+			log.Printf("[WARN] ldap_olc::overlay - server refused to remove overlay %q, it will stay attached: %v", dn, err)
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc::overlay - error removing overlay %q: %v", dn, err)
+		return err
+	}
+	return nil
+}
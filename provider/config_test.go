@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// values returns n distinct values starting with prefix.
+func values(prefix string, n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return result
+}
+
+// summarize describes the changes of each request as operation, attribute
+// and number of values.
+func summarize(requests []*ldap.ModifyRequest) [][]string {
+	operations := map[uint]string{ldap.AddAttribute: "add", ldap.DeleteAttribute: "delete", ldap.ReplaceAttribute: "replace"}
+	result := [][]string{}
+	for _, request := range requests {
+		changes := []string{}
+		for _, change := range request.Changes {
+			changes = append(changes, fmt.Sprintf("%s %s %d", operations[change.Operation], change.Modification.Type, len(change.Modification.Vals)))
+		}
+		result = append(result, changes)
+	}
+	return result
+}
+
+func TestSplitModifyRequest(t *testing.T) {
+	cases := []struct {
+		name     string
+		changes  func(modify *ldap.ModifyRequest)
+		size     int
+		expected [][]string
+	}{
+		{
+			name: "at size",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Add("member", values("cn=", 10))
+			},
+			size:     10,
+			expected: [][]string{{"add member 10"}},
+		},
+		{
+			name: "one over size",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Add("member", values("cn=", 11))
+			},
+			size:     10,
+			expected: [][]string{{"add member 10"}, {"add member 1"}},
+		},
+		{
+			name: "exact multiple of size",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Delete("member", values("cn=", 20))
+			},
+			size:     10,
+			expected: [][]string{{"delete member 10"}, {"delete member 10"}},
+		},
+		{
+			name: "replace then adds",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Replace("member", values("cn=", 25))
+			},
+			size:     10,
+			expected: [][]string{{"replace member 10"}, {"add member 10"}, {"add member 5"}},
+		},
+		{
+			name: "value-less deletes count as one",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Delete("description", nil)
+				modify.Delete("seeAlso", nil)
+				modify.Add("member", values("cn=", 9))
+			},
+			size:     10,
+			expected: [][]string{{"delete description 0", "delete seeAlso 0", "add member 8"}, {"add member 1"}},
+		},
+		{
+			name: "other attributes stay whole in the first request",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Add("member", values("cn=", 12))
+				modify.Add("objectClass", []string{"groupOfNames", "extensibleObject"})
+				modify.Replace("mail", values("group", 3))
+			},
+			size:     10,
+			expected: [][]string{{"add member 5", "add objectClass 2", "replace mail 3"}, {"add member 7"}},
+		},
+		{
+			name: "other attributes larger than size",
+			changes: func(modify *ldap.ModifyRequest) {
+				modify.Replace("memberUid", values("user", 12))
+				modify.Add("member", values("cn=", 15))
+			},
+			size:     10,
+			expected: [][]string{{"replace memberUid 12"}, {"add member 10"}, {"add member 5"}},
+		},
+	}
+	for _, c := range cases {
+		modify := ldap.NewModifyRequest("cn=group,dc=example,dc=com", nil)
+		c.changes(modify)
+		requests := splitModifyRequest(modify, c.size)
+		if summary := summarize(requests); !reflect.DeepEqual(summary, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, summary)
+		}
+		// the values are all sent once, in order
+		sent := map[string][]string{}
+		for _, request := range requests {
+			for _, change := range request.Changes {
+				sent[change.Modification.Type] = append(sent[change.Modification.Type], change.Modification.Vals...)
+			}
+		}
+		for _, change := range modify.Changes {
+			if len(change.Modification.Vals) > 0 && !reflect.DeepEqual(sent[change.Modification.Type], change.Modification.Vals) {
+				t.Errorf("%s: expected the values of %s to be sent once in order, got %v", c.name, change.Modification.Type, sent[change.Modification.Type])
+			}
+		}
+	}
+}
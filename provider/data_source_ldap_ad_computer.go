@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPADComputer() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPADComputerRead,
+
+		Schema: adLookupSchema(map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the computer to look up (e.g. WEB01), without the trailing $ of its sAMAccountName.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "dns_host_name"},
+			},
+			"dns_host_name": {
+				Type:        schema.TypeString,
+				Description: "The DNS host name of the computer to look up (e.g. web01.example.com).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"dn":                            computed(schema.TypeString, "The DN of the computer."),
+			"sam_account_name":              computed(schema.TypeString, "The sAMAccountName of the computer (e.g. WEB01$)."),
+			"object_guid":                   computed(schema.TypeString, "The objectGUID of the computer."),
+			"sid":                           computed(schema.TypeString, "The SID of the computer."),
+			"description":                   computed(schema.TypeString, "The description of the computer."),
+			"operating_system":              computed(schema.TypeString, "The operating system of the computer (e.g. Windows Server 2022 Datacenter)."),
+			"operating_system_version":      computed(schema.TypeString, "The version of the operating system (e.g. 10.0 (20348))."),
+			"operating_system_service_pack": computed(schema.TypeString, "The service pack of the operating system."),
+			"enabled":                       computed(schema.TypeBool, "Whether the account is enabled (ACCOUNTDISABLE not set)."),
+			"service_principal_names": {
+				Type:        schema.TypeList,
+				Description: "The service principal names of the computer, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}),
+	}
+}
+
+func dataSourceLDAPADComputerRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	baseDN, err := adBaseDN(client, d)
+	if err != nil {
+		return err
+	}
+	filter := "(&(objectClass=computer)"
+	if v, ok := d.GetOk("name"); ok {
+		filter += fmt.Sprintf("(sAMAccountName=%s$))", ldap.EscapeFilter(strings.TrimSuffix(v.(string), "$")))
+	} else {
+		filter += fmt.Sprintf("(dNSHostName=%s))", ldap.EscapeFilter(d.Get("dns_host_name").(string)))
+	}
+
+	log.Printf("[DEBUG] ldap_ad_computer::read - looking up %s under %q", filter, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, filter,
+		"sAMAccountName", "dNSHostName", "objectGUID", "objectSid", "description", "userAccountControl",
+		"operatingSystem", "operatingSystemVersion", "operatingSystemServicePack", "servicePrincipalName")
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_computer::read - error looking up computer: %v", err)
+		return err
+	}
+	guid, sid, err := adObjectIdentifiers(entry)
+	if err != nil {
+		return err
+	}
+	uac, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("userAccountControl"))
+	spns := entry.GetEqualFoldAttributeValues("servicePrincipalName")
+	sort.Strings(spns)
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("name", strings.TrimSuffix(entry.GetEqualFoldAttributeValue("sAMAccountName"), "$"))
+	d.Set("dns_host_name", entry.GetEqualFoldAttributeValue("dNSHostName"))
+	d.Set("sam_account_name", entry.GetEqualFoldAttributeValue("sAMAccountName"))
+	d.Set("object_guid", guid)
+	d.Set("sid", sid)
+	d.Set("description", entry.GetEqualFoldAttributeValue("description"))
+	d.Set("operating_system", entry.GetEqualFoldAttributeValue("operatingSystem"))
+	d.Set("operating_system_version", entry.GetEqualFoldAttributeValue("operatingSystemVersion"))
+	d.Set("operating_system_service_pack", entry.GetEqualFoldAttributeValue("operatingSystemServicePack"))
+	d.Set("enabled", uac&adUserAccountControlFlags["ACCOUNTDISABLE"] == 0)
+	return d.Set("service_principal_names", spns)
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the Windows Server versions of the Active Directory functional levels
+var adFunctionalLevels = map[int]string{
+	0:  "2000",
+	1:  "2003 interim",
+	2:  "2003",
+	3:  "2008",
+	4:  "2008 R2",
+	5:  "2012",
+	6:  "2012 R2",
+	7:  "2016",
+	10: "2025",
+}
+
+func dataSourceLDAPADDomain() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPADDomainRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the domain; defaults to that of the domain of the server.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"dns_name":                     computed(schema.TypeString, "The DNS name of the domain (e.g. example.com)."),
+			"netbios_name":                 computed(schema.TypeString, "The NetBIOS name of the domain (e.g. EXAMPLE)."),
+			"sid":                          computed(schema.TypeString, "The SID of the domain, which prefixes the SIDs of its principals."),
+			"configuration_dn":             computed(schema.TypeString, "The DN of the configuration partition of the forest."),
+			"schema_dn":                    computed(schema.TypeString, "The DN of the schema partition of the forest."),
+			"domain_functional_level":      computed(schema.TypeInt, "The functional level of the domain (msDS-Behavior-Version)."),
+			"domain_functional_level_name": computed(schema.TypeString, "The Windows Server version of the functional level of the domain (e.g. 2016)."),
+			"forest_functional_level":      computed(schema.TypeInt, "The functional level of the forest."),
+			"pdc_emulator":                 computed(schema.TypeString, "The DN of the NTDS settings of the PDC emulator of the domain."),
+			"rid_master":                   computed(schema.TypeString, "The DN of the NTDS settings of the RID master of the domain."),
+			"infrastructure_master":        computed(schema.TypeString, "The DN of the NTDS settings of the infrastructure master of the domain."),
+			"schema_master":                computed(schema.TypeString, "The DN of the NTDS settings of the schema master of the forest."),
+			"domain_naming_master":         computed(schema.TypeString, "The DN of the NTDS settings of the domain naming master of the forest."),
+		},
+	}
+}
+
+func dataSourceLDAPADDomainRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	rootDSE, err := readRootDSE(client, "defaultNamingContext", "configurationNamingContext", "schemaNamingContext", "forestFunctionality")
+	if err != nil {
+		return err
+	}
+	if rootDSE == nil || rootDSE.GetEqualFoldAttributeValue("configurationNamingContext") == "" {
+		return fmt.Errorf("the server does not look like an Active Directory domain controller")
+	}
+	domainDN := d.Get("dn").(string)
+	if domainDN == "" {
+		domainDN = rootDSE.GetEqualFoldAttributeValue("defaultNamingContext")
+	}
+	configurationDN := rootDSE.GetEqualFoldAttributeValue("configurationNamingContext")
+	schemaDN := rootDSE.GetEqualFoldAttributeValue("schemaNamingContext")
+	partitionsDN := fmt.Sprintf("CN=Partitions,%s", configurationDN)
+
+	log.Printf("[DEBUG] ldap_ad_domain::read - reading domain %q", domainDN)
+
+	domain, err := readConfigEntry(client, domainDN, "objectSid", "fSMORoleOwner", "msDS-Behavior-Version")
+	if err != nil {
+		return err
+	}
+	if domain == nil {
+		return fmt.Errorf("domain %q does not exist", domainDN)
+	}
+	sid, err := util.SIDToString(domain.GetEqualFoldRawAttributeValue("objectSid"))
+	if err != nil {
+		return err
+	}
+
+	// the cross-reference of the domain holds its NetBIOS and DNS names
+	request := ldap.NewSearchRequest(
+		partitionsDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&(objectClass=crossRef)(nCName=%s))", ldap.EscapeFilter(domainDN)),
+		[]string{"nETBIOSName", "dnsRoot"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_domain::read - error searching the cross-reference of %q: %v", domainDN, err)
+		return err
+	}
+	if len(sr.Entries) == 0 {
+		return fmt.Errorf("no cross-reference for domain %q under %q", domainDN, partitionsDN)
+	}
+
+	// each FSMO role is held by the fSMORoleOwner of a different object
+	roles := map[string]string{
+		"rid_master":            fmt.Sprintf("CN=RID Manager$,CN=System,%s", domainDN),
+		"infrastructure_master": fmt.Sprintf("CN=Infrastructure,%s", domainDN),
+		"schema_master":         schemaDN,
+		"domain_naming_master":  partitionsDN,
+	}
+	for key, dn := range roles {
+		entry, err := readConfigEntry(client, dn, "fSMORoleOwner")
+		if err != nil {
+			return err
+		}
+		owner := ""
+		if entry != nil {
+			owner = entry.GetEqualFoldAttributeValue("fSMORoleOwner")
+		}
+		d.Set(key, owner)
+	}
+
+	level, _ := strconv.Atoi(domain.GetEqualFoldAttributeValue("msDS-Behavior-Version"))
+	forestLevel, _ := strconv.Atoi(rootDSE.GetEqualFoldAttributeValue("forestFunctionality"))
+
+	d.SetId(domainDN)
+	d.Set("dn", domainDN)
+	d.Set("dns_name", sr.Entries[0].GetEqualFoldAttributeValue("dnsRoot"))
+	d.Set("netbios_name", sr.Entries[0].GetEqualFoldAttributeValue("nETBIOSName"))
+	d.Set("sid", sid)
+	d.Set("configuration_dn", configurationDN)
+	d.Set("schema_dn", schemaDN)
+	d.Set("domain_functional_level", level)
+	d.Set("domain_functional_level_name", adFunctionalLevels[level])
+	d.Set("forest_functional_level", forestLevel)
+	d.Set("pdc_emulator", domain.GetEqualFoldAttributeValue("fSMORoleOwner"))
+	return nil
+}
@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the matching rule testing that all the given bits are set
+// (LDAP_MATCHING_RULE_BIT_AND)
+const bitAndOID = "1.2.840.113556.1.4.803"
+
+// the values of trustDirection and trustType
+var (
+	adTrustDirections = map[int]string{
+		0: "disabled",
+		1: "inbound",
+		2: "outbound",
+		3: "bidirectional",
+	}
+	adTrustTypes = map[int]string{
+		1: "downlevel",
+		2: "uplevel",
+		3: "mit",
+		4: "dce",
+	}
+)
+
+// the flags of trustAttributes
+var adTrustAttributeFlags = map[string]int{
+	"NON_TRANSITIVE":                       0x0001,
+	"UPLEVEL_ONLY":                         0x0002,
+	"QUARANTINED_DOMAIN":                   0x0004,
+	"FOREST_TRANSITIVE":                    0x0008,
+	"CROSS_ORGANIZATION":                   0x0010,
+	"WITHIN_FOREST":                        0x0020,
+	"TREAT_AS_EXTERNAL":                    0x0040,
+	"USES_RC4_ENCRYPTION":                  0x0080,
+	"USES_AES_KEYS":                        0x0100,
+	"CROSS_ORGANIZATION_NO_TGT_DELEGATION": 0x0200,
+	"PIM_TRUST":                            0x0400,
+}
+
+func dataSourceLDAPADForest() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPADForestRead,
+
+		Schema: map[string]*schema.Schema{
+			"domain_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the domain to list the trusts of; defaults to that of the domain of the server.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"name":                         computed(schema.TypeString, "The DNS name of the forest, that of its root domain."),
+			"root_domain_dn":               computed(schema.TypeString, "The DN of the root domain of the forest."),
+			"configuration_dn":             computed(schema.TypeString, "The DN of the configuration partition of the forest."),
+			"schema_dn":                    computed(schema.TypeString, "The DN of the schema partition of the forest."),
+			"forest_functional_level":      computed(schema.TypeInt, "The functional level of the forest (msDS-Behavior-Version of the partitions container)."),
+			"forest_functional_level_name": computed(schema.TypeString, "The Windows Server version of the functional level of the forest (e.g. 2016)."),
+			"domains": {
+				Type:        schema.TypeList,
+				Description: "The domains of the forest, sorted by DNS name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn":           computed(schema.TypeString, "The DN of the domain."),
+						"dns_name":     computed(schema.TypeString, "The DNS name of the domain."),
+						"netbios_name": computed(schema.TypeString, "The NetBIOS name of the domain."),
+					},
+				},
+			},
+			"trusts": {
+				Type:        schema.TypeList,
+				Description: "The trusts of domain_dn (its trustedDomain objects), sorted by partner.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn":        computed(schema.TypeString, "The DN of the trustedDomain object."),
+						"partner":   computed(schema.TypeString, "The DNS name of the trusted domain, or its NetBIOS name for downlevel trusts."),
+						"flat_name": computed(schema.TypeString, "The NetBIOS name of the trusted domain."),
+						"sid":       computed(schema.TypeString, "The SID of the trusted domain."),
+						"direction": computed(schema.TypeString, "The direction of the trust: disabled, inbound, outbound or bidirectional."),
+						"type":      computed(schema.TypeString, "The type of the trust: downlevel (Windows NT), uplevel (Active Directory), mit (Kerberos realm) or dce."),
+						"attributes": {
+							Type:        schema.TypeList,
+							Description: "The names of the flags set in trustAttributes (e.g. FOREST_TRANSITIVE), sorted.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"transitive": computed(schema.TypeBool, "Whether the trust is transitive."),
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPADForestRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	rootDSE, err := readRootDSE(client, "defaultNamingContext", "rootDomainNamingContext", "configurationNamingContext", "schemaNamingContext")
+	if err != nil {
+		return err
+	}
+	if rootDSE == nil || rootDSE.GetEqualFoldAttributeValue("configurationNamingContext") == "" {
+		return fmt.Errorf("the server does not look like an Active Directory domain controller")
+	}
+	domainDN := d.Get("domain_dn").(string)
+	if domainDN == "" {
+		domainDN = rootDSE.GetEqualFoldAttributeValue("defaultNamingContext")
+	}
+	rootDomainDN := rootDSE.GetEqualFoldAttributeValue("rootDomainNamingContext")
+	configurationDN := rootDSE.GetEqualFoldAttributeValue("configurationNamingContext")
+	partitionsDN := fmt.Sprintf("CN=Partitions,%s", configurationDN)
+
+	log.Printf("[DEBUG] ldap_ad_forest::read - reading the partitions of %q", configurationDN)
+
+	partitions, err := readConfigEntry(client, partitionsDN, "msDS-Behavior-Version")
+	if err != nil {
+		return err
+	}
+	if partitions == nil {
+		return fmt.Errorf("the partitions container %q does not exist", partitionsDN)
+	}
+	level, _ := strconv.Atoi(partitions.GetEqualFoldAttributeValue("msDS-Behavior-Version"))
+
+	// the cross-references of domains are flagged SYSTEM_FLAG_CR_NTDS_DOMAIN,
+	// the others are application partitions and the like
+	request := ldap.NewSearchRequest(
+		partitionsDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&(objectClass=crossRef)(systemFlags:%s:=2))", bitAndOID),
+		[]string{"nCName", "dnsRoot", "nETBIOSName"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_forest::read - error searching the domains under %q: %v", partitionsDN, err)
+		return err
+	}
+	sort.SliceStable(sr.Entries, func(i, j int) bool {
+		return strings.ToLower(sr.Entries[i].GetEqualFoldAttributeValue("dnsRoot")) < strings.ToLower(sr.Entries[j].GetEqualFoldAttributeValue("dnsRoot"))
+	})
+	name := ""
+	domains := make([]interface{}, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		if strings.EqualFold(entry.GetEqualFoldAttributeValue("nCName"), rootDomainDN) {
+			name = entry.GetEqualFoldAttributeValue("dnsRoot")
+		}
+		domains = append(domains, map[string]interface{}{
+			"dn":           entry.GetEqualFoldAttributeValue("nCName"),
+			"dns_name":     entry.GetEqualFoldAttributeValue("dnsRoot"),
+			"netbios_name": entry.GetEqualFoldAttributeValue("nETBIOSName"),
+		})
+	}
+
+	systemDN := fmt.Sprintf("CN=System,%s", domainDN)
+
+	log.Printf("[DEBUG] ldap_ad_forest::read - searching the trusts under %q", systemDN)
+
+	request = ldap.NewSearchRequest(
+		systemDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=trustedDomain)",
+		[]string{"trustPartner", "flatName", "securityIdentifier", "trustDirection", "trustType", "trustAttributes"},
+		nil,
+	)
+	sr, err = client.Search(request)
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_forest::read - error searching the trusts under %q: %v", systemDN, err)
+		return err
+	}
+	sort.SliceStable(sr.Entries, func(i, j int) bool {
+		return strings.ToLower(sr.Entries[i].GetEqualFoldAttributeValue("trustPartner")) < strings.ToLower(sr.Entries[j].GetEqualFoldAttributeValue("trustPartner"))
+	})
+	trusts := make([]interface{}, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		sid := ""
+		if b := entry.GetEqualFoldRawAttributeValue("securityIdentifier"); len(b) > 0 {
+			if sid, err = util.SIDToString(b); err != nil {
+				return err
+			}
+		}
+		direction, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("trustDirection"))
+		trustType, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("trustType"))
+		attributes, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("trustAttributes"))
+		trusts = append(trusts, map[string]interface{}{
+			"dn":         entry.DN,
+			"partner":    entry.GetEqualFoldAttributeValue("trustPartner"),
+			"flat_name":  entry.GetEqualFoldAttributeValue("flatName"),
+			"sid":        sid,
+			"direction":  adTrustDirections[direction],
+			"type":       adTrustTypes[trustType],
+			"attributes": adFlagNames(attributes, adTrustAttributeFlags),
+			"transitive": attributes&adTrustAttributeFlags["NON_TRANSITIVE"] == 0,
+		})
+	}
+
+	log.Printf("[DEBUG] ldap_ad_forest::read - forest %q has %d domains, %q has %d trusts", name, len(domains), domainDN, len(trusts))
+
+	d.SetId(rootDomainDN)
+	d.Set("domain_dn", domainDN)
+	d.Set("name", name)
+	d.Set("root_domain_dn", rootDomainDN)
+	d.Set("configuration_dn", configurationDN)
+	d.Set("schema_dn", rootDSE.GetEqualFoldAttributeValue("schemaNamingContext"))
+	d.Set("forest_functional_level", level)
+	d.Set("forest_functional_level_name", adFunctionalLevels[level])
+	d.Set("domains", domains)
+	return d.Set("trusts", trusts)
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the values of the flags attribute of group policy containers
+var adGPOStatuses = map[int]string{
+	0: "enabled",
+	1: "user_disabled",
+	2: "computer_disabled",
+	3: "disabled",
+}
+
+func dataSourceLDAPADGPO() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPADGPORead,
+
+		Schema: map[string]*schema.Schema{
+			"display_name": {
+				Type:         schema.TypeString,
+				Description:  "The display name of the GPO, as shown in the Group Policy Management console.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"display_name", "guid"},
+			},
+			"guid": {
+				Type:        schema.TypeString,
+				Description: "The GUID of the GPO, with or without braces (e.g. {31B2F340-016D-11D2-945F-00C04FB984F9}).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search under; defaults to the policies container of the domain of the server.",
+				Optional:    true,
+			},
+			"dn":             computed(schema.TypeString, "The DN of the GPO (CN={GUID},CN=Policies,CN=System,...)."),
+			"file_sys_path":  computed(schema.TypeString, "The path of the GPO in SYSVOL (gPCFileSysPath)."),
+			"version_number": computed(schema.TypeInt, "The version of the GPO, incremented on each change."),
+			"status":         computed(schema.TypeString, "Which settings of the GPO apply: enabled, user_disabled, computer_disabled or disabled."),
+			"gplink":         computed(schema.TypeString, "The gPLink element linking the GPO, enabled and not enforced (e.g. [LDAP://CN={...},...;0])."),
+		},
+	}
+}
+
+func dataSourceLDAPADGPORead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	baseDN := d.Get("base_dn").(string)
+	if baseDN == "" {
+		rootDSE, err := readRootDSE(client, "defaultNamingContext")
+		if err != nil {
+			return err
+		}
+		if rootDSE == nil || rootDSE.GetEqualFoldAttributeValue("defaultNamingContext") == "" {
+			return fmt.Errorf("the root DSE has no defaultNamingContext; set base_dn explicitly")
+		}
+		baseDN = fmt.Sprintf("CN=Policies,CN=System,%s", rootDSE.GetEqualFoldAttributeValue("defaultNamingContext"))
+	}
+	filter := "(&(objectClass=groupPolicyContainer)"
+	if v, ok := d.GetOk("display_name"); ok {
+		filter += fmt.Sprintf("(displayName=%s))", ldap.EscapeFilter(v.(string)))
+	} else {
+		guid := strings.Trim(d.Get("guid").(string), "{}")
+		filter += fmt.Sprintf("(cn={%s}))", ldap.EscapeFilter(guid))
+	}
+
+	log.Printf("[DEBUG] ldap_ad_gpo::read - looking up %s under %q", filter, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, filter, "cn", "displayName", "gPCFileSysPath", "versionNumber", "flags")
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_gpo::read - error looking up GPO: %v", err)
+		return err
+	}
+	version, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("versionNumber"))
+	flags, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("flags"))
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("display_name", entry.GetEqualFoldAttributeValue("displayName"))
+	d.Set("guid", strings.ToUpper(entry.GetEqualFoldAttributeValue("cn")))
+	d.Set("file_sys_path", entry.GetEqualFoldAttributeValue("gPCFileSysPath"))
+	d.Set("version_number", version)
+	d.Set("status", adGPOStatuses[flags])
+	d.Set("gplink", fmt.Sprintf("[LDAP://%s;0]", entry.DN))
+	return nil
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the groupType flags of Active Directory groups
+const (
+	adGroupTypeGlobal      = 0x2
+	adGroupTypeDomainLocal = 0x4
+	adGroupTypeUniversal   = 0x8
+	adGroupTypeSecurity    = -0x80000000
+)
+
+func dataSourceLDAPADGroup() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADGroupRead,
+
+		Schema: adLookupSchema(map[string]*schema.Schema{
+			"sam_account_name": {
+				Type:         schema.TypeString,
+				Description:  "The pre-Windows 2000 name of the group to look up.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"sam_account_name", "cn"},
+			},
+			"cn": {
+				Type:        schema.TypeString,
+				Description: "The common name of the group to look up.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the group.",
+				Computed:    true,
+			},
+			"object_guid": {
+				Type:        schema.TypeString,
+				Description: "The objectGUID of the group.",
+				Computed:    true,
+			},
+			"sid": {
+				Type:        schema.TypeString,
+				Description: "The SID of the group.",
+				Computed:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the group.",
+				Computed:    true,
+			},
+			"group_type": {
+				Type:        schema.TypeInt,
+				Description: "The raw groupType of the group.",
+				Computed:    true,
+			},
+			"scope": {
+				Type:        schema.TypeString,
+				Description: "The scope of the group: global, domain_local or universal.",
+				Computed:    true,
+			},
+			"category": {
+				Type:        schema.TypeString,
+				Description: "The category of the group: security or distribution.",
+				Computed:    true,
+			},
+			"member_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of direct members of the group, excluding the users having it as primary group.",
+				Computed:    true,
+			},
+		}),
+	}
+}
+
+func dataSourceLDAPADGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	baseDN, err := adBaseDN(client, d)
+	if err != nil {
+		return err
+	}
+	filter := "(&(objectClass=group)"
+	if v, ok := d.GetOk("sam_account_name"); ok {
+		filter += fmt.Sprintf("(sAMAccountName=%s))", ldap.EscapeFilter(v.(string)))
+	} else {
+		filter += fmt.Sprintf("(cn=%s))", ldap.EscapeFilter(d.Get("cn").(string)))
+	}
+
+	log.Printf("[DEBUG] ldap_ad_group::read - looking up %s under %q", filter, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, filter,
+		"sAMAccountName", "cn", "objectGUID", "objectSid", "description", "groupType", "member")
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_group::read - error looking up group: %v", err)
+		return err
+	}
+	guid, sid, err := adObjectIdentifiers(entry)
+	if err != nil {
+		return err
+	}
+	// groupType is a signed 32-bit integer, the security flag being its sign
+	groupType, _ := strconv.ParseInt(entry.GetEqualFoldAttributeValue("groupType"), 10, 32)
+	scope := ""
+	switch {
+	case groupType&adGroupTypeGlobal != 0:
+		scope = "global"
+	case groupType&adGroupTypeDomainLocal != 0:
+		scope = "domain_local"
+	case groupType&adGroupTypeUniversal != 0:
+		scope = "universal"
+	}
+	category := "distribution"
+	if groupType&adGroupTypeSecurity != 0 {
+		category = "security"
+	}
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("sam_account_name", entry.GetEqualFoldAttributeValue("sAMAccountName"))
+	d.Set("cn", entry.GetEqualFoldAttributeValue("cn"))
+	d.Set("object_guid", guid)
+	d.Set("sid", sid)
+	d.Set("description", entry.GetEqualFoldAttributeValue("description"))
+	d.Set("group_type", int(groupType))
+	d.Set("scope", scope)
+	d.Set("category", category)
+	d.Set("member_count", len(entry.GetEqualFoldAttributeValues("member")))
+	return nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPADGUID() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADGUIDRead,
+
+		Schema: map[string]*schema.Schema{
+			"guid": {
+				Type:         schema.TypeString,
+				Description:  "The GUID in its text form, with or without braces (e.g. 6f9619ff-8b86-d011-b42d-00c04fc964ff).",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"guid", "base64"},
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					if _, err := util.ParseGUID(v.(string)); err != nil {
+						es = append(es, err)
+					}
+					return
+				},
+			},
+			"base64": {
+				Type:        schema.TypeString,
+				Description: "The binary objectGUID, base64 encoded (e.g. as returned by ldap_search).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"filter_value": {
+				Type:        schema.TypeString,
+				Description: "The binary objectGUID escaped for search filters, as in (objectGUID=<filter_value>).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPADGUIDRead(d *schema.ResourceData, meta interface{}) error {
+	var b []byte
+	var err error
+	if v, ok := d.GetOk("guid"); ok {
+		b, err = util.ParseGUID(v.(string))
+	} else if b, err = base64.StdEncoding.DecodeString(d.Get("base64").(string)); err != nil {
+		err = fmt.Errorf("invalid base64: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+	guid, err := util.GUIDToString(b)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(guid)
+	d.Set("guid", guid)
+	d.Set("base64", base64.StdEncoding.EncodeToString(b))
+	return d.Set("filter_value", util.EscapeFilterBytes(b))
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPADObjectBySID() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADObjectBySIDRead,
+
+		Schema: map[string]*schema.Schema{
+			"sid": {
+				Type:        schema.TypeString,
+				Description: "The SID of the object (e.g. S-1-5-21-1004336348-1177238915-682003330-512).",
+				Required:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					if _, err := util.ParseSID(v.(string)); err != nil {
+						es = append(es, err)
+					}
+					return
+				},
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search for the object under, usually that of the domain.",
+				Required:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the object.",
+				Computed:    true,
+			},
+			"sam_account_name": {
+				Type:        schema.TypeString,
+				Description: "The sAMAccountName of the object, for security principals.",
+				Computed:    true,
+			},
+			"object_class": {
+				Type:        schema.TypeString,
+				Description: "The most specific class of the object (e.g. user, group or computer).",
+				Computed:    true,
+			},
+			"object_classes": {
+				Type:        schema.TypeList,
+				Description: "The class hierarchy of the object, from top down.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"object_guid": {
+				Type:        schema.TypeString,
+				Description: "The objectGUID of the object.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPADObjectBySIDRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	sid := d.Get("sid").(string)
+	baseDN := d.Get("base_dn").(string)
+
+	b, err := util.ParseSID(sid)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] ldap_ad_object_by_sid::read - looking up %s under %q", sid, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, fmt.Sprintf("(objectSid=%s)", util.EscapeFilterBytes(b)), "sAMAccountName", "objectClass", "objectGUID")
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_object_by_sid::read - error looking up %s: %v", sid, err)
+		return err
+	}
+
+	// AD returns the class hierarchy from top down
+	objectClasses := entry.GetEqualFoldAttributeValues("objectClass")
+	objectClass := ""
+	if len(objectClasses) > 0 {
+		objectClass = objectClasses[len(objectClasses)-1]
+	}
+	guid, err := util.GUIDToString(entry.GetEqualFoldRawAttributeValue("objectGUID"))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(sid)
+	d.Set("dn", entry.DN)
+	d.Set("sam_account_name", entry.GetEqualFoldAttributeValue("sAMAccountName"))
+	d.Set("object_class", objectClass)
+	d.Set("object_classes", objectClasses)
+	d.Set("object_guid", guid)
+	return nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPADSID() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADSIDRead,
+
+		Schema: map[string]*schema.Schema{
+			"sid": {
+				Type:         schema.TypeString,
+				Description:  "The SID in its text form (e.g. S-1-5-21-1004336348-1177238915-682003330-512).",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"sid", "base64"},
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					if _, err := util.ParseSID(v.(string)); err != nil {
+						es = append(es, err)
+					}
+					return
+				},
+			},
+			"base64": {
+				Type:        schema.TypeString,
+				Description: "The binary objectSid, base64 encoded (e.g. as returned by ldap_search).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"filter_value": {
+				Type:        schema.TypeString,
+				Description: "The binary objectSid escaped for search filters, as in (objectSid=<filter_value>).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPADSIDRead(d *schema.ResourceData, meta interface{}) error {
+	var b []byte
+	var err error
+	if v, ok := d.GetOk("sid"); ok {
+		b, err = util.ParseSID(v.(string))
+	} else if b, err = base64.StdEncoding.DecodeString(d.Get("base64").(string)); err != nil {
+		err = fmt.Errorf("invalid base64: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+	sid, err := util.SIDToString(b)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(sid)
+	d.Set("sid", sid)
+	d.Set("base64", base64.StdEncoding.EncodeToString(b))
+	return d.Set("filter_value", util.EscapeFilterBytes(b))
+}
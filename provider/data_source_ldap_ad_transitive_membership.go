@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPADTransitiveMembership() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADTransitiveMembershipRead,
+
+		Schema: adLookupSchema(map[string]*schema.Schema{
+			"member_dn": {
+				Type:         schema.TypeString,
+				Description:  "The DN of a user (or any other member) to return all the groups of, directly or through nested groups.",
+				Optional:     true,
+				ExactlyOneOf: []string{"member_dn", "group_dn"},
+			},
+			"group_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of a group to return all the members of, directly or through nested groups.",
+				Optional:    true,
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the groups found, sorted: the groups of member_dn, or the nested groups of group_dn.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"users": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the users transitively in group_dn, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}),
+	}
+}
+
+func dataSourceLDAPADTransitiveMembershipRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	baseDN, err := adBaseDN(client, d)
+	if err != nil {
+		return err
+	}
+
+	var users, groups []string
+	if memberDN, ok := d.GetOk("member_dn"); ok {
+		log.Printf("[DEBUG] ldap_ad_transitive_membership::read - searching the groups of %q under %q", memberDN, baseDN)
+
+		request := ldap.NewSearchRequest(
+			baseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			fmt.Sprintf("(&(objectClass=group)(member:%s:=%s))", inChainOID, ldap.EscapeFilter(memberDN.(string))),
+			[]string{"1.1"},
+			nil,
+		)
+		sr, err := client.SearchWithPaging(request, ldapDefaultPageSize)
+		if err != nil {
+			log.Printf("[ERROR] ldap_ad_transitive_membership::read - error searching the groups of %q: %v", memberDN, err)
+			return err
+		}
+		for _, entry := range sr.Entries {
+			groups = append(groups, entry.DN)
+		}
+		d.SetId(fmt.Sprintf("member:%s", memberDN))
+	} else {
+		groupDN := d.Get("group_dn").(string)
+		if users, groups, err = searchLDAPGroupMembersInChain(client, baseDN, groupDN); err != nil {
+			log.Printf("[ERROR] ldap_ad_transitive_membership::read - error searching the members of %q: %v", groupDN, err)
+			return err
+		}
+		d.SetId(fmt.Sprintf("group:%s", groupDN))
+	}
+	sort.Strings(users)
+	sort.Strings(groups)
+
+	log.Printf("[DEBUG] ldap_ad_transitive_membership::read - found %d groups and %d users", len(groups), len(users))
+
+	d.Set("users", users)
+	d.Set("groups", groups)
+	return nil
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the userAccountControl flags, by name
+var adUserAccountControlFlags = map[string]int{
+	"SCRIPT":                         0x0001,
+	"ACCOUNTDISABLE":                 0x0002,
+	"HOMEDIR_REQUIRED":               0x0008,
+	"LOCKOUT":                        0x0010,
+	"PASSWD_NOTREQD":                 0x0020,
+	"PASSWD_CANT_CHANGE":             0x0040,
+	"ENCRYPTED_TEXT_PWD_ALLOWED":     0x0080,
+	"TEMP_DUPLICATE_ACCOUNT":         0x0100,
+	"NORMAL_ACCOUNT":                 0x0200,
+	"INTERDOMAIN_TRUST_ACCOUNT":      0x0800,
+	"WORKSTATION_TRUST_ACCOUNT":      0x1000,
+	"SERVER_TRUST_ACCOUNT":           0x2000,
+	"DONT_EXPIRE_PASSWORD":           0x10000,
+	"MNS_LOGON_ACCOUNT":              0x20000,
+	"SMARTCARD_REQUIRED":             0x40000,
+	"TRUSTED_FOR_DELEGATION":         0x80000,
+	"NOT_DELEGATED":                  0x100000,
+	"USE_DES_KEY_ONLY":               0x200000,
+	"DONT_REQ_PREAUTH":               0x400000,
+	"PASSWORD_EXPIRED":               0x800000,
+	"TRUSTED_TO_AUTH_FOR_DELEGATION": 0x1000000,
+	"PARTIAL_SECRETS_ACCOUNT":        0x4000000,
+}
+
+func dataSourceLDAPADUser() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPADUserRead,
+
+		Schema: adLookupSchema(map[string]*schema.Schema{
+			"sam_account_name": {
+				Type:         schema.TypeString,
+				Description:  "The logon name of the user to look up (e.g. jsmith).",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"sam_account_name", "user_principal_name"},
+			},
+			"user_principal_name": {
+				Type:        schema.TypeString,
+				Description: "The user principal name of the user to look up (e.g. jsmith@example.com).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the user.",
+				Computed:    true,
+			},
+			"object_guid": {
+				Type:        schema.TypeString,
+				Description: "The objectGUID of the user.",
+				Computed:    true,
+			},
+			"sid": {
+				Type:        schema.TypeString,
+				Description: "The SID of the user.",
+				Computed:    true,
+			},
+			"display_name": {
+				Type:        schema.TypeString,
+				Description: "The display name of the user.",
+				Computed:    true,
+			},
+			"mail": {
+				Type:        schema.TypeString,
+				Description: "The e-mail address of the user.",
+				Computed:    true,
+			},
+			"user_account_control": {
+				Type:        schema.TypeInt,
+				Description: "The userAccountControl flags of the user.",
+				Computed:    true,
+			},
+			"user_account_control_flags": {
+				Type:        schema.TypeList,
+				Description: "The names of the userAccountControl flags set (e.g. NORMAL_ACCOUNT, DONT_EXPIRE_PASSWORD), sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the account is enabled (ACCOUNTDISABLE not set).",
+				Computed:    true,
+			},
+			"member_of": {
+				Type:        schema.TypeSet,
+				Description: "The DNs of the groups the user is a direct member of, excluding its primary group.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+		}),
+	}
+}
+
+func dataSourceLDAPADUserRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	baseDN, err := adBaseDN(client, d)
+	if err != nil {
+		return err
+	}
+	filter := "(&(objectCategory=person)(objectClass=user)"
+	if v, ok := d.GetOk("sam_account_name"); ok {
+		filter += fmt.Sprintf("(sAMAccountName=%s))", ldap.EscapeFilter(v.(string)))
+	} else {
+		filter += fmt.Sprintf("(userPrincipalName=%s))", ldap.EscapeFilter(d.Get("user_principal_name").(string)))
+	}
+
+	log.Printf("[DEBUG] ldap_ad_user::read - looking up %s under %q", filter, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, filter,
+		"sAMAccountName", "userPrincipalName", "objectGUID", "objectSid", "displayName", "mail", "userAccountControl", "memberOf")
+	if err != nil {
+		log.Printf("[ERROR] ldap_ad_user::read - error looking up user: %v", err)
+		return err
+	}
+	guid, sid, err := adObjectIdentifiers(entry)
+	if err != nil {
+		return err
+	}
+	uac, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("userAccountControl"))
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("sam_account_name", entry.GetEqualFoldAttributeValue("sAMAccountName"))
+	d.Set("user_principal_name", entry.GetEqualFoldAttributeValue("userPrincipalName"))
+	d.Set("object_guid", guid)
+	d.Set("sid", sid)
+	d.Set("display_name", entry.GetEqualFoldAttributeValue("displayName"))
+	d.Set("mail", entry.GetEqualFoldAttributeValue("mail"))
+	d.Set("user_account_control", uac)
+	d.Set("user_account_control_flags", adFlagNames(uac, adUserAccountControlFlags))
+	d.Set("enabled", uac&adUserAccountControlFlags["ACCOUNTDISABLE"] == 0)
+	d.Set("member_of", entry.GetEqualFoldAttributeValues("memberOf"))
+	return nil
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceLDAPADUserAccountControl() *schema.Resource {
+	flags := make([]string, 0, len(adUserAccountControlFlags))
+	for name := range adUserAccountControlFlags {
+		flags = append(flags, name)
+	}
+	sort.Strings(flags)
+
+	return &schema.Resource{
+		Read: dataSourceLDAPADUserAccountControlRead,
+
+		Schema: map[string]*schema.Schema{
+			"flags": {
+				Type:         schema.TypeSet,
+				Description:  fmt.Sprintf("The names of the flags set: %s.", strings.Join(flags, ", ")),
+				Elem:         &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(flags, false)},
+				Set:          schema.HashString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"flags", "value"},
+			},
+			"value": {
+				Type:        schema.TypeInt,
+				Description: "The userAccountControl value (e.g. 66048 for NORMAL_ACCOUNT and DONT_EXPIRE_PASSWORD).",
+				Optional:    true,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPADUserAccountControlRead(d *schema.ResourceData, meta interface{}) error {
+	value := 0
+	if v, ok := d.GetOk("flags"); ok {
+		for _, name := range v.(*schema.Set).List() {
+			value |= adUserAccountControlFlags[name.(string)]
+		}
+	} else {
+		value = d.Get("value").(int)
+	}
+	// bits without a name would be lost decoding the value
+	unknown := value
+	for _, flag := range adUserAccountControlFlags {
+		unknown &^= flag
+	}
+	if unknown != 0 {
+		return fmt.Errorf("userAccountControl %d has unknown flags 0x%x", value, unknown)
+	}
+
+	d.SetId(fmt.Sprint(value))
+	d.Set("value", value)
+	return d.Set("flags", adFlagNames(value, adUserAccountControlFlags))
+}
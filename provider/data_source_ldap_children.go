@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPChildren() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPChildrenRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the container to list.",
+				Required:    true,
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Description: "A filter the children must match (e.g. (objectClass=organizationalUnit)).",
+				Optional:    true,
+				Default:     "(objectClass=*)",
+			},
+			"page_size": ldapPageSizeSchema(),
+			"children": {
+				Type:        schema.TypeList,
+				Description: "The immediate children of the container, sorted by DN.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn": {
+							Type:        schema.TypeString,
+							Description: "The DN of the child.",
+							Computed:    true,
+						},
+						"rdn": {
+							Type:        schema.TypeString,
+							Description: "The RDN of the child (e.g. ou=people).",
+							Computed:    true,
+						},
+						"rdn_attribute": {
+							Type:        schema.TypeString,
+							Description: "The attribute of the first component of the RDN (e.g. ou).",
+							Computed:    true,
+						},
+						"rdn_value": {
+							Type:        schema.TypeString,
+							Description: "The value of the first component of the RDN, unescaped (e.g. people).",
+							Computed:    true,
+						},
+						"object_classes": {
+							Type:        schema.TypeList,
+							Description: "The object classes of the child.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPChildrenRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+	filter := d.Get("filter").(string)
+
+	log.Printf("[DEBUG] ldap_children::read - listing children of %q matching %s", dn, filter)
+
+	request := ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		filter,
+		[]string{"objectClass"},
+		nil,
+	)
+	sr, err := searchLDAPPaged(client, request, d.Get("page_size").(int))
+	if err != nil {
+		log.Printf("[ERROR] ldap_children::read - error listing children of %q: %v", dn, err)
+		return err
+	}
+	sortLDAPEntries(sr.Entries)
+
+	children := make([]interface{}, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		parsed, err := ldap.ParseDN(entry.DN)
+		if err != nil {
+			return fmt.Errorf("error parsing DN %q: %v", entry.DN, err)
+		}
+		rdn, _ := util.SplitRDN(entry.DN)
+		children = append(children, map[string]interface{}{
+			"dn":             entry.DN,
+			"rdn":            rdn,
+			"rdn_attribute":  parsed.RDNs[0].Attributes[0].Type,
+			"rdn_value":      parsed.RDNs[0].Attributes[0].Value,
+			"object_classes": entry.GetEqualFoldAttributeValues("objectClass"),
+		})
+	}
+
+	log.Printf("[DEBUG] ldap_children::read - %q has %d children", dn, len(children))
+
+	d.SetId(dn)
+	return d.Set("children", children)
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPDN() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPDNRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:         schema.TypeString,
+				Description:  "The DN to parse; exactly one of it, rdn or rdn_value must be set.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"dn", "rdn", "rdn_value"},
+			},
+			"rdn": {
+				Type:        schema.TypeString,
+				Description: "The leftmost RDN, as written (e.g. cn=John Smith). Set with parent_dn to join an already escaped RDN to its parent.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"rdn_attribute": {
+				Type:         schema.TypeString,
+				Description:  "The attribute of the leftmost RDN (e.g. cn); that of its first component for multi-valued RDNs. Set with rdn_value to build the DN.",
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"rdn_value"},
+			},
+			"rdn_value": {
+				Type:         schema.TypeString,
+				Description:  "The unescaped value of the leftmost RDN (e.g. Smith, John); that of its first component for multi-valued RDNs. Set with rdn_attribute (and parent_dn) to build the DN, escaping the value.",
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"rdn_attribute"},
+			},
+			"rdn_value_escaped": {
+				Type:        schema.TypeString,
+				Description: "The value of rdn_value escaped per RFC 4514 for use in a DN (e.g. Smith\\, John).",
+				Computed:    true,
+			},
+			"parent_dn": {
+				Type:          schema.TypeString,
+				Description:   "The DN of the parent, as written; empty for a single RDN. Set with rdn or rdn_value to build the DN under it.",
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"dn"},
+			},
+			"normalized_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN in a canonical form for comparisons and map keys: lowercase attribute types, no spaces around separators, minimal RFC 4514 escaping and the components of multi-valued RDNs sorted. Values keep their case, most naming attributes comparing case-insensitively wrap it in lower().",
+				Computed:    true,
+			},
+			"ancestor_dn": {
+				Type:        schema.TypeString,
+				Description: "A DN to test the DN against with is_descendant (e.g. ou=managed,dc=example,dc=com).",
+				Optional:    true,
+			},
+			"is_descendant": {
+				Type:        schema.TypeBool,
+				Description: "Whether the DN is strictly below ancestor_dn, comparing attribute types and values case-insensitively after unescaping; false when ancestor_dn is unset.",
+				Computed:    true,
+			},
+			"depth": {
+				Type:        schema.TypeInt,
+				Description: "The number of RDNs of the DN.",
+				Computed:    true,
+			},
+			"rdns": {
+				Type:        schema.TypeList,
+				Description: "The RDNs of the DN as written, leftmost first.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"components": {
+				Type:        schema.TypeList,
+				Description: "The attribute and unescaped value of each RDN component, leftmost first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"attribute": {
+							Type:        schema.TypeString,
+							Description: "The attribute of the component.",
+							Computed:    true,
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Description: "The unescaped value of the component.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPDNRead(d *schema.ResourceData, meta interface{}) error {
+	dn := d.Get("dn").(string)
+	if dn == "" {
+		// build the DN from its leftmost RDN and its parent
+		dn = d.Get("rdn").(string)
+		if value, ok := d.GetOk("rdn_value"); ok {
+			dn = fmt.Sprintf("%s=%s", d.Get("rdn_attribute").(string), ldap.EscapeDN(value.(string)))
+		}
+		if parent := d.Get("parent_dn").(string); parent != "" {
+			dn = fmt.Sprintf("%s,%s", dn, parent)
+		}
+	}
+
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return fmt.Errorf("invalid DN %q: %v", dn, err)
+	}
+
+	isDescendant := false
+	if ancestor := d.Get("ancestor_dn").(string); ancestor != "" {
+		parsedAncestor, err := ldap.ParseDN(ancestor)
+		if err != nil {
+			return fmt.Errorf("invalid ancestor DN %q: %v", ancestor, err)
+		}
+		isDescendant = parsedAncestor.AncestorOfFold(parsed)
+	}
+
+	rdns := []string{}
+	for rest := strings.TrimSpace(dn); rest != ""; {
+		var rdn string
+		rdn, rest = util.SplitRDN(rest)
+		rdns = append(rdns, strings.TrimSpace(rdn))
+	}
+	components := []interface{}{}
+	for _, rdn := range parsed.RDNs {
+		for _, attribute := range rdn.Attributes {
+			components = append(components, map[string]interface{}{
+				"attribute": attribute.Type,
+				"value":     attribute.Value,
+			})
+		}
+	}
+
+	d.SetId(dn)
+	d.Set("dn", dn)
+	d.Set("normalized_dn", normalizeDN(parsed))
+	d.Set("is_descendant", isDescendant)
+	d.Set("depth", len(parsed.RDNs))
+	d.Set("rdns", rdns)
+	d.Set("components", components)
+	if len(parsed.RDNs) > 0 {
+		rdn, parent := util.SplitRDN(strings.TrimSpace(dn))
+		d.Set("rdn", strings.TrimSpace(rdn))
+		d.Set("parent_dn", parent)
+		d.Set("rdn_attribute", parsed.RDNs[0].Attributes[0].Type)
+		d.Set("rdn_value", parsed.RDNs[0].Attributes[0].Value)
+		d.Set("rdn_value_escaped", ldap.EscapeDN(parsed.RDNs[0].Attributes[0].Value))
+	}
+	return nil
+}
+
+// normalizeDN returns the canonical string form of a parsed DN, see the
+// normalized_dn output of ldap_dn.
+func normalizeDN(dn *ldap.DN) string {
+	rdns := make([]string, 0, len(dn.RDNs))
+	for _, rdn := range dn.RDNs {
+		components := make([]string, 0, len(rdn.Attributes))
+		for _, attribute := range rdn.Attributes {
+			components = append(components, fmt.Sprintf("%s=%s", strings.ToLower(attribute.Type), ldap.EscapeDN(attribute.Value)))
+		}
+		sort.Strings(components)
+		rdns = append(rdns, strings.Join(components, "+"))
+	}
+	return strings.Join(rdns, ",")
+}
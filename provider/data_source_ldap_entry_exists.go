@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPEntryExists() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPEntryExistsRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:         schema.TypeString,
+				Description:  "The DN of the entry to check; either it or filter must be set.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"dn", "filter"},
+			},
+			"filter": {
+				Type:         schema.TypeString,
+				Description:  "A filter at least one entry under base_dn must match.",
+				Optional:     true,
+				RequiredWith: []string{"base_dn"},
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search under with filter; a missing base DN means no match rather than an error.",
+				Optional:    true,
+			},
+			"exists": {
+				Type:        schema.TypeBool,
+				Description: "Whether the entry exists.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPEntryExistsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	if filter, ok := d.GetOk("filter"); ok {
+		baseDN := d.Get("base_dn").(string)
+
+		log.Printf("[DEBUG] ldap_entry_exists::read - searching %q for %s", baseDN, filter)
+
+		request := ldap.NewSearchRequest(
+			baseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			1,
+			0,
+			false,
+			filter.(string),
+			[]string{"1.1"},
+			nil,
+		)
+		sr, err := client.Search(request)
+		if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			log.Printf("[ERROR] ldap_entry_exists::read - error searching %q: %v", baseDN, err)
+			return err
+		}
+		exists := sr != nil && len(sr.Entries) > 0
+		dn := ""
+		if exists {
+			dn = sr.Entries[0].DN
+		}
+		d.SetId(fmt.Sprintf("%s?sub?%s", baseDN, filter))
+		d.Set("dn", dn)
+		d.Set("exists", exists)
+		return nil
+	}
+
+	dn := d.Get("dn").(string)
+
+	log.Printf("[DEBUG] ldap_entry_exists::read - checking %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "1.1")
+	if err != nil {
+		log.Printf("[ERROR] ldap_entry_exists::read - error reading %q: %v", dn, err)
+		return err
+	}
+	d.SetId(dn)
+	d.Set("exists", entry != nil)
+	return nil
+}
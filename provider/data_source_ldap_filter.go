@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// an attribute description of RFC 4512: a name or numeric OID followed by
+// options, e.g. cn or userCertificate;binary
+var attributeDescriptionRegexp = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|[0-9]+(\.[0-9]+)+)(;[A-Za-z0-9-]+)*$`)
+
+func dataSourceLDAPFilter() *schema.Resource {
+	assertions := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeMap,
+			Description: description,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+				for attribute := range v.(map[string]interface{}) {
+					if !attributeDescriptionRegexp.MatchString(attribute) {
+						es = append(es, fmt.Errorf("%s: invalid attribute %q", k, attribute))
+					}
+				}
+				return
+			},
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPFilterRead,
+
+		Schema: map[string]*schema.Schema{
+			"all":  assertions("Attribute values that must all match, one value per attribute; values are escaped, so * matches a literal asterisk."),
+			"any":  assertions("Attribute values of which at least one must match, one value per attribute; use filters to match any of several values of the same attribute."),
+			"none": assertions("Attribute values of which none may match, one value per attribute."),
+			"present": {
+				Type:        schema.TypeList,
+				Description: "Attributes the entries must have a value for.",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(attributeDescriptionRegexp, "invalid attribute"),
+				},
+			},
+			"filters": {
+				Type:        schema.TypeList,
+				Description: "Filters that must all match as well, used as is (e.g. another ldap_filter, or (memberOf:1.2.840.113556.1.4.1941:=...)).",
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+					ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+						if _, err := ldap.CompileFilter(v.(string)); err != nil {
+							es = append(es, fmt.Errorf("%s: invalid filter %q: %v", k, v, err))
+						}
+						return
+					},
+				},
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Description: "The RFC 4515 filter ANDing all the conditions, e.g. (&(objectClass=person)(|(ou=a)(l=b))(!(cn=x))).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPFilterRead(d *schema.ResourceData, meta interface{}) error {
+	assertions := func(key, format string) []string {
+		values := d.Get(key).(map[string]interface{})
+		attributes := make([]string, 0, len(values))
+		for attribute := range values {
+			attributes = append(attributes, attribute)
+		}
+		sort.Strings(attributes)
+		filters := make([]string, 0, len(attributes))
+		for _, attribute := range attributes {
+			filters = append(filters, fmt.Sprintf(format, attribute, ldap.EscapeFilter(values[attribute].(string))))
+		}
+		return filters
+	}
+
+	filters := assertions("all", "(%s=%s)")
+	if any := assertions("any", "(%s=%s)"); len(any) == 1 {
+		filters = append(filters, any...)
+	} else if len(any) > 1 {
+		filters = append(filters, fmt.Sprintf("(|%s)", strings.Join(any, "")))
+	}
+	filters = append(filters, assertions("none", "(!(%s=%s))")...)
+	for _, attribute := range d.Get("present").([]interface{}) {
+		filters = append(filters, fmt.Sprintf("(%s=*)", attribute))
+	}
+	for _, filter := range d.Get("filters").([]interface{}) {
+		filters = append(filters, filter.(string))
+	}
+
+	filter := ""
+	switch len(filters) {
+	case 0:
+		return fmt.Errorf("at least one of all, any, none, present or filters must be set")
+	case 1:
+		filter = filters[0]
+	default:
+		filter = fmt.Sprintf("(&%s)", strings.Join(filters, ""))
+	}
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		return fmt.Errorf("error building filter %s: %v", filter, err)
+	}
+
+	d.SetId(filter)
+	return d.Set("filter", filter)
+}
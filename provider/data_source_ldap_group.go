@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the filter matching the group classes of the usual schemas
+const ldapGroupFilter = "(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=posixGroup)(objectClass=group))"
+
+func dataSourceLDAPGroup() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPGroupRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:          schema.TypeString,
+				Description:   "The DN of the group; either it or name must be set.",
+				Optional:      true,
+				Computed:      true,
+				ExactlyOneOf:  []string{"dn", "name"},
+				ConflictsWith: []string{"base_dn"},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the group, looked up under base_dn.",
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"base_dn"},
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to look the group up under by name.",
+				Optional:    true,
+			},
+			"name_attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute holding the name of the group (e.g. sAMAccountName on Active Directory).",
+				Optional:    true,
+				Default:     "cn",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the group.",
+				Computed:    true,
+			},
+			"object_classes": {
+				Type:        schema.TypeSet,
+				Description: "The object classes of the group.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"members": {
+				Type:        schema.TypeSet,
+				Description: "The DNs of the direct members of the group (member and uniqueMember).",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"member_uids": {
+				Type:        schema.TypeSet,
+				Description: "The logins of the members of a posix group (memberUid).",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"gid_number": {
+				Type:        schema.TypeString,
+				Description: "The GID number of a posix group.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	nameAttribute := d.Get("name_attribute").(string)
+	attributes := []string{nameAttribute, "description", "objectClass", "member", "uniqueMember", "memberUid", "gidNumber"}
+
+	var entry *ldap.Entry
+	var err error
+	if dn, ok := d.GetOk("dn"); ok {
+		log.Printf("[DEBUG] ldap_group::read - reading group %q", dn)
+		if entry, err = readConfigEntry(client, dn.(string), attributes...); err == nil && entry == nil {
+			err = fmt.Errorf("group %q does not exist", dn)
+		}
+	} else {
+		baseDN, name := d.Get("base_dn").(string), d.Get("name").(string)
+		log.Printf("[DEBUG] ldap_group::read - looking up group %q under %q", name, baseDN)
+		filter := fmt.Sprintf("(&%s(%s=%s))", ldapGroupFilter, nameAttribute, ldap.EscapeFilter(name))
+		entry, err = searchLDAPEntry(client, baseDN, filter, attributes...)
+	}
+	if err != nil {
+		log.Printf("[ERROR] ldap_group::read - error reading group: %v", err)
+		return err
+	}
+
+	members := append(entry.GetEqualFoldAttributeValues("member"), entry.GetEqualFoldAttributeValues("uniqueMember")...)
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("name", entry.GetEqualFoldAttributeValue(nameAttribute))
+	d.Set("description", entry.GetEqualFoldAttributeValue("description"))
+	d.Set("object_classes", entry.GetEqualFoldAttributeValues("objectClass"))
+	d.Set("members", members)
+	d.Set("member_uids", entry.GetEqualFoldAttributeValues("memberUid"))
+	d.Set("gid_number", entry.GetEqualFoldAttributeValue("gidNumber"))
+	return nil
+}
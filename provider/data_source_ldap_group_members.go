@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the OID of Active Directory's LDAP_MATCHING_RULE_IN_CHAIN, which walks
+// linked attributes such as member transitively
+const inChainOID = "1.2.840.113556.1.4.1941"
+
+// the number of members whose classes are searched for at once
+const ldapMemberBatchSize = 100
+
+// the classes of group entries
+var ldapGroupClasses = []string{"groupOfNames", "groupOfUniqueNames", "posixGroup", "group"}
+
+func dataSourceLDAPGroupMembers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPGroupMembersRead,
+
+		Schema: map[string]*schema.Schema{
+			"group_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the group.",
+				Required:    true,
+			},
+			"recursive": {
+				Type:        schema.TypeBool,
+				Description: "Whether to expand nested groups into their members.",
+				Optional:    true,
+				Default:     false,
+			},
+			"method": {
+				Type:         schema.TypeString,
+				Description:  "How nested groups are expanded: client (reading each nested group in turn, works everywhere) or in_chain (a single search with Active Directory's matching rule in chain).",
+				Optional:     true,
+				Default:      "client",
+				ValidateFunc: validation.StringInSlice([]string{"client", "in_chain"}, false),
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search for members under with the in_chain method, usually that of the domain.",
+				Optional:    true,
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Description: "The DNs of all the members found, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"users": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the members that are not groups, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the members that are groups, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPGroupMembersRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	groupDN := d.Get("group_dn").(string)
+
+	var users, groups []string
+	var err error
+	if d.Get("recursive").(bool) && d.Get("method").(string) == "in_chain" {
+		baseDN, ok := d.GetOk("base_dn")
+		if !ok {
+			return fmt.Errorf("base_dn must be set to expand %q with the in_chain method", groupDN)
+		}
+		users, groups, err = searchLDAPGroupMembersInChain(client, baseDN.(string), groupDN)
+	} else {
+		users, groups, err = expandLDAPGroupMembers(client, groupDN, d.Get("recursive").(bool))
+	}
+	if err != nil {
+		log.Printf("[ERROR] ldap_group_members::read - error reading members of %q: %v", groupDN, err)
+		return err
+	}
+
+	sort.Strings(users)
+	sort.Strings(groups)
+	members := append(append([]string{}, users...), groups...)
+	sort.Strings(members)
+
+	log.Printf("[DEBUG] ldap_group_members::read - %q has %d members", groupDN, len(members))
+
+	d.SetId(groupDN)
+	d.Set("members", members)
+	d.Set("users", users)
+	d.Set("groups", groups)
+	return nil
+}
+
+// expandLDAPGroupMembers returns the members of a group, split into users
+// and groups, walking nested groups when recursive. Every group is read once,
+// so membership cycles end the walk instead of looping.
+func expandLDAPGroupMembers(client ldap.Client, groupDN string, recursive bool) (users, groups []string, err error) {
+	attributes := []string{"objectClass", "member", "uniqueMember"}
+	seen := map[string]bool{strings.ToLower(groupDN): true}
+	queue := []string{groupDN}
+	for len(queue) > 0 {
+		dn := queue[0]
+		queue = queue[1:]
+
+		log.Printf("[DEBUG] ldap_group_members::expand - reading members of %q", dn)
+
+		group, err := readConfigEntry(client, dn, attributes...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if group == nil {
+			if dn == groupDN {
+				return nil, nil, fmt.Errorf("group %q does not exist", groupDN)
+			}
+			continue
+		}
+
+		members := []string{}
+		for _, member := range append(group.GetEqualFoldAttributeValues("member"), group.GetEqualFoldAttributeValues("uniqueMember")...) {
+			if seen[strings.ToLower(member)] {
+				continue
+			}
+			seen[strings.ToLower(member)] = true
+			members = append(members, member)
+		}
+
+		isGroup, err := classifyLDAPGroupMembers(client, members)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range members {
+			// members that no longer exist or that we may not read are
+			// reported as users, there is nothing to expand
+			if !isGroup[ldapMemberKey(member)] {
+				users = append(users, member)
+				continue
+			}
+			groups = append(groups, member)
+			if recursive {
+				queue = append(queue, member)
+			}
+		}
+	}
+	return users, groups, nil
+}
+
+// classifyLDAPGroupMembers tells which of the given members are groups,
+// with one search per naming context and batch of ldapMemberBatchSize
+// members rather than one read per member. The members are matched on
+// entryDN, or on distinguishedName on Active Directory, which has no
+// entryDN; those outside of the naming contexts of the server are read one
+// by one.
+func classifyLDAPGroupMembers(client ldap.Client, members []string) (map[string]bool, error) {
+	isGroup := map[string]bool{}
+	if len(members) == 0 {
+		return isGroup, nil
+	}
+
+	rootDSE, err := readRootDSE(client, "supportedCapabilities")
+	if err != nil {
+		return nil, err
+	}
+	attribute := "entryDN"
+	if rootDSE != nil && stringSliceContains(rootDSE.GetEqualFoldAttributeValues("supportedCapabilities"), adCapabilityOID) {
+		attribute = "distinguishedName"
+	}
+
+	byContext := map[string][]string{}
+	for _, member := range members {
+		context, err := namingContextOf(client, member)
+		if err != nil {
+			entry, err := readConfigEntry(client, member, "objectClass")
+			if err != nil {
+				return nil, err
+			}
+			isGroup[ldapMemberKey(member)] = entry != nil && isLDAPGroup(entry)
+			continue
+		}
+		byContext[context] = append(byContext[context], member)
+	}
+
+	classes := ""
+	for _, class := range ldapGroupClasses {
+		classes += fmt.Sprintf("(objectClass=%s)", class)
+	}
+	for context, members := range byContext {
+		for start := 0; start < len(members); start += ldapMemberBatchSize {
+			end := start + ldapMemberBatchSize
+			if end > len(members) {
+				end = len(members)
+			}
+			dns := ""
+			for _, member := range members[start:end] {
+				dns += fmt.Sprintf("(%s=%s)", attribute, ldap.EscapeFilter(member))
+			}
+
+			log.Printf("[DEBUG] ldap_group_members::classify - looking for groups among %d members under %q", end-start, context)
+
+			request := ldap.NewSearchRequest(
+				context,
+				ldap.ScopeWholeSubtree,
+				ldap.NeverDerefAliases,
+				0,
+				0,
+				false,
+				fmt.Sprintf("(&(|%s)(|%s))", classes, dns),
+				[]string{"objectClass"},
+				nil,
+			)
+			sr, err := client.Search(request)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range sr.Entries {
+				isGroup[ldapMemberKey(entry.DN)] = true
+			}
+		}
+	}
+	return isGroup, nil
+}
+
+// ldapMemberKey returns the form of a member DN under which the DNs the
+// server returns match the values of member attributes.
+func ldapMemberKey(dn string) string {
+	if parsed, err := ldap.ParseDN(dn); err == nil {
+		return strings.ToLower(normalizeDN(parsed))
+	}
+	return strings.ToLower(dn)
+}
+
+// searchLDAPGroupMembersInChain returns the transitive members of a group on
+// Active Directory, split into users and groups, with a single search.
+func searchLDAPGroupMembersInChain(client ldap.Client, baseDN, groupDN string) (users, groups []string, err error) {
+	log.Printf("[DEBUG] ldap_group_members::in_chain - searching members of %q under %q", groupDN, baseDN)
+
+	request := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(memberOf:%s:=%s)", inChainOID, ldap.EscapeFilter(groupDN)),
+		[]string{"objectClass"},
+		nil,
+	)
+	// AD caps unpaged results at its MaxPageSize, which large groups exceed
+	sr, err := client.SearchWithPaging(request, ldapDefaultPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range sr.Entries {
+		if isLDAPGroup(entry) {
+			groups = append(groups, entry.DN)
+		} else {
+			users = append(users, entry.DN)
+		}
+	}
+	return users, groups, nil
+}
+
+func isLDAPGroup(entry *ldap.Entry) bool {
+	for _, objectClass := range entry.GetEqualFoldAttributeValues("objectClass") {
+		for _, class := range ldapGroupClasses {
+			if strings.EqualFold(objectClass, class) {
+				return true
+			}
+		}
+	}
+	return false
+}
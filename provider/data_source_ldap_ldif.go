@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPLDIF() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPLDIFRead,
+
+		Schema: map[string]*schema.Schema{
+			"entry": {
+				Type:        schema.TypeList,
+				Description: "The entries to render, in order.",
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn": {
+							Type:        schema.TypeString,
+							Description: "The DN of the entry.",
+							Required:    true,
+						},
+						"object_classes": {
+							Type:        schema.TypeList,
+							Description: "The objectClass values of the entry, rendered first.",
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"attributes": {
+							Type:        schema.TypeList,
+							Description: "The other attribute values of the entry, in the { name = value } form of the attributes of ldap_object.",
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeMap,
+								Elem: &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+			},
+			"ldif": {
+				Type:        schema.TypeString,
+				Description: "The entries as LDIF content records, with unsafe values base64 encoded and long lines folded.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPLDIFRead(d *schema.ResourceData, meta interface{}) error {
+	entries := []util.LDIFEntry{}
+	for _, e := range d.Get("entry").([]interface{}) {
+		e := e.(map[string]interface{})
+		entry := util.LDIFEntry{DN: e["dn"].(string)}
+		for _, objectClass := range e["object_classes"].([]interface{}) {
+			entry.Attributes = append(entry.Attributes, util.LDIFAttribute{Name: "objectClass", Value: objectClass.(string)})
+		}
+		for _, values := range e["attributes"].([]interface{}) {
+			values, _ := values.(map[string]interface{})
+			names := make([]string, 0, len(values))
+			for name := range values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				entry.Attributes = append(entry.Attributes, util.LDIFAttribute{Name: name, Value: values[name].(string)})
+			}
+		}
+		entries = append(entries, entry)
+	}
+	ldif := util.FormatLDIF(entries)
+
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256([]byte(ldif))))
+	return d.Set("ldif", ldif)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPLDIFEntries() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPLDIFEntriesRead,
+
+		Schema: map[string]*schema.Schema{
+			"ldif": {
+				Type:        schema.TypeString,
+				Description: "The content of the LDIF file (e.g. file(\"export.ldif\")), with content or add records.",
+				Required:    true,
+			},
+			"entries": {
+				Type:        schema.TypeList,
+				Description: "The entries of the file, in order.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn": {
+							Type:        schema.TypeString,
+							Description: "The DN of the entry.",
+							Computed:    true,
+						},
+						"object_classes": {
+							Type:        schema.TypeList,
+							Description: "The objectClass values of the entry.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"attributes": {
+							Type:        schema.TypeList,
+							Description: "The other attribute values of the entry, in the { name = value } form of the attributes of ldap_object.",
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeMap,
+								Elem: &schema.Schema{Type: schema.TypeString},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPLDIFEntriesRead(d *schema.ResourceData, meta interface{}) error {
+	ldif := d.Get("ldif").(string)
+	parsed, err := util.ParseLDIF(ldif)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]interface{}, 0, len(parsed))
+	for _, entry := range parsed {
+		objectClasses := []string{}
+		attributes := []interface{}{}
+		for _, attribute := range entry.Attributes {
+			if strings.EqualFold(attribute.Name, "objectClass") {
+				objectClasses = append(objectClasses, attribute.Value)
+			} else {
+				attributes = append(attributes, map[string]interface{}{attribute.Name: attribute.Value})
+			}
+		}
+		entries = append(entries, map[string]interface{}{
+			"dn":             entry.DN,
+			"object_classes": objectClasses,
+			"attributes":     attributes,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256([]byte(ldif))))
+	return d.Set("entries", entries)
+}
@@ -0,0 +1,9 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPNextGIDNumber() *schema.Resource {
+	return ldapNextIDNumberDataSource("gidNumber", "posixGroup")
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPNextUIDNumber() *schema.Resource {
+	return ldapNextIDNumberDataSource("uidNumber", "posixAccount")
+}
+
+// ldapNextIDNumberDataSource returns a data source allocating the next free
+// value of a posix ID attribute (e.g. uidNumber) among the entries of the
+// given class, or reading it from a counter entry.
+func ldapNextIDNumberDataSource(attribute, objectClass string) *schema.Resource {
+	return &schema.Resource{
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return readLDAPNextIDNumber(d, meta, attribute, objectClass)
+		},
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:         schema.TypeString,
+				Description:  fmt.Sprintf("The DN to search for %s entries under; the next number follows the highest %s in the range.", objectClass, attribute),
+				Optional:     true,
+				ExactlyOneOf: []string{"base_dn", "counter_dn"},
+			},
+			"counter_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of an entry holding the next number instead, e.g. a sambaUnixIdPool entry or the configuration of a DNA plugin; it is only read, see ldap_id_number_reservation to take the number.",
+				Optional:    true,
+			},
+			"counter_attribute": {
+				Type:        schema.TypeString,
+				Description: fmt.Sprintf("The attribute of the counter entry holding the next number (e.g. dnaNextValue); defaults to %s.", attribute),
+				Optional:    true,
+				Default:     attribute,
+			},
+			"min": {
+				Type:        schema.TypeInt,
+				Description: "The lowest number of the range.",
+				Optional:    true,
+				Default:     10000,
+			},
+			"max": {
+				Type:        schema.TypeInt,
+				Description: "The highest number of the range.",
+				Optional:    true,
+				Default:     60000,
+			},
+			"number": {
+				Type:        schema.TypeInt,
+				Description: "The next free number.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func readLDAPNextIDNumber(d *schema.ResourceData, meta interface{}, attribute, objectClass string) error {
+	client := meta.(ldap.Client)
+	min, max := d.Get("min").(int), d.Get("max").(int)
+	if min > max {
+		return fmt.Errorf("min (%d) must not be greater than max (%d)", min, max)
+	}
+
+	var next int
+	if counterDN, ok := d.GetOk("counter_dn"); ok {
+		counterAttribute := d.Get("counter_attribute").(string)
+
+		log.Printf("[DEBUG] ldap_next_id_number::read - reading %s of %q", counterAttribute, counterDN)
+
+		var err error
+		if next, err = readLDAPIDCounter(client, counterDN.(string), counterAttribute, min, max, false); err != nil {
+			return err
+		}
+		d.SetId(fmt.Sprintf("%s:%s", counterDN, counterAttribute))
+	} else {
+		baseDN := d.Get("base_dn").(string)
+		filter := fmt.Sprintf("(&(objectClass=%s)(%s>=%d)(%s<=%d))", objectClass, attribute, min, attribute, max)
+
+		log.Printf("[DEBUG] ldap_next_id_number::read - searching %q for %s", baseDN, filter)
+
+		request := ldap.NewSearchRequest(
+			baseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			filter,
+			[]string{attribute},
+			nil,
+		)
+		// directories can have many accounts, so page through them
+		sr, err := client.SearchWithPaging(request, ldapDefaultPageSize)
+		if err != nil {
+			log.Printf("[ERROR] ldap_next_id_number::read - error searching %q: %v", baseDN, err)
+			return err
+		}
+		used := make([]int, 0, len(sr.Entries))
+		for _, entry := range sr.Entries {
+			if id, err := strconv.Atoi(entry.GetEqualFoldAttributeValue(attribute)); err == nil {
+				used = append(used, id)
+			}
+		}
+		if next, err = util.NextIDNumber(used, min, max); err != nil {
+			return err
+		}
+		d.SetId(fmt.Sprintf("%s?sub?%s", baseDN, filter))
+	}
+
+	log.Printf("[DEBUG] ldap_next_id_number::read - next %s is %d", attribute, next)
+
+	return d.Set("number", next)
+}
+
+// readLDAPIDCounter returns the number held by a counter entry, checking it
+// is within [min, max]. When reserving, the counter is incremented by
+// deleting the value read and adding the next one in a single modify, which
+// fails if another client changed it in the meantime; the increment is then
+// retried with the new value. It is never repeated on a new connection, as
+// its failure could then not be told from the change of another client.
+func readLDAPIDCounter(client ldap.Client, dn, attribute string, min, max int, reserve bool) (int, error) {
+	for attempt := 0; ; attempt++ {
+		entry, err := readConfigEntry(client, dn, attribute)
+		if err != nil {
+			return 0, err
+		}
+		if entry == nil {
+			return 0, fmt.Errorf("counter entry %q does not exist", dn)
+		}
+		value := entry.GetEqualFoldAttributeValue(attribute)
+		next, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q in counter entry %q", attribute, value, dn)
+		}
+		if next < min || next > max {
+			return 0, fmt.Errorf("the %s of counter entry %q (%d) is outside [%d, %d]", attribute, dn, next, min, max)
+		}
+		if !reserve {
+			return next, nil
+		}
+
+		log.Printf("[DEBUG] ldap_next_id_number::reserve - incrementing %s of %q from %d", attribute, dn, next)
+
+		modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+		modify.Delete(attribute, []string{value})
+		modify.Add(attribute, []string{strconv.Itoa(next + 1)})
+		if pool, ok := client.(*ldapPool); ok {
+			err = pool.modifyOnce(modify)
+		} else {
+			err = client.Modify(modify)
+		}
+		if err == nil {
+			return next, nil
+		}
+		if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) || attempt == 9 {
+			log.Printf("[ERROR] ldap_next_id_number::reserve - error incrementing %s of %q: %v", attribute, dn, err)
+			return 0, err
+		}
+		log.Printf("[WARN] ldap_next_id_number::reserve - %s of %q changed concurrently, retrying", attribute, dn)
+	}
+}
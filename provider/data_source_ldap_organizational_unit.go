@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPOrganizationalUnit() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPOrganizationalUnitRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:         schema.TypeString,
+				Description:  "The DN of the organizational unit; exactly one of dn, path and name must be set.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"dn", "path", "name"},
+			},
+			"path": {
+				Type:         schema.TypeString,
+				Description:  "The slash-separated names of the organizational unit and its parents below base_dn, outermost first (e.g. Engineering/Backend).",
+				Optional:     true,
+				RequiredWith: []string{"base_dn"},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the organizational unit, which must be unique under base_dn.",
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"base_dn"},
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN the path is relative to, or to look the name up under.",
+				Optional:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the organizational unit.",
+				Computed:    true,
+			},
+			"object_classes": {
+				Type:        schema.TypeSet,
+				Description: "The object classes of the organizational unit.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+			},
+			"attributes": {
+				Type:        schema.TypeMap,
+				Description: "The first value of each user attribute of the organizational unit.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPOrganizationalUnitRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	var entry *ldap.Entry
+	var err error
+	if name, ok := d.GetOk("name"); ok {
+		baseDN := d.Get("base_dn").(string)
+		log.Printf("[DEBUG] ldap_organizational_unit::read - looking up %q under %q", name, baseDN)
+		entry, err = searchLDAPEntry(client, baseDN, fmt.Sprintf("(&(objectClass=organizationalUnit)(ou=%s))", ldap.EscapeFilter(name.(string))))
+	} else {
+		dn := d.Get("dn").(string)
+		if path, ok := d.GetOk("path"); ok {
+			dn = ldapOrganizationalUnitPathDN(d.Get("base_dn").(string), path.(string))
+		}
+		log.Printf("[DEBUG] ldap_organizational_unit::read - reading %q", dn)
+		if entry, err = readConfigEntry(client, dn); err == nil && entry == nil {
+			err = fmt.Errorf("organizational unit %q does not exist", dn)
+		}
+	}
+	if err != nil {
+		log.Printf("[ERROR] ldap_organizational_unit::read - error reading organizational unit: %v", err)
+		return err
+	}
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("name", entry.GetEqualFoldAttributeValue("ou"))
+	d.Set("description", entry.GetEqualFoldAttributeValue("description"))
+	d.Set("object_classes", entry.GetEqualFoldAttributeValues("objectClass"))
+	d.Set("attributes", flattenLDAPEntry(entry)["attributes"])
+	return nil
+}
+
+// ldapOrganizationalUnitPathDN returns the DN of the organizational unit at
+// the given slash-separated path, outermost first, below baseDN.
+func ldapOrganizationalUnitPathDN(baseDN, path string) string {
+	dn := baseDN
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		dn = fmt.Sprintf("ou=%s,%s", ldap.EscapeDN(name), dn)
+	}
+	return dn
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the capability Active Directory domain controllers advertise in their
+// root DSE (LDAP_CAP_ACTIVE_DIRECTORY_OID)
+const adCapabilityOID = "1.2.840.113556.1.4.800"
+
+// the attributes of the password policy sources, by argument name: OpenLDAP
+// ppolicy entries, AD password settings objects and AD domains
+var (
+	ppolicyAttributes = map[string]string{
+		"max_age":                    "pwdMaxAge",
+		"min_age":                    "pwdMinAge",
+		"min_length":                 "pwdMinLength",
+		"history_length":             "pwdInHistory",
+		"lockout_threshold":          "pwdMaxFailure",
+		"lockout_duration":           "pwdLockoutDuration",
+		"lockout_observation_window": "pwdFailureCountInterval",
+	}
+	adPSOAttributes = map[string]string{
+		"max_age":                    "msDS-MaximumPasswordAge",
+		"min_age":                    "msDS-MinimumPasswordAge",
+		"min_length":                 "msDS-MinimumPasswordLength",
+		"history_length":             "msDS-PasswordHistoryLength",
+		"lockout_threshold":          "msDS-LockoutThreshold",
+		"lockout_duration":           "msDS-LockoutDuration",
+		"lockout_observation_window": "msDS-LockoutObservationWindow",
+	}
+	adDomainPolicyAttributes = map[string]string{
+		"max_age":                    "maxPwdAge",
+		"min_age":                    "minPwdAge",
+		"min_length":                 "minPwdLength",
+		"history_length":             "pwdHistoryLength",
+		"lockout_threshold":          "lockoutThreshold",
+		"lockout_duration":           "lockoutDuration",
+		"lockout_observation_window": "lockOutObservationWindow",
+	}
+)
+
+// the arguments holding AD intervals rather than counts
+var adIntervalKeys = map[string]bool{
+	"max_age":                    true,
+	"min_age":                    true,
+	"lockout_duration":           true,
+	"lockout_observation_window": true,
+}
+
+func dataSourceLDAPPasswordPolicy() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPPasswordPolicyRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the entry to resolve the policy of.",
+				Required:    true,
+			},
+			"default_policy_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the default ppolicy of an OpenLDAP server (olcPPolicyDefault), used when the entry has no pwdPolicySubentry.",
+				Optional:    true,
+			},
+			"policy_dn":                  computed(schema.TypeString, "The DN of the policy in effect: a ppolicy entry, a password settings object or the domain."),
+			"source":                     computed(schema.TypeString, "Where the policy comes from: pwdPolicySubentry, default, msDS-ResultantPSO or domain."),
+			"max_age":                    computed(schema.TypeInt, "The maximum age of passwords in seconds; 0 when they never expire."),
+			"min_age":                    computed(schema.TypeInt, "The minimum age of passwords in seconds."),
+			"min_length":                 computed(schema.TypeInt, "The minimum length of passwords."),
+			"history_length":             computed(schema.TypeInt, "The number of previous passwords that may not be reused."),
+			"lockout_threshold":          computed(schema.TypeInt, "The number of failed binds locking the account out; 0 when lockout is disabled."),
+			"lockout_duration":           computed(schema.TypeInt, "How long accounts stay locked out in seconds; 0 until an administrator unlocks them."),
+			"lockout_observation_window": computed(schema.TypeInt, "The interval in seconds after which failed binds are forgotten."),
+			"complexity_enabled":         computed(schema.TypeBool, "Whether passwords must meet complexity (AD) or quality checks (pwdCheckQuality)."),
+			"attributes": {
+				Type:        schema.TypeMap,
+				Description: "The first value of each attribute of the policy entry, for settings not exposed above; only the policy attributes of a domain.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPPasswordPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+
+	rootDSE, err := readRootDSE(client, "supportedCapabilities", "defaultNamingContext")
+	if err != nil {
+		return err
+	}
+	isAD := rootDSE != nil && stringSliceContains(rootDSE.GetEqualFoldAttributeValues("supportedCapabilities"), adCapabilityOID)
+
+	// pwdPolicySubentry and msDS-ResultantPSO are operational and constructed
+	// attributes, only returned when asked for
+	entry, err := readConfigEntry(client, dn, "pwdPolicySubentry", "msDS-ResultantPSO")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("entry %q does not exist", dn)
+	}
+
+	var policyDN, source string
+	var attributes map[string]string
+	switch {
+	case isAD && entry.GetEqualFoldAttributeValue("msDS-ResultantPSO") != "":
+		policyDN, source, attributes = entry.GetEqualFoldAttributeValue("msDS-ResultantPSO"), "msDS-ResultantPSO", adPSOAttributes
+	case isAD:
+		policyDN, source, attributes = rootDSE.GetEqualFoldAttributeValue("defaultNamingContext"), "domain", adDomainPolicyAttributes
+	case entry.GetEqualFoldAttributeValue("pwdPolicySubentry") != "":
+		policyDN, source, attributes = entry.GetEqualFoldAttributeValue("pwdPolicySubentry"), "pwdPolicySubentry", ppolicyAttributes
+	case d.Get("default_policy_dn").(string) != "":
+		policyDN, source, attributes = d.Get("default_policy_dn").(string), "default", ppolicyAttributes
+	default:
+		return fmt.Errorf("entry %q has no pwdPolicySubentry; set default_policy_dn to the default policy of the server", dn)
+	}
+
+	log.Printf("[DEBUG] ldap_password_policy::read - policy of %q is %q (%s)", dn, policyDN, source)
+
+	// the domain root holds much more than its policy, so only ask for that
+	names := []string{"pwdProperties", "msDS-PasswordComplexityEnabled", "pwdCheckQuality"}
+	for _, attribute := range attributes {
+		names = append(names, attribute)
+	}
+	if source != "domain" {
+		names = append(names, "*")
+	}
+	policy, err := readConfigEntry(client, policyDN, names...)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return fmt.Errorf("password policy %q of %q does not exist", policyDN, dn)
+	}
+
+	for key, attribute := range attributes {
+		value := policy.GetEqualFoldAttributeValue(attribute)
+		var n int64
+		switch {
+		case value == "":
+		case isAD && adIntervalKeys[key]:
+			n, err = util.ADIntervalSeconds(value)
+		default:
+			n, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s %q in password policy %q: %v", attribute, value, policyDN, err)
+		}
+		d.Set(key, int(n))
+	}
+	complexity := false
+	switch source {
+	case "msDS-ResultantPSO":
+		complexity = strings.EqualFold(policy.GetEqualFoldAttributeValue("msDS-PasswordComplexityEnabled"), "TRUE")
+	case "domain":
+		// DOMAIN_PASSWORD_COMPLEX is the first bit of pwdProperties
+		properties, _ := strconv.Atoi(policy.GetEqualFoldAttributeValue("pwdProperties"))
+		complexity = properties&1 != 0
+	default:
+		quality, _ := strconv.Atoi(policy.GetEqualFoldAttributeValue("pwdCheckQuality"))
+		complexity = quality > 0
+	}
+
+	d.SetId(dn)
+	d.Set("policy_dn", policyDN)
+	d.Set("source", source)
+	d.Set("complexity_enabled", complexity)
+	d.Set("attributes", flattenLDAPEntry(policy)["attributes"])
+	return nil
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the RDN of the 389 Directory Server tombstone holding the replica update
+// vector of a suffix
+const ruvTombstoneRDN = "nsuniqueid=ffffffff-ffffffff-ffffffff-ffffffff"
+
+func dataSourceLDAPReplicationStatus() *schema.Resource {
+	computed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        t,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPReplicationStatusRead,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The replicated suffix; defaults to the first naming context of the server.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"kind":                  computed(schema.TypeString, "The replication indicators found: ad (highestCommittedUSN), openldap (contextCSN), 389ds (replica update vector) or none."),
+			"latest_change":         computed(schema.TypeString, "The time of the latest change applied to the suffix in RFC 3339 format, from contextCSN or the replica update vector."),
+			"state":                 computed(schema.TypeString, "A token of the replication state of the suffix (the sorted CSNs, or the USN on Active Directory): two servers holding the same token have converged."),
+			"highest_committed_usn": computed(schema.TypeInt, "The highest update sequence number committed by an Active Directory domain controller; it is local to each server."),
+			"synchronized":          computed(schema.TypeBool, "Whether an Active Directory domain controller completed its initial synchronization (isSynchronized)."),
+			"context_csns": {
+				Type:        schema.TypeList,
+				Description: "The contextCSN values of an OpenLDAP suffix, one per server changes originate from, sorted by server ID.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"server_id": computed(schema.TypeString, "The (hexadecimal) ID of the server."),
+						"csn":       computed(schema.TypeString, "The CSN of the latest change from the server."),
+						"timestamp": computed(schema.TypeString, "The time of the latest change from the server in RFC 3339 format."),
+					},
+				},
+			},
+			"replicas": {
+				Type:        schema.TypeList,
+				Description: "The replicas of the replica update vector of a 389 Directory Server suffix, sorted by replica ID.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"replica_id": computed(schema.TypeString, "The ID of the replica."),
+						"url":        computed(schema.TypeString, "The URL of the replica."),
+						"max_csn":    computed(schema.TypeString, "The CSN of the latest change from the replica; empty when it originated none."),
+						"timestamp":  computed(schema.TypeString, "The time of the latest change from the replica in RFC 3339 format."),
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPReplicationStatusRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	rootDSE, err := readConfigEntry(client, "", "namingContexts", "highestCommittedUSN", "isSynchronized")
+	if err != nil {
+		return err
+	}
+	if rootDSE == nil {
+		rootDSE = &ldap.Entry{}
+	}
+	baseDN := d.Get("base_dn").(string)
+	if baseDN == "" {
+		if baseDN = rootDSE.GetEqualFoldAttributeValue("namingContexts"); baseDN == "" {
+			return fmt.Errorf("the root DSE advertises no naming context; set base_dn explicitly")
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_replication_status::read - reading the replication state of %q", baseDN)
+
+	kind, state, latest := "none", "", time.Time{}
+	contextCSNs := make([]interface{}, 0)
+	replicas := make([]interface{}, 0)
+	usn, _ := strconv.ParseInt(rootDSE.GetEqualFoldAttributeValue("highestCommittedUSN"), 10, 64)
+
+	if rootDSE.GetEqualFoldAttributeValue("highestCommittedUSN") != "" {
+		kind, state = "ad", rootDSE.GetEqualFoldAttributeValue("highestCommittedUSN")
+	} else {
+		suffix, err := readConfigEntry(client, baseDN, "contextCSN")
+		if err != nil {
+			return err
+		}
+		if suffix == nil {
+			return fmt.Errorf("suffix %q does not exist", baseDN)
+		}
+		csns := suffix.GetEqualFoldAttributeValues("contextCSN")
+		sort.Strings(csns)
+		for _, csn := range csns {
+			t, serverID, err := util.ParseContextCSN(csn)
+			if err != nil {
+				return err
+			}
+			if t.After(latest) {
+				latest = t
+			}
+			contextCSNs = append(contextCSNs, map[string]interface{}{
+				"server_id": serverID,
+				"csn":       csn,
+				"timestamp": t.Format(time.RFC3339Nano),
+			})
+		}
+		if len(csns) > 0 {
+			kind, state = "openldap", strings.Join(csns, ",")
+		} else if replicas, state, latest, err = readLDAPReplicaUpdateVector(client, baseDN); err != nil {
+			return err
+		} else if len(replicas) > 0 {
+			kind = "389ds"
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_replication_status::read - state of %q (%s): %s", baseDN, kind, state)
+
+	latestChange := ""
+	if !latest.IsZero() {
+		latestChange = latest.Format(time.RFC3339Nano)
+	}
+	d.SetId(baseDN)
+	d.Set("base_dn", baseDN)
+	d.Set("kind", kind)
+	d.Set("state", state)
+	d.Set("latest_change", latestChange)
+	d.Set("highest_committed_usn", int(usn))
+	d.Set("synchronized", strings.EqualFold(rootDSE.GetEqualFoldAttributeValue("isSynchronized"), "TRUE"))
+	d.Set("context_csns", contextCSNs)
+	return d.Set("replicas", replicas)
+}
+
+// readLDAPReplicaUpdateVector reads the replica update vector of a 389
+// Directory Server suffix, returning its replicas, the state token of the
+// suffix and the time of its latest change. Servers without one yield no
+// replicas rather than an error.
+func readLDAPReplicaUpdateVector(client ldap.Client, baseDN string) ([]interface{}, string, time.Time, error) {
+	// the tombstone is only returned when asked for by class
+	request := ldap.NewSearchRequest(
+		fmt.Sprintf("%s,%s", ruvTombstoneRDN, baseDN),
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=nsTombstone)",
+		[]string{"nsds50ruv"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, "", time.Time{}, err
+	}
+	replicas := make([]interface{}, 0)
+	if sr == nil || len(sr.Entries) == 0 {
+		return replicas, "", time.Time{}, nil
+	}
+
+	elements := []util.RUVElement{}
+	for _, value := range sr.Entries[0].GetEqualFoldAttributeValues("nsds50ruv") {
+		if element, ok := util.ParseRUVElement(value); ok {
+			elements = append(elements, element)
+		}
+	}
+	sort.SliceStable(elements, func(i, j int) bool {
+		return elements[i].ReplicaID < elements[j].ReplicaID
+	})
+	csns := []string{}
+	latest := time.Time{}
+	for _, element := range elements {
+		timestamp := ""
+		if element.MaxCSN != "" {
+			t, err := util.DS389CSNTime(element.MaxCSN)
+			if err != nil {
+				return nil, "", time.Time{}, err
+			}
+			if t.After(latest) {
+				latest = t
+			}
+			timestamp = t.Format(time.RFC3339)
+			csns = append(csns, element.MaxCSN)
+		}
+		replicas = append(replicas, map[string]interface{}{
+			"replica_id": element.ReplicaID,
+			"url":        element.URL,
+			"max_csn":    element.MaxCSN,
+			"timestamp":  timestamp,
+		})
+	}
+	return replicas, strings.Join(csns, ","), latest, nil
+}
@@ -0,0 +1,352 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPSchema() *schema.Resource {
+	names := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeList,
+			Description: description,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		}
+	}
+	text := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeString,
+			Description: description,
+			Computed:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPSchemaRead,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the subschema subentry; read from the root DSE when unset.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"object_classes": {
+				Type:        schema.TypeList,
+				Description: "The object classes of the schema, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid":         text("The OID of the object class."),
+						"name":        text("The first name of the object class."),
+						"names":       names("All the names of the object class."),
+						"description": text("The description of the object class."),
+						"superiors":   names("The superior classes of the object class."),
+						"kind":        text("The kind of the object class: structural, auxiliary or abstract."),
+						"must":        names("The attributes entries of the class must have, excluding those of the superior classes."),
+						"may":         names("The attributes entries of the class may have, excluding those of the superior classes."),
+						"obsolete": {
+							Type:        schema.TypeBool,
+							Description: "Whether the object class is obsolete.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"attribute_types": {
+				Type:        schema.TypeList,
+				Description: "The attribute types of the schema, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid":         text("The OID of the attribute type."),
+						"name":        text("The first name of the attribute type."),
+						"names":       names("All the names of the attribute type."),
+						"description": text("The description of the attribute type."),
+						"superior":    text("The attribute type this one derives from."),
+						"syntax":      text("The OID of the syntax of the attribute type, inherited from the superior type when not set, without any length bound."),
+						"equality":    text("The equality matching rule of the attribute type, inherited from the superior type when not set."),
+						"ordering":    text("The ordering matching rule of the attribute type, inherited from the superior type when not set."),
+						"substring":   text("The substrings matching rule of the attribute type, inherited from the superior type when not set."),
+						"usage":       text("The usage of the attribute type: userApplications, directoryOperation, distributedOperation or dSAOperation."),
+						"single_valued": {
+							Type:        schema.TypeBool,
+							Description: "Whether the attribute type holds a single value.",
+							Computed:    true,
+						},
+						"no_user_modification": {
+							Type:        schema.TypeBool,
+							Description: "Whether the attribute type is maintained by the server only.",
+							Computed:    true,
+						},
+						"obsolete": {
+							Type:        schema.TypeBool,
+							Description: "Whether the attribute type is obsolete.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"matching_rules": {
+				Type:        schema.TypeList,
+				Description: "The matching rules of the schema, sorted by name; empty when the server does not publish them (e.g. Active Directory).",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid":         text("The OID of the matching rule."),
+						"name":        text("The first name of the matching rule."),
+						"names":       names("All the names of the matching rule."),
+						"description": text("The description of the matching rule."),
+						"syntax":      text("The OID of the syntax of the assertion values of the matching rule."),
+						"applies_to":  names("The attribute types the matching rule may be used with in extensible filters (matchingRuleUse)."),
+						"obsolete": {
+							Type:        schema.TypeBool,
+							Description: "Whether the matching rule is obsolete.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"syntaxes": {
+				Type:        schema.TypeList,
+				Description: "The LDAP syntaxes of the schema, sorted by OID; empty when the server does not publish them.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid":         text("The OID of the syntax."),
+						"description": text("The description of the syntax (e.g. Directory String)."),
+						"human_readable": {
+							Type:        schema.TypeBool,
+							Description: "Whether values of the syntax are human readable, i.e. not flagged X-NOT-HUMAN-READABLE or X-BINARY-TRANSFER-REQUIRED.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPSchemaRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	entry, err := readSubschemaEntry(client, d.Get("dn").(string), "objectClasses", "attributeTypes", "matchingRules", "matchingRuleUse", "ldapSyntaxes")
+	if err != nil {
+		log.Printf("[ERROR] ldap_schema::read - error reading the subschema subentry: %v", err)
+		return err
+	}
+
+	objectClasses, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("objectClasses"))
+	if err != nil {
+		return err
+	}
+	classes := make([]interface{}, 0, len(objectClasses))
+	for _, definition := range objectClasses {
+		kind := "structural"
+		for _, k := range []string{"AUXILIARY", "ABSTRACT"} {
+			if _, ok := definition.fields[k]; ok {
+				kind = strings.ToLower(k)
+			}
+		}
+		classes = append(classes, map[string]interface{}{
+			"oid":         definition.oid,
+			"name":        definition.name(),
+			"names":       definition.fields["NAME"],
+			"description": definition.field("DESC"),
+			"superiors":   definition.fields["SUP"],
+			"kind":        kind,
+			"must":        definition.fields["MUST"],
+			"may":         definition.fields["MAY"],
+			"obsolete":    definition.flag("OBSOLETE"),
+		})
+	}
+
+	attributeTypes, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("attributeTypes"))
+	if err != nil {
+		return err
+	}
+	byName := map[string]schemaDefinition{}
+	for _, definition := range attributeTypes {
+		byName[strings.ToLower(definition.oid)] = definition
+		for _, name := range definition.fields["NAME"] {
+			byName[strings.ToLower(name)] = definition
+		}
+	}
+	// the syntax and matching rules of a type may come from its superiors
+	inherited := func(definition schemaDefinition, keyword string) string {
+		for depth := 0; depth < 16; depth++ {
+			if value := definition.field(keyword); value != "" {
+				return value
+			}
+			superior, ok := byName[strings.ToLower(definition.field("SUP"))]
+			if !ok {
+				break
+			}
+			definition = superior
+		}
+		return ""
+	}
+	types := make([]interface{}, 0, len(attributeTypes))
+	for _, definition := range attributeTypes {
+		syntax := inherited(definition, "SYNTAX")
+		if i := strings.IndexByte(syntax, '{'); i >= 0 {
+			syntax = syntax[:i]
+		}
+		usage := definition.field("USAGE")
+		if usage == "" {
+			usage = "userApplications"
+		}
+		types = append(types, map[string]interface{}{
+			"oid":                  definition.oid,
+			"name":                 definition.name(),
+			"names":                definition.fields["NAME"],
+			"description":          definition.field("DESC"),
+			"superior":             definition.field("SUP"),
+			"syntax":               syntax,
+			"equality":             inherited(definition, "EQUALITY"),
+			"ordering":             inherited(definition, "ORDERING"),
+			"substring":            inherited(definition, "SUBSTR"),
+			"usage":                usage,
+			"single_valued":        definition.flag("SINGLE-VALUE"),
+			"no_user_modification": definition.flag("NO-USER-MODIFICATION"),
+			"obsolete":             definition.flag("OBSOLETE"),
+		})
+	}
+
+	matchingRuleUse, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("matchingRuleUse"))
+	if err != nil {
+		return err
+	}
+	appliesTo := map[string][]string{}
+	for _, definition := range matchingRuleUse {
+		appliesTo[definition.oid] = definition.fields["APPLIES"]
+	}
+	matchingRules, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("matchingRules"))
+	if err != nil {
+		return err
+	}
+	rules := make([]interface{}, 0, len(matchingRules))
+	for _, definition := range matchingRules {
+		rules = append(rules, map[string]interface{}{
+			"oid":         definition.oid,
+			"name":        definition.name(),
+			"names":       definition.fields["NAME"],
+			"description": definition.field("DESC"),
+			"syntax":      definition.field("SYNTAX"),
+			"applies_to":  appliesTo[definition.oid],
+			"obsolete":    definition.flag("OBSOLETE"),
+		})
+	}
+
+	ldapSyntaxes, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("ldapSyntaxes"))
+	if err != nil {
+		return err
+	}
+	syntaxes := make([]interface{}, 0, len(ldapSyntaxes))
+	for _, definition := range ldapSyntaxes {
+		syntaxes = append(syntaxes, map[string]interface{}{
+			"oid":            definition.oid,
+			"description":    definition.field("DESC"),
+			"human_readable": !strings.EqualFold(definition.field("X-NOT-HUMAN-READABLE"), "TRUE") && !strings.EqualFold(definition.field("X-BINARY-TRANSFER-REQUIRED"), "TRUE"),
+		})
+	}
+
+	log.Printf("[DEBUG] ldap_schema::read - %q has %d object classes, %d attribute types, %d matching rules and %d syntaxes", entry.DN, len(classes), len(types), len(rules), len(syntaxes))
+
+	d.SetId(entry.DN)
+	d.Set("dn", entry.DN)
+	d.Set("object_classes", classes)
+	d.Set("matching_rules", rules)
+	d.Set("syntaxes", syntaxes)
+	return d.Set("attribute_types", types)
+}
+
+// readSubschemaEntry reads the given attributes of the subschema subentry at
+// dn, or of the one advertised by the root DSE when dn is empty, through the
+// cache.
+func readSubschemaEntry(client ldap.Client, dn string, attributes ...string) (*ldap.Entry, error) {
+	if dn == "" {
+		rootDSE, err := readRootDSE(client, "subschemaSubentry")
+		if err != nil {
+			return nil, err
+		}
+		if rootDSE == nil || rootDSE.GetEqualFoldAttributeValue("subschemaSubentry") == "" {
+			return nil, fmt.Errorf("the root DSE does not advertise a subschema subentry")
+		}
+		dn = rootDSE.GetEqualFoldAttributeValue("subschemaSubentry")
+	}
+
+	return readCachedEntry(client, dn, attributes, func() (*ldap.Entry, error) {
+		log.Printf("[DEBUG] ldap_schema::read - reading subschema subentry %q", dn)
+
+		request := ldap.NewSearchRequest(
+			dn,
+			ldap.ScopeBaseObject,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			"(objectClass=subschema)",
+			attributes,
+			nil,
+		)
+		sr, err := client.Search(request)
+		if err != nil {
+			return nil, err
+		}
+		if len(sr.Entries) == 0 {
+			return nil, fmt.Errorf("%q is not a subschema subentry", dn)
+		}
+		return sr.Entries[0], nil
+	})
+}
+
+// schemaDefinition is a parsed RFC 4512 definition.
+type schemaDefinition struct {
+	oid    string
+	fields map[string][]string
+}
+
+func (s schemaDefinition) name() string {
+	if names := s.fields["NAME"]; len(names) > 0 {
+		return names[0]
+	}
+	return s.oid
+}
+
+func (s schemaDefinition) field(keyword string) string {
+	if values := s.fields[keyword]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (s schemaDefinition) flag(keyword string) bool {
+	_, ok := s.fields[keyword]
+	return ok
+}
+
+// parseSchemaDefinitions parses the values of a subschema attribute (e.g.
+// objectClasses), sorted by name.
+func parseSchemaDefinitions(values []string) ([]schemaDefinition, error) {
+	definitions := make([]schemaDefinition, 0, len(values))
+	for _, value := range values {
+		oid, fields, err := util.ParseDefinition(value)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, schemaDefinition{oid: oid, fields: fields})
+	}
+	sort.SliceStable(definitions, func(i, j int) bool {
+		return strings.ToLower(definitions[i].name()) < strings.ToLower(definitions[j].name())
+	})
+	return definitions, nil
+}
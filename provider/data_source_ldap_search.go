@@ -0,0 +1,805 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the page size of searches using the simple paged results control, below
+// the 1000 entries Active Directory returns at most per page by default
+const ldapDefaultPageSize = 500
+
+// the search scopes, by argument value
+var ldapScopes = map[string]int{
+	"base": ldap.ScopeBaseObject,
+	"one":  ldap.ScopeSingleLevel,
+	"sub":  ldap.ScopeWholeSubtree,
+}
+
+// serverSideSortControl is the RFC 2891 Server-Side Sort request control.
+// Unlike go-ldap's, it is critical, so that servers unable to sort fail the
+// search rather than return unsorted entries, and it only sends ordering
+// rules that are set.
+type serverSideSortControl struct {
+	keys []*ldap.SortKey
+}
+
+// newServerSideSortControl returns the control sorting by the given keys,
+// see util.ParseSortKey.
+func newServerSideSortControl(keys []string) (*serverSideSortControl, error) {
+	control := &serverSideSortControl{}
+	for _, key := range keys {
+		attribute, orderingRule, reverse, err := util.ParseSortKey(key)
+		if err != nil {
+			return nil, err
+		}
+		control.keys = append(control.keys, &ldap.SortKey{AttributeType: attribute, MatchingRule: orderingRule, Reverse: reverse})
+	}
+	return control, nil
+}
+
+func (c *serverSideSortControl) GetControlType() string {
+	return ldap.ControlTypeServerSideSorting
+}
+
+func (c *serverSideSortControl) Encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, ldap.ControlTypeServerSideSorting, "Control Type (Server-Side Sort)"))
+	packet.AppendChild(ber.NewBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, true, "Criticality"))
+	value := ber.Encode(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nil, "Control Value (Server-Side Sort)")
+	keys := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "SortKeyList")
+	for _, key := range c.keys {
+		sequence := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "SortKey")
+		sequence.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, key.AttributeType, "attributeType"))
+		if key.MatchingRule != "" {
+			sequence.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, key.MatchingRule, "orderingRule"))
+		}
+		if key.Reverse {
+			sequence.AppendChild(ber.NewBoolean(ber.ClassContext, ber.TypePrimitive, 1, true, "reverseOrder"))
+		}
+		keys.AppendChild(sequence)
+	}
+	value.AppendChild(keys)
+	packet.AppendChild(value)
+	return packet
+}
+
+func (c *serverSideSortControl) String() string {
+	keys := make([]string, 0, len(c.keys))
+	for _, key := range c.keys {
+		keys = append(keys, fmt.Sprintf("%+v", *key))
+	}
+	return fmt.Sprintf("Control Type: Server-Side Sort (%q)  Criticality: true  Keys: %s", ldap.ControlTypeServerSideSorting, strings.Join(keys, ", "))
+}
+
+// the entry fields holding the attributes declared in attribute_types, by
+// type
+var ldapTypedAttributeFields = map[string]string{
+	"string":    "strings",
+	"int":       "integers",
+	"bool":      "booleans",
+	"list":      "lists",
+	"binary":    "binaries",
+	"timestamp": "timestamps",
+}
+
+// the Virtual List View request and response controls, see
+// draft-ietf-ldapext-ldapv3-vlv
+const (
+	vlvRequestOID  = "2.16.840.1.113730.3.4.9"
+	vlvResponseOID = "2.16.840.1.113730.3.4.10"
+)
+
+// vlvControl is the critical Virtual List View request control for the
+// window of count entries starting at the 1-based offset of the sorted
+// result set.
+type vlvControl struct {
+	offset, count int
+}
+
+func (c *vlvControl) GetControlType() string {
+	return vlvRequestOID
+}
+
+func (c *vlvControl) Encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, vlvRequestOID, "Control Type (Virtual List View)"))
+	packet.AppendChild(ber.NewBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, true, "Criticality"))
+	value := ber.Encode(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nil, "Control Value (Virtual List View)")
+	request := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "VirtualListViewRequest")
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(0), "beforeCount"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(c.count-1), "afterCount"))
+	byOffset := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "byOffset")
+	byOffset.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(c.offset), "offset"))
+	byOffset.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(0), "contentCount"))
+	request.AppendChild(byOffset)
+	value.AppendChild(request)
+	packet.AppendChild(value)
+	return packet
+}
+
+func (c *vlvControl) String() string {
+	return fmt.Sprintf("Control Type: Virtual List View (%q)  Criticality: true  Offset: %d  Count: %d", vlvRequestOID, c.offset, c.count)
+}
+
+// readVLVResponse returns the content count of the Virtual List View
+// response control among controls: the server's estimate of the size of the
+// whole result set.
+func readVLVResponse(controls []ldap.Control) (int, error) {
+	control, ok := ldap.FindControl(controls, vlvResponseOID).(*ldap.ControlString)
+	if !ok {
+		return 0, fmt.Errorf("the server returned no Virtual List View response")
+	}
+	response, err := ber.DecodePacketErr([]byte(control.ControlValue))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Virtual List View response: %v", err)
+	}
+	if len(response.Children) < 3 {
+		return 0, fmt.Errorf("invalid Virtual List View response: %d elements", len(response.Children))
+	}
+	contentCount, err := ber.ParseInt64(response.Children[1].Data.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("invalid Virtual List View content count: %v", err)
+	}
+	result, err := ber.ParseInt64(response.Children[2].Data.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("invalid Virtual List View result: %v", err)
+	}
+	if result != ldap.LDAPResultSuccess {
+		return 0, fmt.Errorf("virtual list view failed: %s", ldap.LDAPResultCodeMap[uint16(result)])
+	}
+	return int(contentCount), nil
+}
+
+func dataSourceLDAPSearch() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPSearchRead,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search from.",
+				Required:    true,
+			},
+			"scope": {
+				Type:         schema.TypeString,
+				Description:  "The scope of the search: base, one or sub.",
+				Optional:     true,
+				Default:      "sub",
+				ValidateFunc: validation.StringInSlice([]string{"base", "one", "sub"}, false),
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Description: "The filter of the search (e.g. (objectClass=inetOrgPerson)).",
+				Optional:    true,
+				Default:     "(objectClass=*)",
+			},
+			"attributes": {
+				Type:        schema.TypeList,
+				Description: "The attributes to return, the server only sending those; all user attributes when unset.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"size_limit": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of entries to return; no limit when unset, although the server may enforce one.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"attribute_types": {
+				Type:        schema.TypeMap,
+				Description: "Attributes to return typed, by name, with their type: string, int, bool, list, binary (base64) or timestamp (RFC 3339, from GeneralizedTime or AD FILETIME); they are also requested from the server, alone when attributes is unset.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"decode_binary_attributes": {
+				Type:        schema.TypeBool,
+				Description: "Whether to return the values of the well-known binary attributes of Active Directory in text form: GUIDs (objectGUID, schemaIDGUID, mS-DS-ConsistencyGuid, ...) and SIDs (objectSid, sIDHistory, tokenGroups, ...).",
+				Optional:    true,
+				Default:     false,
+			},
+			"json": {
+				Type:        schema.TypeBool,
+				Description: "Whether to also return the entries as JSON documents, in the json field of each entry and in results_json, for jsondecode or writing to files.",
+				Optional:    true,
+				Default:     false,
+			},
+			"results_json": {
+				Type:        schema.TypeString,
+				Description: "With json, a JSON array of the entries: {\"dn\": ..., \"attributes\": {name: [values]}}, typed values for attribute_types.",
+				Computed:    true,
+			},
+			"page_size": ldapPageSizeSchema(),
+			"sort_by": {
+				Type:        schema.TypeList,
+				Description: "Sort keys of the form [-]attribute[:orderingRule] (e.g. sn, -uidNumber) to have the server sort the entries with the Server-Side Sort control; the search fails when the server does not support it.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"vlv": {
+				Type:         schema.TypeList,
+				Description:  "A window of the sorted result set to retrieve with the Virtual List View control, instead of the whole result set; requires sort_by and disables paging.",
+				Optional:     true,
+				MaxItems:     1,
+				RequiredWith: []string{"sort_by"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"offset": {
+							Type:         schema.TypeInt,
+							Description:  "The 1-based position of the first entry of the window in the sorted result set.",
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"count": {
+							Type:         schema.TypeInt,
+							Description:  "The number of entries in the window.",
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+					},
+				},
+			},
+			"content_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of entries in the whole result set: estimated by the server with vlv, else the number of entries found.",
+				Computed:    true,
+			},
+			"entries": {
+				Type:        schema.TypeList,
+				Description: "The entries found, in the order the server returned them.",
+				Computed:    true,
+				Elem:        ldapTypedEntrySchema(),
+			},
+		},
+	}
+}
+
+// ldapPageSizeSchema returns the schema of the page_size argument of the
+// search data sources.
+func ldapPageSizeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Description:  "The number of entries to fetch per page with the simple paged results control; 0 disables paging, leaving large searches subject to the server size limit.",
+		Optional:     true,
+		Default:      ldapDefaultPageSize,
+		ValidateFunc: validation.IntAtLeast(0),
+	}
+}
+
+// ldapEntrySchema returns the schema of an entry returned by the search data
+// sources.
+func ldapEntrySchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the entry.",
+				Computed:    true,
+			},
+			"attributes": {
+				Type:        schema.TypeMap,
+				Description: "The first value of each attribute of the entry, for convenient access to single-valued attributes.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"attribute_values": {
+				Type:        schema.TypeList,
+				Description: "All the values of each attribute of the entry.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the attribute, as returned by the server.",
+							Computed:    true,
+						},
+						"values": {
+							Type:        schema.TypeList,
+							Description: "The values of the attribute.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPSearchRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+	scope := d.Get("scope").(string)
+	filter := d.Get("filter").(string)
+
+	attributes := toStringSlice(d.Get("attributes").([]interface{}))
+	types := map[string]string{}
+	for name, t := range d.Get("attribute_types").(map[string]interface{}) {
+		if _, ok := ldapTypedAttributeFields[t.(string)]; !ok {
+			return fmt.Errorf("invalid type %q of attribute %s, expected string, int, bool, list, binary or timestamp", t, name)
+		}
+		types[name] = t.(string)
+		if !stringSliceContains(attributes, name) {
+			attributes = append(attributes, name)
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_search::read - searching %q (scope %s) for %s", baseDN, scope, filter)
+
+	request := ldap.NewSearchRequest(
+		baseDN,
+		ldapScopes[scope],
+		ldap.NeverDerefAliases,
+		d.Get("size_limit").(int),
+		0,
+		false,
+		filter,
+		attributes,
+		nil,
+	)
+	if sortBy := toStringSlice(d.Get("sort_by").([]interface{})); len(sortBy) > 0 {
+		control, err := newServerSideSortControl(sortBy)
+		if err != nil {
+			return err
+		}
+		request.Controls = append(request.Controls, control)
+	}
+	pageSize := d.Get("page_size").(int)
+	window, vlv := d.GetOk("vlv.0")
+	if vlv {
+		// servers refuse Virtual List View combined with paging
+		settings := window.(map[string]interface{})
+		request.Controls = append(request.Controls, &vlvControl{offset: settings["offset"].(int), count: settings["count"].(int)})
+		pageSize = 0
+	}
+
+	// the entries are converted as they arrive, so that only their flattened
+	// form is held in memory rather than the whole result set
+	entries := []interface{}{}
+	documents := []interface{}{}
+	convert := func(i int, entry *ldap.Entry) error {
+		if d.Get("decode_binary_attributes").(bool) {
+			decodeADBinaryAttributes(entry)
+		}
+		flattened := flattenLDAPEntry(entry)
+		typed, err := flattenLDAPTypedAttributes(entry, types)
+		if err != nil {
+			return err
+		}
+		for field, values := range typed {
+			flattened[field] = values
+		}
+		if d.Get("json").(bool) {
+			document, err := ldapEntryDocument(entry, types)
+			if err != nil {
+				return err
+			}
+			encoded, err := json.Marshal(document)
+			if err != nil {
+				return fmt.Errorf("error encoding %q: %v", entry.DN, err)
+			}
+			flattened["json"] = string(encoded)
+			documents[i] = document
+		}
+		entries[i] = flattened
+		return nil
+	}
+	// the remaining ranges of ranged attributes are read once the search is
+	// over, as it holds its connection until then
+	ranged := map[int]*ldap.Entry{}
+	restart := func() {
+		entries, documents, ranged = []interface{}{}, []interface{}{}, map[int]*ldap.Entry{}
+	}
+	controls, err := streamLDAPSearch(client, request, pageSize, restart, func(entry *ldap.Entry) error {
+		i := len(entries)
+		entries = append(entries, nil)
+		documents = append(documents, nil)
+		if hasRangedAttributes(entry) {
+			ranged[i] = entry
+			return nil
+		}
+		return convert(i, entry)
+	})
+	if err != nil {
+		log.Printf("[ERROR] ldap_search::read - error searching %q: %v", baseDN, err)
+		return err
+	}
+	for i, entry := range ranged {
+		if err := readRangedAttributes(client, entry); err != nil {
+			return err
+		}
+		if err := convert(i, entry); err != nil {
+			return err
+		}
+	}
+	contentCount := len(entries)
+	if vlv {
+		if contentCount, err = readVLVResponse(controls); err != nil {
+			log.Printf("[ERROR] ldap_search::read - error reading the window of %q: %v", baseDN, err)
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_search::read - found %d entries under %q", len(entries), baseDN)
+
+	resultsJSON := ""
+	if d.Get("json").(bool) {
+		encoded, err := json.Marshal(documents)
+		if err != nil {
+			return fmt.Errorf("error encoding the entries: %v", err)
+		}
+		resultsJSON = string(encoded)
+	}
+
+	d.SetId(fmt.Sprintf("%s?%s?%s", baseDN, scope, filter))
+	d.Set("results_json", resultsJSON)
+	d.Set("content_count", contentCount)
+	return d.Set("entries", entries)
+}
+
+// ldapTypedEntrySchema returns ldapEntrySchema with the fields holding the
+// attributes declared in attribute_types.
+func ldapTypedEntrySchema() *schema.Resource {
+	entry := ldapEntrySchema()
+	typed := func(t schema.ValueType, description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeMap,
+			Description: description,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: t},
+		}
+	}
+	entry.Schema["strings"] = typed(schema.TypeString, "The first value of each string attribute.")
+	entry.Schema["integers"] = typed(schema.TypeInt, "The first value of each int attribute.")
+	entry.Schema["booleans"] = typed(schema.TypeBool, "The first value of each bool attribute.")
+	entry.Schema["binaries"] = typed(schema.TypeString, "The first value of each binary attribute, base64 encoded.")
+	entry.Schema["timestamps"] = typed(schema.TypeString, "The first value of each timestamp attribute in RFC 3339 format; empty for the AD values meaning never.")
+	entry.Schema["json"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "With json, the entry as a JSON document.",
+		Computed:    true,
+	}
+	entry.Schema["lists"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "All the values of each list attribute.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Description: "The name of the attribute, as declared in attribute_types.",
+					Computed:    true,
+				},
+				"values": {
+					Type:        schema.TypeList,
+					Description: "The values of the attribute.",
+					Computed:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+	return entry
+}
+
+// flattenLDAPTypedAttributes converts the attributes of entry declared in
+// types into the typed fields of ldapTypedEntrySchema, failing on values
+// that do not match their type.
+func flattenLDAPTypedAttributes(entry *ldap.Entry, types map[string]string) (map[string]interface{}, error) {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	typed := map[string]interface{}{}
+	for _, field := range ldapTypedAttributeFields {
+		typed[field] = map[string]interface{}{}
+	}
+	lists := make([]interface{}, 0)
+	for _, name := range names {
+		values := entry.GetEqualFoldAttributeValues(name)
+		if len(values) == 0 {
+			continue
+		}
+		value, err := typedLDAPAttributeValue(entry, name, types[name])
+		if err != nil {
+			return nil, err
+		}
+		if types[name] == "list" {
+			lists = append(lists, map[string]interface{}{
+				"name":   name,
+				"values": value,
+			})
+			continue
+		}
+		typed[ldapTypedAttributeFields[types[name]]].(map[string]interface{})[name] = value
+	}
+	typed["lists"] = lists
+	return typed, nil
+}
+
+// typedLDAPAttributeValue returns the first value of the attribute name of
+// entry converted to type t, or all of them for lists.
+func typedLDAPAttributeValue(entry *ldap.Entry, name, t string) (interface{}, error) {
+	values := entry.GetEqualFoldAttributeValues(name)
+	switch t {
+	case "int":
+		n, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s of %q is not an integer: %q", name, entry.DN, values[0])
+		}
+		return int(n), nil
+	case "bool":
+		switch strings.ToUpper(values[0]) {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		}
+		return nil, fmt.Errorf("attribute %s of %q is not a boolean: %q", name, entry.DN, values[0])
+	case "list":
+		return values, nil
+	case "binary":
+		return base64.StdEncoding.EncodeToString(entry.GetEqualFoldRawAttributeValue(name)), nil
+	case "timestamp":
+		parsed, err := util.ParseTimestamp(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s of %q: %v", name, entry.DN, err)
+		}
+		if parsed.IsZero() {
+			return "", nil
+		}
+		return parsed.Format(time.RFC3339), nil
+	}
+	return values[0], nil
+}
+
+// ldapEntryDocument returns entry as a JSON document: its DN and all the
+// values of each attribute, or the typed value of those declared in types.
+func ldapEntryDocument(entry *ldap.Entry, types map[string]string) (map[string]interface{}, error) {
+	// servers return attribute names in their own case
+	lowerTypes := map[string]string{}
+	for name, t := range types {
+		lowerTypes[strings.ToLower(name)] = t
+	}
+	attributes := map[string]interface{}{}
+	for _, attribute := range entry.Attributes {
+		if len(attribute.Values) == 0 {
+			continue
+		}
+		t, ok := lowerTypes[strings.ToLower(attribute.Name)]
+		if !ok {
+			attributes[attribute.Name] = attribute.Values
+			continue
+		}
+		value, err := typedLDAPAttributeValue(entry, attribute.Name, t)
+		if err != nil {
+			return nil, err
+		}
+		attributes[attribute.Name] = value
+	}
+	return map[string]interface{}{
+		"dn":         entry.DN,
+		"attributes": attributes,
+	}, nil
+}
+
+// flattenLDAPEntry converts an entry into the map of ldapEntrySchema, with
+// the attributes sorted by name for a stable output.
+func flattenLDAPEntry(entry *ldap.Entry) map[string]interface{} {
+	attributes := make([]*ldap.EntryAttribute, len(entry.Attributes))
+	copy(attributes, entry.Attributes)
+	sort.SliceStable(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+
+	first := map[string]interface{}{}
+	values := make([]interface{}, 0, len(attributes))
+	for _, attribute := range attributes {
+		if len(attribute.Values) == 0 {
+			continue
+		}
+		first[attribute.Name] = attribute.Values[0]
+		values = append(values, map[string]interface{}{
+			"name":   attribute.Name,
+			"values": attribute.Values,
+		})
+	}
+	return map[string]interface{}{
+		"dn":               entry.DN,
+		"attributes":       first,
+		"attribute_values": values,
+	}
+}
+
+// searchLDAPPaged runs request, fetching the results page by page with the
+// simple paged results control unless pageSize is 0, so that the whole
+// result set is returned rather than the first server-sized chunk of it.
+// Unpaged searches stopped by the size limit of the server are retried with
+// paging, and size limit errors are explained, see ldapSizeLimitError.
+func searchLDAPPaged(client ldap.Client, request *ldap.SearchRequest, pageSize int) (*ldap.SearchResult, error) {
+	var sr *ldap.SearchResult
+	var err error
+	if pageSize == 0 {
+		sr, err = client.Search(request)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) && request.SizeLimit == 0 {
+			log.Printf("[WARN] the search of %q hit the size limit of the server, retrying with paged results", request.BaseDN)
+			pageSize = ldapDefaultPageSize
+			sr, err = client.SearchWithPaging(request, uint32(pageSize))
+		}
+	} else {
+		sr, err = client.SearchWithPaging(request, uint32(pageSize))
+	}
+	if err != nil {
+		count := 0
+		if sr != nil {
+			count = len(sr.Entries)
+		}
+		if err := ldapSizeLimitError(err, request, pageSize, count); err != nil {
+			return nil, err
+		}
+	}
+	return sr, nil
+}
+
+// ldapSizeLimitError tells which limit stopped a search that failed with
+// sizeLimitExceeded after returning count entries, and how to lift it. It is
+// nil when the search stopped at its own size limit, the entries returned so
+// far being the result asked for; other errors are returned as they are.
+func ldapSizeLimitError(err error, request *ldap.SearchRequest, pageSize, count int) error {
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return err
+	}
+	switch {
+	case request.SizeLimit > 0 && count >= request.SizeLimit:
+		log.Printf("[WARN] the search of %q for %s matched more than the %d entries it was limited to", request.BaseDN, request.Filter, request.SizeLimit)
+		return nil
+	case pageSize > 0:
+		return fmt.Errorf("the server stopped the paged search of %q for %s after %d entries, at the size limit it enforces for the bind user; "+
+			"raise that limit (e.g. size.prtotal in the olcLimits of OpenLDAP, MaxResultSetSize in the LDAP policies of Active Directory) or narrow the search: %v",
+			request.BaseDN, request.Filter, count, err)
+	default:
+		return fmt.Errorf("the server stopped the search of %q for %s after %d entries, at its size limit; "+
+			"page the search (page_size, unless the vlv window is used), raise the limit of the bind user or narrow the search: %v",
+			request.BaseDN, request.Filter, count, err)
+	}
+}
+
+// streamLDAPSearch runs request like searchLDAPPaged, but passes each entry
+// to f as it is received instead of buffering the whole result set, and
+// returns the controls of the last result done. The search is abandoned when
+// f fails. Through the pool, it runs like its other operations, retried on
+// a new connection when the server closes its own and with access errors
+// explained; restart is called before it is run again, for the entries of
+// the failed attempt to be dropped.
+func streamLDAPSearch(client ldap.Client, request *ldap.SearchRequest, pageSize int, restart func(), f func(entry *ldap.Entry) error) ([]ldap.Control, error) {
+	var controls []ldap.Control
+	count, attempts := 0, 0
+	search := func(searchAsync func(context.Context, *ldap.SearchRequest, int) ldap.Response) error {
+		if attempts++; attempts > 1 {
+			restart()
+		}
+		var err error
+		controls, count, err = streamLDAPPages(searchAsync, request, pageSize, f)
+		return err
+	}
+	var err error
+	if pool, ok := client.(*ldapPool); ok {
+		// all the pages are read on the same connection, paging cookies
+		// being meaningless to the others
+		err = pool.reconnecting(pool.with, func(conn *ldap.Conn, retried bool) error {
+			return pool.explainAccessError(conn, search(conn.SearchAsync), "search", request.BaseDN, request.Attributes)
+		})
+	} else {
+		err = search(client.SearchAsync)
+	}
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) && pageSize == 0 && request.SizeLimit == 0 && ldap.FindControl(request.Controls, vlvRequestOID) == nil {
+		log.Printf("[WARN] the search of %q hit the size limit of the server, retrying with paged results", request.BaseDN)
+		restart()
+		return streamLDAPSearch(client, request, ldapDefaultPageSize, restart, f)
+	}
+	if err != nil {
+		return controls, ldapSizeLimitError(err, request, pageSize, count)
+	}
+	return controls, nil
+}
+
+// streamLDAPPages runs the search of streamLDAPSearch once with searchAsync,
+// from its first page, returning the number of entries passed to f.
+func streamLDAPPages(searchAsync func(context.Context, *ldap.SearchRequest, int) ldap.Response, request *ldap.SearchRequest, pageSize int, f func(entry *ldap.Entry) error) ([]ldap.Control, int, error) {
+	paging, _ := ldap.FindControl(request.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+	if paging != nil {
+		paging.SetCookie(nil)
+	} else if pageSize > 0 {
+		paging = ldap.NewControlPaging(uint32(pageSize))
+		request.Controls = append(request.Controls, paging)
+	}
+	count := 0
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		response := searchAsync(ctx, request, 0)
+		var controls []ldap.Control
+		var err error
+		for err == nil && response.Next() {
+			if entry := response.Entry(); entry != nil {
+				count++
+				err = f(entry)
+			} else if len(response.Controls()) > 0 {
+				controls = response.Controls()
+			}
+		}
+		cancel()
+		if err != nil {
+			// read the rest of the page, for the response to release its
+			// connection
+			for response.Next() {
+			}
+			return nil, count, err
+		}
+		if err := response.Err(); err != nil {
+			return controls, count, err
+		}
+		if paging == nil {
+			return controls, count, nil
+		}
+		next, ok := ldap.FindControl(controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		if !ok || len(next.Cookie) == 0 {
+			return controls, count, nil
+		}
+		paging.SetCookie(next.Cookie)
+	}
+}
+
+// searchLDAPEntry returns the single entry under baseDN matching filter,
+// failing when there is none or more than one, as lookups by name must be
+// unambiguous.
+func searchLDAPEntry(client ldap.Client, baseDN, filter string, attributes ...string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		0,
+		false,
+		filter,
+		attributes,
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+	switch {
+	case sr == nil || len(sr.Entries) == 0:
+		return nil, fmt.Errorf("no entry matching %s under %q", filter, baseDN)
+	case len(sr.Entries) > 1:
+		return nil, fmt.Errorf("more than one entry matching %s under %q", filter, baseDN)
+	}
+	if err := readRangedAttributes(client, sr.Entries[0]); err != nil {
+		return nil, err
+	}
+	return sr.Entries[0], nil
+}
+
+// sortLDAPEntries sorts entries by DN, so that the plural data sources do not
+// change when the server returns them in another order.
+func sortLDAPEntries(entries []*ldap.Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].DN) < strings.ToLower(entries[j].DN)
+	})
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceLDAPSRVServers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPSRVServersRead,
+
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:        schema.TypeString,
+				Description: "The DNS domain to discover the servers of (e.g. example.com).",
+				Required:    true,
+			},
+			"service": {
+				Type:         schema.TypeString,
+				Description:  "The service of the SRV records: ldap, ldaps or gc (Active Directory global catalogs).",
+				Optional:     true,
+				Default:      "ldap",
+				ValidateFunc: validation.StringInSlice([]string{"ldap", "ldaps", "gc"}, false),
+			},
+			"site": {
+				Type:        schema.TypeString,
+				Description: "The Active Directory site to restrict the lookup to, using _<service>._tcp.<site>._sites.<domain>.",
+				Optional:    true,
+			},
+			"records": {
+				Type:        schema.TypeList,
+				Description: "The SRV records found, by priority, then weight (highest first), then target.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"target": {
+							Type:        schema.TypeString,
+							Description: "The host name of the server.",
+							Computed:    true,
+						},
+						"port": {
+							Type:        schema.TypeInt,
+							Description: "The port of the server.",
+							Computed:    true,
+						},
+						"priority": {
+							Type:        schema.TypeInt,
+							Description: "The priority of the record; lower is preferred.",
+							Computed:    true,
+						},
+						"weight": {
+							Type:        schema.TypeInt,
+							Description: "The weight of the record among those of the same priority.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"servers": {
+				Type:        schema.TypeList,
+				Description: "The host:port of the servers, in the order of records.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"urls": {
+				Type:        schema.TypeList,
+				Description: "The URLs of the servers (ldap:// or ldaps://), in the order of records, for the url of a provider block.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPSRVServersRead(d *schema.ResourceData, meta interface{}) error {
+	domain := strings.TrimSuffix(d.Get("domain").(string), ".")
+	service := d.Get("service").(string)
+	name := domain
+	if site := d.Get("site").(string); site != "" {
+		name = fmt.Sprintf("%s._sites.%s", site, domain)
+	}
+
+	log.Printf("[DEBUG] ldap_srv_servers::read - looking up _%s._tcp.%s", service, name)
+
+	_, addrs, err := net.LookupSRV(service, "tcp", name)
+	if err != nil {
+		log.Printf("[ERROR] ldap_srv_servers::read - error looking up _%s._tcp.%s: %v", service, name, err)
+		return err
+	}
+	// the resolver shuffles records of equal priority by weight, which
+	// would change the output on every read
+	sort.SliceStable(addrs, func(i, j int) bool {
+		if addrs[i].Priority != addrs[j].Priority {
+			return addrs[i].Priority < addrs[j].Priority
+		}
+		if addrs[i].Weight != addrs[j].Weight {
+			return addrs[i].Weight > addrs[j].Weight
+		}
+		return addrs[i].Target < addrs[j].Target
+	})
+
+	scheme := "ldap"
+	if service == "ldaps" {
+		scheme = "ldaps"
+	}
+	records := make([]interface{}, 0, len(addrs))
+	servers := make([]string, 0, len(addrs))
+	urls := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		target := strings.TrimSuffix(addr.Target, ".")
+		server := net.JoinHostPort(target, fmt.Sprint(addr.Port))
+		records = append(records, map[string]interface{}{
+			"target":   target,
+			"port":     int(addr.Port),
+			"priority": int(addr.Priority),
+			"weight":   int(addr.Weight),
+		})
+		servers = append(servers, server)
+		urls = append(urls, fmt.Sprintf("%s://%s", scheme, server))
+	}
+
+	log.Printf("[DEBUG] ldap_srv_servers::read - found %d servers for %s", len(servers), name)
+
+	d.SetId(fmt.Sprintf("_%s._tcp.%s", service, name))
+	d.Set("records", records)
+	d.Set("servers", servers)
+	return d.Set("urls", urls)
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the friendly names of the controls, extended operations and features
+// advertised by common servers, by OID
+var ldapOIDNames = map[string]string{
+	// controls
+	"1.2.840.113556.1.4.319":      "paged_results",
+	"1.2.840.113556.1.4.473":      "server_side_sort",
+	"2.16.840.1.113730.3.4.9":     "virtual_list_view",
+	"1.2.840.113556.1.4.805":      "tree_delete",
+	"1.2.840.113556.1.4.417":      "show_deleted",
+	"1.2.840.113556.1.4.2064":     "show_recycled",
+	"1.2.840.113556.1.4.529":      "extended_dn",
+	"1.2.840.113556.1.4.801":      "sd_flags",
+	"1.2.840.113556.1.4.1339":     "domain_scope",
+	"1.2.840.113556.1.4.1340":     "search_options",
+	"1.2.840.113556.1.4.1413":     "permissive_modify",
+	"1.2.840.113556.1.4.1504":     "attribute_scoped_query",
+	"1.2.840.113556.1.4.841":      "dirsync",
+	"1.2.840.113556.1.4.528":      "notification",
+	"1.2.840.113556.1.4.2239":     "policy_hints",
+	"2.16.840.1.113730.3.4.2":     "manage_dsa_it",
+	"2.16.840.1.113730.3.4.3":     "persistent_search",
+	"2.16.840.1.113730.3.4.18":    "proxied_authorization_v2",
+	"2.16.840.1.113730.3.4.12":    "proxied_authorization_v1",
+	"1.3.6.1.1.12":                "assertion",
+	"1.3.6.1.1.13.1":              "pre_read",
+	"1.3.6.1.1.13.2":              "post_read",
+	"1.3.6.1.1.21.2":              "transaction_specification",
+	"1.3.6.1.1.22":                "dont_use_copy",
+	"1.3.6.1.4.1.42.2.27.8.5.1":   "password_policy",
+	"1.3.6.1.4.1.4203.1.9.1.1":    "sync_request",
+	"1.3.6.1.4.1.4203.1.10.1":     "subentries",
+	"1.3.6.1.4.1.4203.1.10.2":     "no_op",
+	"1.3.6.1.4.1.4203.666.5.12":   "relax_rules",
+	"1.3.6.1.4.1.1466.29539.12":   "chaining_behavior",
+	"2.16.840.1.113730.3.4.15":    "authorization_identity",
+	"2.16.840.1.113730.3.4.16":    "authorization_identity_request",
+	"1.3.6.1.4.1.4203.666.5.15":   "dereference",
+	"1.3.6.1.4.1.4203.666.11.6.2": "session_tracking",
+	// extended operations
+	"1.3.6.1.4.1.1466.20037":     "start_tls",
+	"1.3.6.1.4.1.4203.1.11.1":    "password_modify",
+	"1.3.6.1.4.1.4203.1.11.3":    "who_am_i",
+	"1.3.6.1.1.8":                "cancel",
+	"1.3.6.1.1.21.1":             "start_transaction",
+	"1.3.6.1.1.21.3":             "end_transaction",
+	"1.3.6.1.4.1.1466.101.119.1": "refresh",
+	"1.2.840.113556.1.4.1781":    "fast_concurrent_bind",
+	"1.2.840.113556.1.4.2212":    "batch_request",
+	"2.16.840.1.113730.3.5.7":    "replication_start",
+	// features
+	"1.3.6.1.1.14":              "modify_increment",
+	"1.3.6.1.4.1.4203.1.5.1":    "all_operational_attributes",
+	"1.3.6.1.4.1.4203.1.5.2":    "object_class_attributes",
+	"1.3.6.1.4.1.4203.1.5.3":    "absolute_filters",
+	"1.3.6.1.4.1.4203.1.5.4":    "language_tags",
+	"1.3.6.1.4.1.4203.1.5.5":    "language_ranges",
+	"1.3.6.1.4.1.4203.666.5.13": "relay",
+	"1.2.840.113556.1.4.800":    "active_directory",
+	"1.2.840.113556.1.4.1670":   "active_directory_v51",
+	"1.2.840.113556.1.4.1791":   "active_directory_ldap_integration",
+	"1.2.840.113556.1.4.1935":   "active_directory_v60",
+	"1.2.840.113556.1.4.2080":   "active_directory_v61_r2",
+	"1.2.840.113556.1.4.2237":   "active_directory_w8",
+}
+
+func dataSourceLDAPSupportedFeatures() *schema.Resource {
+	oids := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeList,
+			Description: description,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"oid": {
+						Type:        schema.TypeString,
+						Description: "The OID.",
+						Computed:    true,
+					},
+					"name": {
+						Type:        schema.TypeString,
+						Description: "The friendly name of the OID (e.g. paged_results), or the OID itself when unknown.",
+						Computed:    true,
+					},
+				},
+			},
+		}
+	}
+	names := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: description,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPSupportedFeaturesRead,
+
+		Schema: map[string]*schema.Schema{
+			"controls":        oids("The controls the server supports (supportedControl)."),
+			"extensions":      oids("The extended operations the server supports (supportedExtension)."),
+			"features":        oids("The features the server supports (supportedFeatures, and supportedCapabilities on Active Directory)."),
+			"control_names":   names("The friendly names of the supported controls, for contains() checks."),
+			"extension_names": names("The friendly names of the supported extended operations."),
+			"feature_names":   names("The friendly names of the supported features."),
+			"sasl_mechanisms": names("The SASL mechanisms the server supports."),
+			"ldap_versions":   names("The LDAP versions the server supports."),
+			"naming_contexts": names("The naming contexts (suffixes) the server holds."),
+			"vendor_name": {
+				Type:        schema.TypeString,
+				Description: "The vendor of the server, when advertised.",
+				Computed:    true,
+			},
+			"vendor_version": {
+				Type:        schema.TypeString,
+				Description: "The version of the server, when advertised.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPSupportedFeaturesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_supported_features::read - reading the root DSE")
+
+	rootDSE, err := readRootDSE(client,
+		"supportedControl", "supportedExtension", "supportedFeatures", "supportedCapabilities",
+		"supportedSASLMechanisms", "supportedLDAPVersion", "namingContexts", "vendorName", "vendorVersion")
+	if err != nil {
+		log.Printf("[ERROR] ldap_supported_features::read - error reading the root DSE: %v", err)
+		return err
+	}
+	if rootDSE == nil {
+		rootDSE = &ldap.Entry{}
+	}
+
+	flatten := func(oids []string) ([]interface{}, []string) {
+		list := make([]interface{}, 0, len(oids))
+		names := make([]string, 0, len(oids))
+		for _, oid := range oids {
+			name, ok := ldapOIDNames[oid]
+			if !ok {
+				name = oid
+			}
+			list = append(list, map[string]interface{}{"oid": oid, "name": name})
+			names = append(names, name)
+		}
+		return list, names
+	}
+	controls, controlNames := flatten(rootDSE.GetEqualFoldAttributeValues("supportedControl"))
+	extensions, extensionNames := flatten(rootDSE.GetEqualFoldAttributeValues("supportedExtension"))
+	features, featureNames := flatten(append(rootDSE.GetEqualFoldAttributeValues("supportedFeatures"), rootDSE.GetEqualFoldAttributeValues("supportedCapabilities")...))
+
+	d.SetId("rootDSE")
+	d.Set("controls", controls)
+	d.Set("extensions", extensions)
+	d.Set("features", features)
+	d.Set("control_names", controlNames)
+	d.Set("extension_names", extensionNames)
+	d.Set("feature_names", featureNames)
+	d.Set("sasl_mechanisms", rootDSE.GetEqualFoldAttributeValues("supportedSASLMechanisms"))
+	d.Set("ldap_versions", rootDSE.GetEqualFoldAttributeValues("supportedLDAPVersion"))
+	d.Set("naming_contexts", rootDSE.GetEqualFoldAttributeValues("namingContexts"))
+	d.Set("vendor_name", rootDSE.GetEqualFoldAttributeValue("vendorName"))
+	d.Set("vendor_version", rootDSE.GetEqualFoldAttributeValue("vendorVersion"))
+	return nil
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPTimestamp() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPTimestampRead,
+
+		Schema: map[string]*schema.Schema{
+			"rfc3339": {
+				Type:         schema.TypeString,
+				Description:  "The time in RFC 3339 format (e.g. 2025-01-01T00:00:00Z).",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"rfc3339", "generalized_time", "filetime"},
+			},
+			"generalized_time": {
+				Type:        schema.TypeString,
+				Description: "The time as an LDAP GeneralizedTime in UTC (e.g. 20250101000000Z).",
+				Optional:    true,
+				Computed:    true,
+			},
+			"filetime": {
+				Type:        schema.TypeString,
+				Description: "The time as an Active Directory FILETIME, the number of 100-nanosecond ticks since 1601 (e.g. the value of accountExpires, pwdLastSet or lastLogonTimestamp); 0 and 9223372036854775807 mean never.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"unix": {
+				Type:        schema.TypeInt,
+				Description: "The time in seconds since the Unix epoch; 0 when never.",
+				Computed:    true,
+			},
+			"never": {
+				Type:        schema.TypeBool,
+				Description: "Whether filetime is one of the values meaning never: an account that never expires (accountExpires), never logged on (lastLogonTimestamp) or whose password must change at next logon (pwdLastSet). The other outputs are then empty.",
+				Computed:    true,
+			},
+			"days_since": {
+				Type:        schema.TypeInt,
+				Description: "The number of whole days elapsed since the time, e.g. the age of a password or since the last logon; negative for future times and 0 when never.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceLDAPTimestampRead(d *schema.ResourceData, meta interface{}) error {
+	var t time.Time
+	var err error
+	if v, ok := d.GetOk("rfc3339"); ok {
+		if t, err = time.Parse(time.RFC3339Nano, v.(string)); err != nil {
+			return fmt.Errorf("invalid RFC 3339 time %q: %v", v, err)
+		}
+	} else if v, ok := d.GetOk("generalized_time"); ok {
+		if t, err = time.Parse("20060102150405Z0700", v.(string)); err != nil {
+			return fmt.Errorf("invalid GeneralizedTime %q: %v", v, err)
+		}
+	} else {
+		value := d.Get("filetime").(string)
+		if _, err = strconv.ParseUint(value, 10, 63); err != nil {
+			return fmt.Errorf("invalid FILETIME %q: %v", value, err)
+		}
+		if t, err = util.ParseTimestamp(value); err != nil {
+			return err
+		}
+		if t.IsZero() {
+			d.SetId(value)
+			d.Set("never", true)
+			d.Set("rfc3339", "")
+			d.Set("generalized_time", "")
+			d.Set("unix", 0)
+			return d.Set("days_since", 0)
+		}
+	}
+	t = t.UTC()
+
+	d.SetId(t.Format(time.RFC3339Nano))
+	d.Set("rfc3339", t.Format(time.RFC3339Nano))
+	d.Set("generalized_time", util.FormatGeneralizedTime(t))
+	d.Set("filetime", strconv.FormatInt(util.FileTime(t), 10))
+	d.Set("unix", int(t.Unix()))
+	d.Set("never", false)
+	return d.Set("days_since", int((time.Now().Unix()-t.Unix())/(24*60*60)))
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the single-valued user attributes exposed by the user data sources, by
+// argument name
+var ldapUserAttributes = map[string]string{
+	"uid":                 "uid",
+	"sam_account_name":    "sAMAccountName",
+	"user_principal_name": "userPrincipalName",
+	"mail":                "mail",
+	"cn":                  "cn",
+	"given_name":          "givenName",
+	"surname":             "sn",
+	"display_name":        "displayName",
+	"uid_number":          "uidNumber",
+	"gid_number":          "gidNumber",
+	"home_directory":      "homeDirectory",
+	"login_shell":         "loginShell",
+}
+
+// the arguments a user can be looked up by
+var ldapUserLookupKeys = []string{"uid", "mail", "sam_account_name"}
+
+func dataSourceLDAPUser() *schema.Resource {
+	s := ldapUserSchema()
+	s["base_dn"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The DN to look the user up under.",
+		Required:    true,
+	}
+	for _, key := range ldapUserLookupKeys {
+		s[key].Optional = true
+		s[key].ExactlyOneOf = ldapUserLookupKeys
+	}
+	s["uid"].Description = "The login of the user to look up (uid)."
+	s["mail"].Description = "The e-mail address of the user to look up."
+	s["sam_account_name"].Description = "The Active Directory logon name of the user to look up (sAMAccountName)."
+
+	return &schema.Resource{
+		Read: dataSourceLDAPUserRead,
+
+		Schema: s,
+	}
+}
+
+// ldapUserSchema returns the computed attributes of a user, as exposed by the
+// user data sources.
+func ldapUserSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"dn": {
+			Type:        schema.TypeString,
+			Description: "The DN of the user.",
+			Computed:    true,
+		},
+		"object_classes": {
+			Type:        schema.TypeSet,
+			Description: "The object classes of the user.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+		},
+		"member_of": {
+			Type:        schema.TypeSet,
+			Description: "The DNs of the groups the user is a direct member of, on servers maintaining memberOf.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+		},
+	}
+	for key, attribute := range ldapUserAttributes {
+		s[key] = &schema.Schema{
+			Type:        schema.TypeString,
+			Description: fmt.Sprintf("The %s of the user.", attribute),
+			Computed:    true,
+		}
+	}
+	return s
+}
+
+func dataSourceLDAPUserRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+
+	var filter string
+	for _, key := range ldapUserLookupKeys {
+		if v, ok := d.GetOk(key); ok {
+			filter = fmt.Sprintf("(&(objectClass=person)(%s=%s))", ldapUserAttributes[key], ldap.EscapeFilter(v.(string)))
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_user::read - looking up %s under %q", filter, baseDN)
+
+	entry, err := searchLDAPEntry(client, baseDN, filter, ldapUserAttributeNames()...)
+	if err != nil {
+		log.Printf("[ERROR] ldap_user::read - error looking up user: %v", err)
+		return err
+	}
+
+	d.SetId(entry.DN)
+	for key, value := range flattenLDAPUser(entry) {
+		d.Set(key, value)
+	}
+	return nil
+}
+
+// ldapUserAttributeNames returns the attributes to request for the user data
+// sources.
+func ldapUserAttributeNames(extra ...string) []string {
+	names := append([]string{"objectClass", "memberOf"}, extra...)
+	for _, attribute := range ldapUserAttributes {
+		names = append(names, attribute)
+	}
+	return names
+}
+
+// flattenLDAPUser converts a user entry into the map of ldapUserSchema.
+func flattenLDAPUser(entry *ldap.Entry) map[string]interface{} {
+	user := map[string]interface{}{
+		"dn":             entry.DN,
+		"object_classes": entry.GetEqualFoldAttributeValues("objectClass"),
+		"member_of":      entry.GetEqualFoldAttributeValues("memberOf"),
+	}
+	for key, attribute := range ldapUserAttributes {
+		user[key] = entry.GetEqualFoldAttributeValue(attribute)
+	}
+	return user
+}
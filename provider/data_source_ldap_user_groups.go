@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceLDAPUserGroups() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLDAPUserGroupsRead,
+
+		Schema: map[string]*schema.Schema{
+			"user_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the user.",
+				Required:    true,
+			},
+			"method": {
+				Type:         schema.TypeString,
+				Description:  "How groups are found: memberof (the memberOf attribute of the user), search (groups whose member, uniqueMember or memberUid holds the user) or auto (memberof when the user has memberOf values, else search).",
+				Optional:     true,
+				Default:      "auto",
+				ValidateFunc: validation.StringInSlice([]string{"auto", "memberof", "search"}, false),
+			},
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search for groups under; defaults to the naming context holding the user.",
+				Optional:    true,
+			},
+			"resolved_method": {
+				Type:        schema.TypeString,
+				Description: "The method actually used: memberof or search.",
+				Computed:    true,
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the groups the user is directly a member of, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPUserGroupsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	userDN := d.Get("user_dn").(string)
+	method := d.Get("method").(string)
+
+	user, err := readConfigEntry(client, userDN, "memberOf", "uid")
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %q does not exist", userDN)
+	}
+
+	groups := user.GetEqualFoldAttributeValues("memberOf")
+	if method == "search" || method == "auto" && len(groups) == 0 {
+		method = "search"
+		baseDN := d.Get("base_dn").(string)
+		if baseDN == "" {
+			if baseDN, err = namingContextOf(client, userDN); err != nil {
+				return err
+			}
+		}
+
+		filter := fmt.Sprintf("(|(member=%s)(uniqueMember=%s)", ldap.EscapeFilter(userDN), ldap.EscapeFilter(userDN))
+		if uid := user.GetEqualFoldAttributeValue("uid"); uid != "" {
+			filter += fmt.Sprintf("(&(objectClass=posixGroup)(memberUid=%s))", ldap.EscapeFilter(uid))
+		}
+		filter += ")"
+
+		log.Printf("[DEBUG] ldap_user_groups::read - searching %q for %s", baseDN, filter)
+
+		request := ldap.NewSearchRequest(
+			baseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			filter,
+			[]string{"1.1"},
+			nil,
+		)
+		sr, err := client.SearchWithPaging(request, ldapDefaultPageSize)
+		if err != nil {
+			log.Printf("[ERROR] ldap_user_groups::read - error searching the groups of %q: %v", userDN, err)
+			return err
+		}
+		groups = make([]string, 0, len(sr.Entries))
+		for _, entry := range sr.Entries {
+			groups = append(groups, entry.DN)
+		}
+	} else {
+		method = "memberof"
+	}
+	sort.Strings(groups)
+
+	log.Printf("[DEBUG] ldap_user_groups::read - %q is in %d groups (%s)", userDN, len(groups), method)
+
+	d.SetId(userDN)
+	d.Set("resolved_method", method)
+	return d.Set("groups", groups)
+}
+
+// namingContextOf returns the naming context advertised by the root DSE
+// that holds dn, the longest one when they are nested.
+func namingContextOf(client ldap.Client, dn string) (string, error) {
+	rootDSE, err := readRootDSE(client, "namingContexts")
+	if err != nil {
+		return "", err
+	}
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return "", fmt.Errorf("error parsing DN %q: %v", dn, err)
+	}
+	namingContext := ""
+	if rootDSE != nil {
+		for _, context := range rootDSE.GetEqualFoldAttributeValues("namingContexts") {
+			parsedContext, err := ldap.ParseDN(context)
+			if err != nil || len(context) <= len(namingContext) {
+				continue
+			}
+			if parsedContext.AncestorOfFold(parsed) || parsedContext.EqualFold(parsed) {
+				namingContext = context
+			}
+		}
+	}
+	if namingContext == "" {
+		return "", fmt.Errorf("no naming context of the server holds %q; set base_dn explicitly", dn)
+	}
+	return namingContext, nil
+}
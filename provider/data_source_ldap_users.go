@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceLDAPUsers() *schema.Resource {
+	user := ldapUserSchema()
+	user["attributes"] = &schema.Schema{
+		Type:        schema.TypeMap,
+		Description: "The first value of each of the selected attributes of the user.",
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+
+	return &schema.Resource{
+		Read: dataSourceLDAPUsersRead,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN to search for users under.",
+				Required:    true,
+			},
+			"filter": {
+				Type:        schema.TypeString,
+				Description: "An additional filter the users must match (e.g. (departmentNumber=42)).",
+				Optional:    true,
+			},
+			"attributes": {
+				Type:        schema.TypeList,
+				Description: "Additional attributes to return for each user.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"page_size": ldapPageSizeSchema(),
+			"users": {
+				Type:        schema.TypeList,
+				Description: "The users found, sorted by DN.",
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: user},
+			},
+			"dns": {
+				Type:        schema.TypeList,
+				Description: "The DNs of the users found, sorted.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceLDAPUsersRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+	filter := fmt.Sprintf("(&(objectClass=person)%s)", d.Get("filter").(string))
+	selected := toStringSlice(d.Get("attributes").([]interface{}))
+
+	log.Printf("[DEBUG] ldap_users::read - searching %q for %s", baseDN, filter)
+
+	request := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		filter,
+		ldapUserAttributeNames(selected...),
+		nil,
+	)
+	sr, err := searchLDAPPaged(client, request, d.Get("page_size").(int))
+	if err != nil {
+		log.Printf("[ERROR] ldap_users::read - error searching %q: %v", baseDN, err)
+		return err
+	}
+	sortLDAPEntries(sr.Entries)
+
+	log.Printf("[DEBUG] ldap_users::read - found %d users under %q", len(sr.Entries), baseDN)
+
+	users := make([]interface{}, 0, len(sr.Entries))
+	dns := make([]string, 0, len(sr.Entries))
+	for _, entry := range sr.Entries {
+		user := flattenLDAPUser(entry)
+		attributes := map[string]interface{}{}
+		for _, name := range selected {
+			if value := entry.GetEqualFoldAttributeValue(name); value != "" {
+				attributes[name] = value
+			}
+		}
+		user["attributes"] = attributes
+		users = append(users, user)
+		dns = append(dns, entry.DN)
+	}
+
+	d.SetId(fmt.Sprintf("%s?sub?%s", baseDN, filter))
+	d.Set("dns", dns)
+	return d.Set("users", users)
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// explainAccessError adds to an insufficientAccessRights error of an
+// operation what is needed to grant the missing right: the identity the
+// server checked (from the Who am I? operation on the connection that
+// failed, else the bind DN), the operation, its target DN and the attributes
+// involved. The error keeps its result code; other errors are returned as
+// they are.
+func (p *ldapPool) explainAccessError(conn *ldap.Conn, err error, operation, dn string, attributes []string) error {
+	e, ok := err.(*ldap.Error)
+	if !ok || e.ResultCode != ldap.LDAPResultInsufficientAccessRights {
+		return err
+	}
+
+	identity := p.bindDN
+	if result, whoAmIErr := conn.WhoAmI(nil); whoAmIErr == nil && result.AuthzID != "" {
+		identity = result.AuthzID
+	} else if whoAmIErr != nil {
+		log.Printf("[DEBUG] unable to tell the identity denied to %s %q: %v", operation, dn, whoAmIErr)
+	}
+	if identity == "" {
+		identity = "anonymous"
+	}
+
+	detail := fmt.Sprintf("%s denied to %s %q", identity, operation, dn)
+	if len(attributes) > 0 {
+		detail = fmt.Sprintf("%s (attributes %s)", detail, strings.Join(attributes, ", "))
+	}
+	return &ldap.Error{
+		ResultCode: e.ResultCode,
+		MatchedDN:  e.MatchedDN,
+		Packet:     e.Packet,
+		Err:        fmt.Errorf("%s; grant it the right in the access control of the server: %v", detail, e.Err),
+	}
+}
+
+// addRequestAttributes returns the names of the attributes of an add request.
+func addRequestAttributes(request *ldap.AddRequest) []string {
+	names := make([]string, 0, len(request.Attributes))
+	for _, attribute := range request.Attributes {
+		names = append(names, attribute.Type)
+	}
+	return names
+}
+
+// modifyRequestAttributes returns the names of the attributes changed by a
+// modify request, each once.
+func modifyRequestAttributes(request *ldap.ModifyRequest) []string {
+	names := []string{}
+	for _, change := range request.Changes {
+		if !stringSliceContains(names, change.Modification.Type) {
+			names = append(names, change.Modification.Type)
+		}
+	}
+	return names
+}
+
+// explainEntryExists turns the entryAlreadyExists error of the creation of
+// dn into a description of the entry already there, with the command to
+// import it when the resource type (e.g. ldap_object) has an importer taking
+// the DN, and an empty resourceType otherwise. Other errors are returned as
+// they are.
+func explainEntryExists(client ldap.Client, err error, resourceType, dn string) error {
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		return err
+	}
+
+	description := ""
+	entry, readErr := readConfigEntry(client, dn, "objectClass", "createTimestamp", "creatorsName", "whenCreated")
+	if readErr != nil {
+		log.Printf("[DEBUG] unable to read the existing entry %q: %v", dn, readErr)
+	} else if entry != nil {
+		description = fmt.Sprintf(" (object classes %s", strings.Join(entry.GetAttributeValues("objectClass"), ", "))
+		if created := entry.GetEqualFoldAttributeValue("createTimestamp"); created != "" {
+			description += ", created " + created
+		} else if created := entry.GetEqualFoldAttributeValue("whenCreated"); created != "" {
+			description += ", created " + created
+		}
+		if creator := entry.GetEqualFoldAttributeValue("creatorsName"); creator != "" {
+			description += " by " + creator
+		}
+		description += ")"
+	}
+
+	message := fmt.Sprintf("an entry already exists at %q%s", dn, description)
+	if resourceType != "" {
+		quoted := "'" + strings.Replace(dn, "'", `'\''`, -1) + "'"
+		message += fmt.Sprintf("; to manage it with this resource, import it with: terraform import %s.<name> %s", resourceType, quoted)
+	} else {
+		message += "; remove it or choose another name"
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
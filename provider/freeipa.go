@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ipaAutogenerate is the magic value that makes the FreeIPA DNA and UUID
+// plugins assign IDs (uidNumber, gidNumber, ipaUniqueID) on add.
+const (
+	ipaAutogenerate   = "autogenerate"
+	ipaAutogenerateID = "-1"
+)
+
+// ipaRealm returns the Kerberos realm of a FreeIPA domain, read from its
+// cn=kerberos container.
+func ipaRealm(client ldap.Client, baseDN string) (string, error) {
+	request := ldap.NewSearchRequest(
+		fmt.Sprintf("cn=kerberos,%s", baseDN),
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=krbRealmContainer)",
+		[]string{"cn"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		return "", err
+	}
+	if len(sr.Entries) != 1 {
+		return "", fmt.Errorf("expected one Kerberos realm under cn=kerberos,%s, found %d; set realm explicitly", baseDN, len(sr.Entries))
+	}
+	return sr.Entries[0].GetAttributeValue("cn"), nil
+}
+
+func ipaGroupDN(baseDN, name string) string {
+	return fmt.Sprintf("cn=%s,cn=groups,cn=accounts,%s", ldap.EscapeDN(name), baseDN)
+}
+
+func ipaUserDN(baseDN, uid string) string {
+	return fmt.Sprintf("uid=%s,cn=users,cn=accounts,%s", ldap.EscapeDN(uid), baseDN)
+}
+
+// searchIPAEntry returns the DN of the entry with the given cn and object
+// class directly below a container, used for the entries whose RDN is the
+// ipaUniqueID the server assigned to them.
+func searchIPAEntry(client ldap.Client, containerDN, objectClass, cn string) (string, error) {
+	request := ldap.NewSearchRequest(
+		containerDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&(objectClass=%s)(cn=%s))", ldap.EscapeFilter(objectClass), ldap.EscapeFilter(cn)),
+		[]string{"dn"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		return "", err
+	}
+	if len(sr.Entries) != 1 {
+		return "", fmt.Errorf("expected one %s named %q under %q, found %d", objectClass, cn, containerDN, len(sr.Entries))
+	}
+	return sr.Entries[0].DN, nil
+}
+
+// ipaMemberName returns the value of the RDN of a member DN, if the member is
+// an entry of the given container named by the given attribute.
+func ipaMemberName(member, attribute, containerDN string) (string, bool) {
+	dn, err := ldap.ParseDN(member)
+	if err != nil || len(dn.RDNs) < 2 || len(dn.RDNs[0].Attributes) != 1 || !strings.EqualFold(dn.RDNs[0].Attributes[0].Type, attribute) {
+		return "", false
+	}
+	container, err := ldap.ParseDN(containerDN)
+	if err != nil || !(&ldap.DN{RDNs: dn.RDNs[1:]}).EqualFold(container) {
+		return "", false
+	}
+	return dn.RDNs[0].Attributes[0].Value, true
+}
+
+// ipaMemberKind describes a kind of entry that a member attribute of a
+// FreeIPA rule links to (e.g. users or groups for memberUser), and the
+// argument holding the names of those entries.
+type ipaMemberKind struct {
+	key       string
+	attribute string
+	container string
+}
+
+// ipaMemberDNs returns the DNs of the entries named by the arguments of the
+// given kinds.
+func ipaMemberDNs(d *schema.ResourceData, baseDN string, kinds []ipaMemberKind) []string {
+	members := []string{}
+	for _, kind := range kinds {
+		for _, name := range toStringSlice(d.Get(kind.key).(*schema.Set).List()) {
+			members = append(members, fmt.Sprintf("%s=%s,%s,%s", kind.attribute, ldap.EscapeDN(name), kind.container, baseDN))
+		}
+	}
+	return members
+}
+
+// addIPAMembers adds the member attributes to an add request, given a map of
+// member attributes to the kinds of entries they link to.
+func addIPAMembers(request *ldap.AddRequest, d *schema.ResourceData, members map[string][]ipaMemberKind) {
+	for attribute, kinds := range members {
+		if dns := ipaMemberDNs(d, d.Get("base_dn").(string), kinds); len(dns) > 0 {
+			request.Attribute(attribute, dns)
+		}
+	}
+}
+
+// modifyIPAMembers replaces the member attributes whose arguments changed.
+func modifyIPAMembers(modify *ldap.ModifyRequest, d *schema.ResourceData, members map[string][]ipaMemberKind) {
+	for attribute, kinds := range members {
+		keys := []string{}
+		for _, kind := range kinds {
+			keys = append(keys, kind.key)
+		}
+		if d.HasChanges(keys...) {
+			modify.Replace(attribute, ipaMemberDNs(d, d.Get("base_dn").(string), kinds))
+		}
+	}
+}
+
+// readIPAMembers sets the arguments of the member attributes from an entry;
+// DNs that are not of the expected kinds are ignored.
+func readIPAMembers(d *schema.ResourceData, entry *ldap.Entry, members map[string][]ipaMemberKind) {
+	baseDN := d.Get("base_dn").(string)
+	for attribute, kinds := range members {
+		names := map[string][]string{}
+		for _, member := range entry.GetEqualFoldAttributeValues(attribute) {
+			for _, kind := range kinds {
+				if name, ok := ipaMemberName(member, kind.attribute, fmt.Sprintf("%s,%s", kind.container, baseDN)); ok {
+					names[kind.key] = append(names[kind.key], name)
+					break
+				}
+			}
+		}
+		for _, kind := range kinds {
+			d.Set(kind.key, matchAttributeNames(names[kind.key], toStringSlice(d.Get(kind.key).(*schema.Set).List())))
+		}
+	}
+}
+
+// ipaValues returns the values of an argument as attribute values: strings
+// and non-zero integers become a single value, sets one value per element
+// and booleans TRUE or FALSE.
+func ipaValues(d *schema.ResourceData, key string) []string {
+	switch v := d.Get(key).(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case int:
+		if v != 0 {
+			return []string{strconv.Itoa(v)}
+		}
+	case bool:
+		return []string{strings.ToUpper(strconv.FormatBool(v))}
+	case *schema.Set:
+		values := toStringSlice(v.List())
+		sort.Strings(values)
+		return values
+	}
+	return []string{}
+}
+
+// addIPAAttributes adds the set arguments to an add request, given a map of
+// argument names to attribute names.
+func addIPAAttributes(request *ldap.AddRequest, d *schema.ResourceData, attributes map[string]string) {
+	for key, attribute := range attributes {
+		if values := ipaValues(d, key); len(values) > 0 {
+			request.Attribute(attribute, values)
+		}
+	}
+}
+
+// modifyIPAAttributes replaces the attributes whose argument changed.
+func modifyIPAAttributes(modify *ldap.ModifyRequest, d *schema.ResourceData, attributes map[string]string) {
+	for key, attribute := range attributes {
+		if d.HasChange(key) {
+			modify.Replace(attribute, ipaValues(d, key))
+		}
+	}
+}
+
+// readIPAAttributes sets the arguments from the attributes of an entry.
+// Values of set arguments that only differ in case from configured ones
+// (e.g. DNs normalized by the server) are kept as configured.
+func readIPAAttributes(d *schema.ResourceData, entry *ldap.Entry, attributes map[string]string) {
+	for key, attribute := range attributes {
+		values := entry.GetEqualFoldAttributeValues(attribute)
+		switch v := d.Get(key).(type) {
+		case string:
+			d.Set(key, entry.GetEqualFoldAttributeValue(attribute))
+		case int:
+			n, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue(attribute))
+			d.Set(key, n)
+		case bool:
+			d.Set(key, strings.EqualFold(entry.GetEqualFoldAttributeValue(attribute), "TRUE"))
+		case *schema.Set:
+			d.Set(key, matchAttributeNames(values, toStringSlice(v.List())))
+		}
+	}
+}
+
+// ipaAttributeNames returns the attribute names of a map of argument names
+// to attribute names, plus the extra ones.
+func ipaAttributeNames(attributes map[string]string, extra ...string) []string {
+	names := append([]string{}, extra...)
+	for _, attribute := range attributes {
+		names = append(names, attribute)
+	}
+	return names
+}
+
+// ipaMemberAttributeNames returns the names of the member attributes.
+func ipaMemberAttributeNames(members map[string][]ipaMemberKind) []string {
+	names := []string{}
+	for attribute := range members {
+		names = append(names, attribute)
+	}
+	return names
+}
+
+// deleteIPAEntry deletes an entry, ignoring entries that are already gone.
+func deleteIPAEntry(client ldap.Client, resource, dn string) error {
+	log.Printf("[DEBUG] %s::delete - removing %q", resource, dn)
+
+	if err := client.Del(ldap.NewDelRequest(dn, nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] %s::delete - error removing %q: %v", resource, dn, err)
+		return err
+	}
+	return nil
+}
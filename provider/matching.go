@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"log"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapAttributeRules are the equality matching rule and the syntax of an
+// attribute type.
+type ldapAttributeRules struct {
+	equality string
+	syntax   string
+}
+
+// readAttributeRules returns the equality matching rules and syntaxes of the
+// attribute types of the subschema, by lowercase name and OID, those not
+// declaring them inheriting those of their superior. They are parsed once
+// per subschema entry cached by the pool, not on every read.
+func readAttributeRules(client ldap.Client) (map[string]ldapAttributeRules, error) {
+	entry, err := readSubschemaEntry(client, "", "attributeTypes")
+	if err != nil {
+		return nil, err
+	}
+	pool, cached := client.(*ldapPool)
+	if cached {
+		pool.cache.mu.Lock()
+		rules := pool.cache.rules
+		if pool.cache.rulesSource != entry {
+			rules = nil
+		}
+		pool.cache.mu.Unlock()
+		if rules != nil {
+			return rules, nil
+		}
+	}
+	definitions, err := parseSchemaDefinitions(entry.GetEqualFoldAttributeValues("attributeTypes"))
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]schemaDefinition{}
+	for _, definition := range definitions {
+		byName[strings.ToLower(definition.oid)] = definition
+		for _, name := range definition.fields["NAME"] {
+			byName[strings.ToLower(name)] = definition
+		}
+	}
+	rules := map[string]ldapAttributeRules{}
+	for key, definition := range byName {
+		var attributeRules ldapAttributeRules
+		// follow the superiors, without looping on broken schemas
+		for depth := 0; depth < 16; depth++ {
+			if attributeRules.equality == "" {
+				attributeRules.equality = definition.field("EQUALITY")
+			}
+			if attributeRules.syntax == "" {
+				attributeRules.syntax = definition.field("SYNTAX")
+			}
+			superior, ok := byName[strings.ToLower(definition.field("SUP"))]
+			if !ok || (attributeRules.equality != "" && attributeRules.syntax != "") {
+				break
+			}
+			definition = superior
+		}
+		rules[key] = attributeRules
+	}
+	if cached {
+		pool.cache.mu.Lock()
+		pool.cache.rules, pool.cache.rulesSource = rules, entry
+		pool.cache.mu.Unlock()
+	}
+	return rules, nil
+}
+
+// ldapValueMatcher keeps the values of attributes as configured when the
+// server returns them in another form that is equal once normalized per the
+// syntax and the equality matching rule of the attribute (e.g. a DN with
+// other spacing or case, or a telephone number with other separators), so
+// that its normalization does not show as a change on every plan. The rules
+// are only read from the subschema once a value differs.
+type ldapValueMatcher struct {
+	client ldap.Client
+	rules  map[string]ldapAttributeRules // nil until read
+}
+
+// match returns the values read for attribute, with those equal to one of
+// the configured values replaced by it.
+func (m *ldapValueMatcher) match(attribute string, values, configured []string) []string {
+	exact := make(map[string]struct{}, len(configured))
+	for _, value := range configured {
+		exact[value] = struct{}{}
+	}
+	var normalized map[string]string // configured values by normal form
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := exact[value]; ok || len(configured) == 0 {
+			result = append(result, value)
+			continue
+		}
+		if normalized == nil {
+			normalized = map[string]string{}
+			for _, c := range configured {
+				if n, ok := m.normalize(attribute, c); ok {
+					normalized[n] = c
+				}
+			}
+		}
+		if n, ok := m.normalize(attribute, value); ok {
+			if c, ok := normalized[n]; ok {
+				log.Printf("[DEBUG] keeping %s value %q as configured (%q)", attribute, c, value)
+				value = c
+			}
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+// normalize returns the normal form of a value of attribute under its syntax
+// and then its equality matching rule, and whether either is known.
+func (m *ldapValueMatcher) normalize(attribute, value string) (string, bool) {
+	if m.rules == nil {
+		rules, err := readAttributeRules(m.client)
+		if err != nil {
+			log.Printf("[WARN] unable to read the matching rules of the schema, comparing values exactly: %v", err)
+			rules = map[string]ldapAttributeRules{}
+		}
+		m.rules = rules
+	}
+	// options such as ;binary or ;lang-en do not change the rules
+	rules := m.rules[strings.ToLower(strings.SplitN(attribute, ";", 2)[0])]
+	if strings.EqualFold(rules.equality, "distinguishedNameMatch") || rules.equality == "2.5.13.1" ||
+		strings.SplitN(rules.syntax, "{", 2)[0] == "1.3.6.1.4.1.1466.115.121.1.12" {
+		dn, err := ldap.ParseDN(value)
+		if err != nil {
+			return value, false
+		}
+		return strings.ToLower(normalizeDN(dn)), true
+	}
+	value, bySyntax := util.NormalizeSyntaxValue(rules.syntax, value)
+	value, byRule := util.NormalizeValue(rules.equality, value)
+	return value, bySyntax || byRule
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLDAPValueMatcherTelephoneNumbers(t *testing.T) {
+	matcher := &ldapValueMatcher{rules: map[string]ldapAttributeRules{
+		"telephonenumber": {equality: "telephoneNumberMatch", syntax: "1.3.6.1.4.1.1466.115.121.1.50{32}"},
+	}}
+	tests := []struct {
+		read, configured, expected []string
+	}{
+		// spaces and hyphens are insignificant to telephoneNumberMatch
+		{[]string{"+15550100"}, []string{"+1 555-0100"}, []string{"+1 555-0100"}},
+		// parentheses and dots are not, so the drift must show as a diff
+		{[]string{"5550100"}, []string{"(555)0100"}, []string{"5550100"}},
+		{[]string{"555.0100"}, []string{"5550100"}, []string{"555.0100"}},
+	}
+	for _, test := range tests {
+		if matched := matcher.match("telephoneNumber", test.read, test.configured); !reflect.DeepEqual(matched, test.expected) {
+			t.Errorf("Expected %v read with %v configured to be kept as %v, got %v", test.read, test.configured, test.expected, matched)
+		}
+	}
+}
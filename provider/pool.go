@@ -0,0 +1,639 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapPool is an ldap.Client spreading operations over up to size
+// connections, so that the resources Terraform handles in parallel do not
+// queue on a single connection. Each operation checks a connection out for
+// itself until its last response has been read, so no connection is ever
+// shared by concurrent operations. Connections are dialed and bound on
+// demand, and those the server closed are replaced. Operations may be
+// throttled, see limitRate and limitModifies, waiting for their turn rather
+// than failing. Once a connection is lost, a single operation re-dials
+// while the others wait for its outcome, see connect.
+type ldapPool struct {
+	dial      func() (*ldap.Conn, error)
+	idle      chan *ldap.Conn
+	slots     chan struct{} // one per open connection
+	modifies  chan struct{} // one per running write, nil when unlimited
+	interval  time.Duration // between the starts of operations, 0 when unlimited
+	batchSize int           // the values per request of modifyInBatches, 0 for the default
+	bindDN    string        // the identity of the connections, see explainAccessError
+
+	// retryPolicies are the retry_policy settings, by result code
+	retryPolicies map[uint16]ldapRetryPolicy
+
+	// dialReferral connects to the server of a referral, see followReferral;
+	// nil when referrals are not followed
+	dialReferral func(referral *url.URL) (*ldap.Conn, error)
+	cache        ldapEntryCache
+
+	mu      sync.Mutex
+	timeout time.Duration
+	closed  bool
+	err     error       // the permanent failure of a previous dial
+	next    time.Time   // when the next operation may start
+	lost    bool        // whether a connection was closed by the server or a dial failed since the last dial
+	redial  *ldapRedial // the dial in flight while lost
+}
+
+// ldapRedial is the outcome of the dial after a connection was lost, awaited
+// by the operations needing a new connection meanwhile.
+type ldapRedial struct {
+	done chan struct{}
+	err  error
+}
+
+var _ ldap.Client = (*ldapPool)(nil)
+
+// the attempts made at the operations that can be repeated when the server
+// closes their connection, see reconnecting
+const ldapReconnectAttempts = 3
+
+// ldapRetryPolicy is how to retry the operations failing with a result code.
+type ldapRetryPolicy struct {
+	retries int
+	backoff time.Duration // before the first retry, doubled for the next ones
+}
+
+func newLDAPPool(size int, dial func() (*ldap.Conn, error)) *ldapPool {
+	return &ldapPool{
+		retryPolicies: map[uint16]ldapRetryPolicy{},
+		dial:          dial,
+		idle:          make(chan *ldap.Conn, size),
+		slots:         make(chan struct{}, size),
+	}
+}
+
+// limitRate spaces the starts of the operations so that at most perSecond
+// are sent each second.
+func (p *ldapPool) limitRate(perSecond int) {
+	p.interval = time.Second / time.Duration(perSecond)
+}
+
+// limitModifies bounds the number of writes (adds, deletes, modifies and
+// password changes) running at once.
+func (p *ldapPool) limitModifies(size int) {
+	p.modifies = make(chan struct{}, size)
+}
+
+// throttle waits for the turn of an operation under limitRate.
+func (p *ldapPool) throttle() {
+	if p.interval == 0 {
+		return
+	}
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+	time.Sleep(time.Until(start))
+}
+
+// acquire returns an idle connection, or a new one while the pool is not
+// full, else waits for one to be released.
+func (p *ldapPool) acquire() (*ldap.Conn, error) {
+	for {
+		var conn *ldap.Conn
+		select {
+		case conn = <-p.idle:
+		default:
+			select {
+			case conn = <-p.idle:
+			case p.slots <- struct{}{}:
+				conn, redial, err := p.connect()
+				if redial != nil {
+					// wait without holding a slot, then try again
+					<-p.slots
+					if err := p.awaitRedial(redial); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				if err != nil {
+					<-p.slots
+					return nil, err
+				}
+				return p.checkout(conn), nil
+			}
+		}
+		if !conn.IsClosing() {
+			return p.checkout(conn), nil
+		}
+		log.Printf("[DEBUG] ldap connection closed by the server, reconnecting")
+		conn.Close()
+		<-p.slots
+		p.lose()
+	}
+}
+
+// lose records that the server closed a connection, closing the idle ones
+// too: a server shutting down closes them all, but a connection is only
+// noticed to be closed once its reader fails, possibly under an operation.
+func (p *ldapPool) lose() {
+	p.mu.Lock()
+	lost := p.lost
+	p.lost = true
+	p.mu.Unlock()
+	if lost {
+		return
+	}
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+			<-p.slots
+		default:
+			return
+		}
+	}
+}
+
+// connect dials a new connection. Once a connection was lost, only one
+// operation re-dials at a time rather than all of those in flight hammering
+// a server coming back up: the others get the dial in flight to await.
+func (p *ldapPool) connect() (conn *ldap.Conn, redial *ldapRedial, err error) {
+	p.mu.Lock()
+	if p.err != nil {
+		err = p.err
+		p.mu.Unlock()
+		return nil, nil, err
+	}
+	if p.redial != nil {
+		redial = p.redial
+		p.mu.Unlock()
+		return nil, redial, nil
+	}
+	lost := p.lost
+	if lost {
+		redial = &ldapRedial{done: make(chan struct{})}
+		p.redial = redial
+	}
+	p.mu.Unlock()
+
+	conn, err = p.dial()
+
+	p.mu.Lock()
+	if e, ok := err.(*ldapConnectError); ok && e.permanent {
+		p.err = err
+	}
+	p.lost = err != nil
+	if lost {
+		redial.err = err
+		p.redial = nil
+		close(redial.done)
+	}
+	p.mu.Unlock()
+	return conn, nil, err
+}
+
+// awaitRedial waits for the outcome of the dial of another operation, for
+// at most the timeout of the pool.
+func (p *ldapPool) awaitRedial(redial *ldapRedial) error {
+	p.mu.Lock()
+	timeout := p.timeout
+	p.mu.Unlock()
+	if timeout <= 0 {
+		timeout = ldap.DefaultTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-redial.done:
+		return redial.err
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s waiting to reconnect to the LDAP server", timeout)
+	}
+}
+
+// write runs the write operation f with a connection of the pool, within
+// the limit of limitModifies.
+func (p *ldapPool) write(f func(conn *ldap.Conn) error) error {
+	if p.modifies != nil {
+		p.modifies <- struct{}{}
+		defer func() { <-p.modifies }()
+	}
+	return p.with(f)
+}
+
+// checkout applies the current settings of the pool to a connection before
+// handing it out, as SetTimeout may have been called since it was dialed.
+func (p *ldapPool) checkout(conn *ldap.Conn) *ldap.Conn {
+	p.mu.Lock()
+	if p.timeout > 0 {
+		conn.SetTimeout(p.timeout)
+	}
+	p.mu.Unlock()
+	return conn
+}
+
+// reconnecting runs f with run (with or write), running it again on a new
+// connection when the server closed the connection under it, as it does
+// after sending a Notice of Disconnection when shutting down; retried tells
+// f that an earlier attempt may have been applied. Only operations that can
+// safely be repeated are run this way.
+func (p *ldapPool) reconnecting(run func(func(*ldap.Conn) error) error, f func(conn *ldap.Conn, retried bool) error) error {
+	for attempt := 1; ; attempt++ {
+		closed := false
+		err := run(func(conn *ldap.Conn) error {
+			err := f(conn, attempt > 1)
+			if err != nil && !conn.IsClosing() && ldapSendFailed(err) {
+				// the request was not sent, the connection being closed
+				// before its reader noticed
+				conn.Close()
+			}
+			if err != nil && conn.IsClosing() {
+				closed = true
+				if last := conn.GetLastError(); last != nil {
+					log.Printf("[DEBUG] last error of the closed connection: %v", last)
+				}
+			}
+			return err
+		})
+		if !closed || attempt == ldapReconnectAttempts {
+			return err
+		}
+		log.Printf("[WARN] the server closed the connection during the operation (%v), retrying on a new connection", err)
+	}
+}
+
+// modifyApplied reports whether the entry holds the values added by a modify
+// and none of those it deletes, once its retry failed because they were
+// already added or deleted: as modifies are atomic, the attempt the
+// connection was closed under then went through, rather than another client
+// changing some of the values since. It only knows of adds and deletes.
+func modifyApplied(conn *ldap.Conn, request *ldap.ModifyRequest) bool {
+	clauses := []string{}
+	for _, change := range request.Changes {
+		attribute := change.Modification.Type
+		switch change.Operation {
+		case ldap.AddAttribute:
+			for _, value := range change.Modification.Vals {
+				clauses = append(clauses, fmt.Sprintf("(%s=%s)", attribute, ldap.EscapeFilter(value)))
+			}
+		case ldap.DeleteAttribute:
+			if len(change.Modification.Vals) == 0 {
+				clauses = append(clauses, fmt.Sprintf("(!(%s=*))", attribute))
+			}
+			for _, value := range change.Modification.Vals {
+				clauses = append(clauses, fmt.Sprintf("(!(%s=%s))", attribute, ldap.EscapeFilter(value)))
+			}
+		default:
+			return false
+		}
+	}
+	sr, err := conn.Search(ldap.NewSearchRequest(
+		request.DN,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&%s)", strings.Join(clauses, "")),
+		[]string{"1.1"},
+		nil,
+	))
+	return err == nil && len(sr.Entries) == 1
+}
+
+// ldapSendFailed reports whether err is the failure to write a request to
+// the connection, which go-ldap returns as is, without closing it.
+func ldapSendFailed(err error) bool {
+	return strings.HasPrefix(err.Error(), "unable to send request: ")
+}
+
+// release returns a connection acquired from the pool.
+func (p *ldapPool) release(conn *ldap.Conn) {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed || conn.IsClosing() {
+		conn.Close()
+		<-p.slots
+		if !closed {
+			p.lose()
+		}
+		return
+	}
+	p.idle <- conn
+}
+
+// with runs f with a connection of the pool, again while it fails with a
+// result code its retry policy says to retry.
+func (p *ldapPool) with(f func(conn *ldap.Conn) error) error {
+	for retry := 0; ; retry++ {
+		p.throttle()
+		conn, err := p.acquire()
+		if err != nil {
+			return err
+		}
+		err = f(conn)
+		p.release(conn)
+
+		e, ok := err.(*ldap.Error)
+		if !ok {
+			return err
+		}
+		policy, ok := p.retryPolicies[e.ResultCode]
+		if !ok || retry >= policy.retries {
+			return err
+		}
+		delay := policy.backoff << uint(retry)
+		log.Printf("[WARN] operation failed with result code %d, retrying in %s (%d of %d): %v", e.ResultCode, delay, retry+1, policy.retries, err)
+		time.Sleep(delay)
+	}
+}
+
+func (p *ldapPool) Start() {}
+
+func (p *ldapPool) StartTLS(*tls.Config) error {
+	return fmt.Errorf("StartTLS is negotiated when the connections of the pool are dialed")
+}
+
+func (p *ldapPool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+			<-p.slots
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *ldapPool) GetLastError() error {
+	return nil
+}
+
+func (p *ldapPool) IsClosing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+func (p *ldapPool) SetTimeout(timeout time.Duration) {
+	p.mu.Lock()
+	p.timeout = timeout
+	p.mu.Unlock()
+}
+
+func (p *ldapPool) TLSConnectionState() (state tls.ConnectionState, ok bool) {
+	p.with(func(conn *ldap.Conn) error {
+		state, ok = conn.TLSConnectionState()
+		return nil
+	})
+	return state, ok
+}
+
+// binds would only change the identity of one of the connections
+
+func (p *ldapPool) Bind(username, password string) error {
+	return fmt.Errorf("the connections of the pool are bound when dialed")
+}
+
+func (p *ldapPool) UnauthenticatedBind(username string) error {
+	return p.Bind(username, "")
+}
+
+func (p *ldapPool) SimpleBind(*ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error) {
+	return nil, p.Bind("", "")
+}
+
+func (p *ldapPool) ExternalBind() error {
+	return p.Bind("", "")
+}
+
+func (p *ldapPool) NTLMUnauthenticatedBind(domain, username string) error {
+	return p.Bind("", "")
+}
+
+func (p *ldapPool) Unbind() error {
+	return p.Bind("", "")
+}
+
+func (p *ldapPool) Add(request *ldap.AddRequest) error {
+	defer p.cache.invalidate(request.DN)
+	return p.write(func(conn *ldap.Conn) error {
+		attributes := addRequestAttributes(request)
+		err := p.explainAccessError(conn, conn.Add(request), "add", request.DN, attributes)
+		return p.followReferral(err, request.DN, func(conn *ldap.Conn, dn string) error {
+			referred := *request
+			referred.DN = dn
+			return p.explainAccessError(conn, conn.Add(&referred), "add", dn, attributes)
+		})
+	})
+}
+
+func (p *ldapPool) Del(request *ldap.DelRequest) error {
+	defer p.cache.invalidate(request.DN)
+	return p.reconnecting(p.write, func(conn *ldap.Conn, retried bool) error {
+		err := p.explainAccessError(conn, conn.Del(request), "delete", request.DN, nil)
+		err = p.followReferral(err, request.DN, func(conn *ldap.Conn, dn string) error {
+			referred := *request
+			referred.DN = dn
+			return p.explainAccessError(conn, conn.Del(&referred), "delete", dn, nil)
+		})
+		if retried && ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			// deleted by the attempt the connection was closed under
+			return nil
+		}
+		return err
+	})
+}
+
+func (p *ldapPool) Modify(request *ldap.ModifyRequest) error {
+	_, err := p.ModifyWithResult(request)
+	return err
+}
+
+func (p *ldapPool) ModifyDN(request *ldap.ModifyDNRequest) error {
+	defer p.cache.invalidate(request.DN)
+	return p.write(func(conn *ldap.Conn) error {
+		err := p.explainAccessError(conn, conn.ModifyDN(request), "rename", request.DN, nil)
+		return p.followReferral(err, request.DN, func(conn *ldap.Conn, dn string) error {
+			referred := *request
+			referred.DN = dn
+			return p.explainAccessError(conn, conn.ModifyDN(&referred), "rename", dn, nil)
+		})
+	})
+}
+
+func (p *ldapPool) ModifyWithResult(request *ldap.ModifyRequest) (*ldap.ModifyResult, error) {
+	for _, change := range request.Changes {
+		if change.Operation == ldap.IncrementAttribute {
+			// increments cannot be repeated
+			return p.modifyWithResult(request, false)
+		}
+	}
+	return p.modifyWithResult(request, true)
+}
+
+// modifyOnce runs a modify that must not be repeated on a new connection,
+// e.g. a compare-and-swap: a retry failing could not tell whether the first
+// attempt was applied or another client changed the values.
+func (p *ldapPool) modifyOnce(request *ldap.ModifyRequest) error {
+	_, err := p.modifyWithResult(request, false)
+	return err
+}
+
+func (p *ldapPool) modifyWithResult(request *ldap.ModifyRequest, repeatable bool) (result *ldap.ModifyResult, err error) {
+	defer p.cache.invalidate(request.DN)
+	modify := func(conn *ldap.Conn, retried bool) error {
+		attributes := modifyRequestAttributes(request)
+		result, err = conn.ModifyWithResult(request)
+		err = p.followReferral(p.explainAccessError(conn, err, "modify", request.DN, attributes), request.DN, func(conn *ldap.Conn, dn string) error {
+			referred := *request
+			referred.DN = dn
+			result, err = conn.ModifyWithResult(&referred)
+			return p.explainAccessError(conn, err, "modify", dn, attributes)
+		})
+		if retried && ldap.IsErrorAnyOf(err, ldap.LDAPResultAttributeOrValueExists, ldap.LDAPResultNoSuchAttribute) && modifyApplied(conn, request) {
+			// applied by the attempt the connection was closed under
+			return nil
+		}
+		return err
+	}
+	if !repeatable {
+		err = p.write(func(conn *ldap.Conn) error {
+			return modify(conn, false)
+		})
+		return result, err
+	}
+	err = p.reconnecting(p.write, modify)
+	return result, err
+}
+
+func (p *ldapPool) Extended(request *ldap.ExtendedRequest) (response *ldap.ExtendedResponse, err error) {
+	err = p.with(func(conn *ldap.Conn) error {
+		response, err = conn.Extended(request)
+		return err
+	})
+	return response, err
+}
+
+func (p *ldapPool) Compare(dn, attribute, value string) (matches bool, err error) {
+	err = p.reconnecting(p.with, func(conn *ldap.Conn, retried bool) error {
+		matches, err = conn.Compare(dn, attribute, value)
+		return p.explainAccessError(conn, err, "compare", dn, []string{attribute})
+	})
+	return matches, err
+}
+
+func (p *ldapPool) PasswordModify(request *ldap.PasswordModifyRequest) (result *ldap.PasswordModifyResult, err error) {
+	err = p.write(func(conn *ldap.Conn) error {
+		result, err = conn.PasswordModify(request)
+		err = p.explainAccessError(conn, err, "change the password of", request.UserIdentity, nil)
+		return p.followReferral(err, request.UserIdentity, func(conn *ldap.Conn, dn string) error {
+			referred := *request
+			referred.UserIdentity = dn
+			result, err = conn.PasswordModify(&referred)
+			return p.explainAccessError(conn, err, "change the password of", dn, nil)
+		})
+	})
+	return result, err
+}
+
+func (p *ldapPool) Search(request *ldap.SearchRequest) (result *ldap.SearchResult, err error) {
+	err = p.reconnecting(p.with, func(conn *ldap.Conn, retried bool) error {
+		result, err = conn.Search(request)
+		return p.explainAccessError(conn, err, "search", request.BaseDN, request.Attributes)
+	})
+	return result, err
+}
+
+func (p *ldapPool) SearchWithPaging(request *ldap.SearchRequest, pagingSize uint32) (result *ldap.SearchResult, err error) {
+	err = p.reconnecting(p.with, func(conn *ldap.Conn, retried bool) error {
+		if paging, ok := ldap.FindControl(request.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging); ok && retried {
+			// the cookie of the closed connection is meaningless to the new one
+			paging.SetCookie(nil)
+		}
+		result, err = conn.SearchWithPaging(request, pagingSize)
+		return p.explainAccessError(conn, err, "search", request.BaseDN, request.Attributes)
+	})
+	return result, err
+}
+
+func (p *ldapPool) DirSync(request *ldap.SearchRequest, flags, maxAttrCount int64, cookie []byte) (result *ldap.SearchResult, err error) {
+	err = p.with(func(conn *ldap.Conn) error {
+		result, err = conn.DirSync(request, flags, maxAttrCount, cookie)
+		return err
+	})
+	return result, err
+}
+
+// the asynchronous operations hold their connection until their response
+// has been read to the end; unlike the others, they are neither retried nor
+// explained, see streamLDAPSearch for the searches streamed by the provider
+
+func (p *ldapPool) SearchAsync(ctx context.Context, request *ldap.SearchRequest, bufferSize int) ldap.Response {
+	return p.async(func(conn *ldap.Conn) ldap.Response {
+		return conn.SearchAsync(ctx, request, bufferSize)
+	})
+}
+
+func (p *ldapPool) DirSyncAsync(ctx context.Context, request *ldap.SearchRequest, bufferSize int, flags, maxAttrCount int64, cookie []byte) ldap.Response {
+	return p.async(func(conn *ldap.Conn) ldap.Response {
+		return conn.DirSyncAsync(ctx, request, bufferSize, flags, maxAttrCount, cookie)
+	})
+}
+
+func (p *ldapPool) Syncrepl(ctx context.Context, request *ldap.SearchRequest, bufferSize int, mode ldap.ControlSyncRequestMode, cookie []byte, reloadHint bool) ldap.Response {
+	return p.async(func(conn *ldap.Conn) ldap.Response {
+		return conn.Syncrepl(ctx, request, bufferSize, mode, cookie, reloadHint)
+	})
+}
+
+func (p *ldapPool) async(f func(conn *ldap.Conn) ldap.Response) ldap.Response {
+	p.throttle()
+	conn, err := p.acquire()
+	if err != nil {
+		return &ldapPoolErrorResponse{err: err}
+	}
+	return &ldapPoolResponse{Response: f(conn), release: func() { p.release(conn) }}
+}
+
+// ldapPoolResponse releases the connection of an asynchronous operation
+// once its last result has been read.
+type ldapPoolResponse struct {
+	ldap.Response
+	release func()
+	once    sync.Once
+}
+
+func (r *ldapPoolResponse) Next() bool {
+	if r.Response.Next() {
+		return true
+	}
+	r.once.Do(r.release)
+	return false
+}
+
+// ldapPoolErrorResponse is the response of an asynchronous operation for
+// which no connection could be made.
+type ldapPoolErrorResponse struct {
+	err error
+}
+
+func (r *ldapPoolErrorResponse) Entry() *ldap.Entry       { return nil }
+func (r *ldapPoolErrorResponse) Referral() string         { return "" }
+func (r *ldapPoolErrorResponse) Controls() []ldap.Control { return nil }
+func (r *ldapPoolErrorResponse) Err() error               { return r.err }
+func (r *ldapPoolErrorResponse) Next() bool               { return false }
@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testLDAPServer answers the searches sent over the connections it dials
+// with their base entry, and their modifies with success, until drop closes
+// them all.
+type testLDAPServer struct {
+	mu         sync.Mutex
+	conns      []net.Conn
+	requests   int
+	dropAfter  int  // the request on which to drop the connections, 0 for never
+	recovering bool // whether no dial completed since the drop
+	dialing    int
+	overlaps   int // dials started while recovering with another in flight
+	dials      int
+}
+
+func (s *testLDAPServer) dial() (*ldap.Conn, error) {
+	s.mu.Lock()
+	s.dials++
+	s.dialing++
+	if s.recovering && s.dialing > 1 {
+		s.overlaps++
+	}
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	client, server := net.Pipe()
+
+	s.mu.Lock()
+	s.dialing--
+	s.recovering = false
+	s.conns = append(s.conns, server)
+	s.mu.Unlock()
+
+	go s.serve(server)
+	conn := ldap.NewConn(client, false)
+	conn.Start()
+	return conn, nil
+}
+
+func (s *testLDAPServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.recovering = true
+}
+
+func (s *testLDAPServer) serve(conn net.Conn) {
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.requests++
+		drop := s.requests == s.dropAfter
+		s.mu.Unlock()
+		if drop {
+			s.drop()
+			return
+		}
+
+		var tag ber.Tag
+		switch request.Children[1].Tag {
+		case ldap.ApplicationSearchRequest:
+			tag = ldap.ApplicationSearchResultDone
+			entry := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			entry.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, request.Children[0].Value, "MessageID"))
+			result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+			result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, request.Children[1].Children[0].Value.(string), "objectName"))
+			result.AppendChild(ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes"))
+			entry.AppendChild(result)
+			if _, err := conn.Write(entry.Bytes()); err != nil {
+				return
+			}
+		case ldap.ApplicationModifyRequest:
+			tag = ldap.ApplicationModifyResponse
+		default:
+			continue
+		}
+		response := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+		response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, request.Children[0].Value, "MessageID"))
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+		result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, ldap.LDAPResultSuccess, "resultCode"))
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+		response.AppendChild(result)
+		if _, err := conn.Write(response.Bytes()); err != nil {
+			return
+		}
+	}
+}
+
+func TestPoolReconnectsDuringParallelApply(t *testing.T) {
+	server := &testLDAPServer{dropAfter: 50}
+	pool := newLDAPPool(10, server.dial)
+	pool.SetTimeout(5 * time.Second)
+	defer pool.Close()
+
+	// as many resources as a large apply, each reading then updating its entry
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			dn := fmt.Sprintf("cn=resource%d,dc=example,dc=com", i)
+			if _, err := pool.Search(ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", nil, nil)); err != nil {
+				errs <- err
+				return
+			}
+			modify := ldap.NewModifyRequest(dn, nil)
+			modify.Replace("description", []string{"managed"})
+			errs <- pool.Modify(modify)
+		}(i)
+	}
+
+	deadline := time.After(30 * time.Second)
+	for i := 0; i < 100; i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		case <-deadline:
+			t.Fatalf("Deadlocked with %d operations left", 100-i)
+		}
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.dials <= 10 || server.recovering {
+		t.Errorf("Expected the connections to be dropped and redialed, got %d dials", server.dials)
+	}
+	if server.overlaps > 0 {
+		t.Errorf("Expected a single redial at a time after the drop, got %d overlapping dials", server.overlaps)
+	}
+	if server.dials > 20 {
+		t.Errorf("Expected the pool to redial at most once per connection, got %d dials", server.dials)
+	}
+}
+
+func TestPoolRedialWaitTimesOut(t *testing.T) {
+	unblock := make(chan struct{})
+	pool := newLDAPPool(2, func() (*ldap.Conn, error) {
+		<-unblock
+		return nil, &ldapConnectError{detail: "server down"}
+	})
+	pool.SetTimeout(50 * time.Millisecond)
+	pool.lost = true
+
+	dialed := make(chan error)
+	go func() {
+		_, err := pool.acquire()
+		dialed <- err
+	}()
+	for {
+		pool.mu.Lock()
+		redialing := pool.redial != nil
+		pool.mu.Unlock()
+		if redialing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := pool.acquire(); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout waiting for the redial, got %v", err)
+	}
+	close(unblock)
+	if err := <-dialed; err == nil {
+		t.Errorf("Expected the redial to fail")
+	}
+	if _, err := pool.acquire(); err == nil || strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected the failure of a new dial, got %v", err)
+	}
+}
+
+func TestStreamLDAPSearchRestartsAfterDrop(t *testing.T) {
+	server := &testLDAPServer{dropAfter: 1}
+	pool := newLDAPPool(2, server.dial)
+	pool.SetTimeout(5 * time.Second)
+	defer pool.Close()
+
+	entries, restarts := []string{}, 0
+	request := ldap.NewSearchRequest("dc=example,dc=com", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", nil, nil)
+	_, err := streamLDAPSearch(pool, request, 0, func() {
+		entries = entries[:0]
+		restarts++
+	}, func(entry *ldap.Entry) error {
+		entries = append(entries, entry.DN)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restarts != 1 || len(entries) != 1 || entries[0] != "dc=example,dc=com" {
+		t.Errorf("Expected a single restart and entry, got %d restarts and %v", restarts, entries)
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func New(version string) func() *schema.Provider {
@@ -40,11 +44,165 @@ func New(version string) func() *schema.Provider {
 					Sensitive:   true,
 					DefaultFunc: schema.EnvDefaultFunc("LDAP_BIND_PASSWORD", nil),
 				},
+				"max_connections": {
+					Type:         schema.TypeInt,
+					Description:  "The maximum number of connections to the server, over which the operations Terraform runs in parallel are spread; match it with -parallelism (10 by default).",
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("LDAP_MAX_CONNECTIONS", 10),
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"max_operations_per_second": {
+					Type:         schema.TypeInt,
+					Description:  "The maximum number of operations sent to the server per second, further ones waiting for their turn, e.g. to stay below the throttling policies of Active Directory; no limit when 0 (the default).",
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("LDAP_MAX_OPERATIONS_PER_SECOND", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"max_concurrent_modifies": {
+					Type:         schema.TypeInt,
+					Description:  "The maximum number of writes (adds, deletes, modifies and password changes) running at once, further ones waiting for their turn, e.g. to spare small replicas; only bounded by max_connections when 0 (the default).",
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("LDAP_MAX_CONCURRENT_MODIFIES", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"modify_batch_size": {
+					Type:         schema.TypeInt,
+					Description:  "The maximum number of values sent in one modify request when changing large multi-valued attributes (e.g. the members of a group), larger changes being split into consecutive requests, retried like any other operation (1000 by default).",
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("LDAP_MODIFY_BATCH_SIZE", ldapModifyBatchSize),
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"retry_policy": {
+					Type:        schema.TypeList,
+					Description: "How to retry the operations failing with given LDAP result codes (e.g. 51 busy, 52 unavailable, 53 unwilling to perform); operations failing with other codes fail at once.",
+					Optional:    true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"result_codes": {
+								Type:        schema.TypeSet,
+								Description: "The result codes the policy applies to.",
+								Required:    true,
+								Elem: &schema.Schema{
+									Type:         schema.TypeInt,
+									ValidateFunc: validation.IntBetween(1, 65535),
+								},
+							},
+							"max_retries": {
+								Type:         schema.TypeInt,
+								Description:  "The number of times to retry the operation; 0 fails at once.",
+								Required:     true,
+								ValidateFunc: validation.IntAtLeast(0),
+							},
+							"backoff_seconds": {
+								Type:         schema.TypeInt,
+								Description:  "The delay before the first retry, doubled for each of the next ones.",
+								Optional:     true,
+								Default:      1,
+								ValidateFunc: validation.IntAtLeast(0),
+							},
+						},
+					},
+				},
+				"follow_referrals": {
+					Type:        schema.TypeBool,
+					Description: "Whether to follow the referrals returned to writes (e.g. by read-only replicas or for writable subordinate partitions) by connecting to the referred server and retrying the operation there. Only the hosts listed in referral_credentials or referral_hosts are followed to, over LDAPS or StartTLS.",
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("LDAP_FOLLOW_REFERRALS", false),
+				},
+				"referral_hosts": {
+					Type:        schema.TypeSet,
+					Description: "The hosts referrals are followed to binding with the credentials of the provider, as in the referral URL, with its port when the URL has one (e.g. ldap2.example.com:389).",
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"referral_credentials": {
+					Type:        schema.TypeList,
+					Description: "The credentials to bind with to the servers referrals are followed to, by host.",
+					Optional:    true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"host": {
+								Type:        schema.TypeString,
+								Description: "The host of the referred server, as in the referral URL, with its port when the URL has one (e.g. ldap2.example.com:389).",
+								Required:    true,
+							},
+							"bind_user": {
+								Type:        schema.TypeString,
+								Description: "The DN to bind as to the server.",
+								Required:    true,
+							},
+							"bind_password": {
+								Type:        schema.TypeString,
+								Description: "The password to bind with to the server.",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"ldap_object": resourceLDAPObject(),
+				"ldap_object":                        resourceLDAPObject(),
+				"ldap_ad_foreign_security_principal": resourceLDAPADForeignSecurityPrincipal(),
+				"ldap_dynamic_object":                resourceLDAPDynamicObject(),
+				"ldap_subentry":                      resourceLDAPSubentry(),
+				"ldap_olc_access":                    resourceLDAPOlcAccess(),
+				"ldap_olc_schema":                    resourceLDAPOlcSchema(),
+				"ldap_olc_syncrepl":                  resourceLDAPOlcSyncrepl(),
+				"ldap_olc_index":                     resourceLDAPOlcIndex(),
+				"ldap_olc_limits":                    resourceLDAPOlcLimits(),
+				"ldap_olc_global_config":             resourceLDAPOlcGlobalConfig(),
+				"ldap_olc_memberof":                  resourceLDAPOlcMemberOf(),
+				"ldap_olc_overlay_dynlist":           resourceLDAPOlcOverlayDynlist(),
+				"ldap_olc_tls":                       resourceLDAPOlcTLS(),
+				"ldap_freeipa_user":                  resourceLDAPFreeIPAUser(),
+				"ldap_freeipa_group":                 resourceLDAPFreeIPAGroup(),
+				"ldap_freeipa_hbac_rule":             resourceLDAPFreeIPAHBACRule(),
+				"ldap_freeipa_sudo_rule":             resourceLDAPFreeIPASudoRule(),
+				"ldap_freeipa_host":                  resourceLDAPFreeIPAHost(),
+				"ldap_389ds_plugin":                  resourceLDAP389DSPlugin(),
+				"ldap_389ds_replica":                 resourceLDAP389DSReplica(),
+				"ldap_389ds_replication_agreement":   resourceLDAP389DSReplicationAgreement(),
+				"ldap_389ds_index":                   resourceLDAP389DSIndex(),
+				"ldap_389ds_backend":                 resourceLDAP389DSBackend(),
+				"ldap_password_policy_assignment":    resourceLDAPPasswordPolicyAssignment(),
+				"ldap_config_password":               resourceLDAPConfigPassword(),
+				"ldap_id_number_reservation":         resourceLDAPIDNumberReservation(),
+			},
+			DataSourcesMap: map[string]*schema.Resource{
+				"ldap_search":                   dataSourceLDAPSearch(),
+				"ldap_group":                    dataSourceLDAPGroup(),
+				"ldap_group_members":            dataSourceLDAPGroupMembers(),
+				"ldap_user":                     dataSourceLDAPUser(),
+				"ldap_users":                    dataSourceLDAPUsers(),
+				"ldap_organizational_unit":      dataSourceLDAPOrganizationalUnit(),
+				"ldap_children":                 dataSourceLDAPChildren(),
+				"ldap_schema":                   dataSourceLDAPSchema(),
+				"ldap_dn":                       dataSourceLDAPDN(),
+				"ldap_next_uid_number":          dataSourceLDAPNextUIDNumber(),
+				"ldap_next_gid_number":          dataSourceLDAPNextGIDNumber(),
+				"ldap_ad_object_by_sid":         dataSourceLDAPADObjectBySID(),
+				"ldap_ad_domain":                dataSourceLDAPADDomain(),
+				"ldap_ad_user":                  dataSourceLDAPADUser(),
+				"ldap_ad_group":                 dataSourceLDAPADGroup(),
+				"ldap_ad_transitive_membership": dataSourceLDAPADTransitiveMembership(),
+				"ldap_entry_exists":             dataSourceLDAPEntryExists(),
+				"ldap_password_policy":          dataSourceLDAPPasswordPolicy(),
+				"ldap_supported_features":       dataSourceLDAPSupportedFeatures(),
+				"ldap_ad_forest":                dataSourceLDAPADForest(),
+				"ldap_user_groups":              dataSourceLDAPUserGroups(),
+				"ldap_srv_servers":              dataSourceLDAPSRVServers(),
+				"ldap_ad_gpo":                   dataSourceLDAPADGPO(),
+				"ldap_replication_status":       dataSourceLDAPReplicationStatus(),
+				"ldap_ad_computer":              dataSourceLDAPADComputer(),
+				"ldap_ad_guid":                  dataSourceLDAPADGUID(),
+				"ldap_ad_sid":                   dataSourceLDAPADSID(),
+				"ldap_timestamp":                dataSourceLDAPTimestamp(),
+				"ldap_ad_user_account_control":  dataSourceLDAPADUserAccountControl(),
+				"ldap_filter":                   dataSourceLDAPFilter(),
+				"ldap_ldif_entries":             dataSourceLDAPLDIFEntries(),
+				"ldap_ldif":                     dataSourceLDAPLDIF(),
 			},
-			DataSourcesMap:       map[string]*schema.Resource{},
 			ConfigureContextFunc: providerConfigure,
 		}
 
@@ -52,6 +210,18 @@ func New(version string) func() *schema.Provider {
 	}
 }
 
+// ldapConnectError is the failure of one of the steps of connecting to the
+// server; permanent ones (rejected credentials) are not retried, so that the
+// bind account is not locked out by every resource of the plan trying again.
+type ldapConnectError struct {
+	detail    string
+	permanent bool
+}
+
+func (e *ldapConnectError) Error() string {
+	return e.detail
+}
+
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
@@ -62,39 +232,77 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	bindUser := d.Get("bind_user").(string)
 	bindPassword := d.Get("bind_password").(string)
 
-	l, err := ldap.DialURL(url, ldap.DialWithTLSConfig(&tlsConfig))
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Failed to connect to ldap server",
-			Detail:   fmt.Sprintf("Connecting to ldap server failed with: %v", err),
-		})
-		return nil, diags
+	dialAs := func(url string, useStartTLS bool, bindUser, bindPassword string) (*ldap.Conn, error) {
+		l, err := ldap.DialURL(url, ldap.DialWithTLSConfig(&tlsConfig))
+		if err != nil {
+			return nil, &ldapConnectError{detail: fmt.Sprintf("Connecting to ldap server failed with: %v", err)}
+		}
+		if useStartTLS {
+			if err := l.StartTLS(&tlsConfig); err != nil {
+				l.Close()
+				return nil, &ldapConnectError{detail: fmt.Sprintf("Establishing StartTLS session failed with: %v", err)}
+			}
+		}
+		if err := l.Bind(bindUser, bindPassword); err != nil {
+			l.Close()
+			return nil, &ldapConnectError{
+				detail:    fmt.Sprintf("Binding user failed with: %v", err),
+				permanent: ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials),
+			}
+		}
+		return l, nil
 	}
+	dial := func() (*ldap.Conn, error) {
+		return dialAs(url, useStartTLS, bindUser, bindPassword)
+	}
+	// the connections are only dialed by the first operations, so that
+	// validating or planning configurations that do not read from the server
+	// does not require it to be reachable
 	// TODO: https://github.com/hashicorp/terraform-plugin-sdk/issues/63
-	// defer l.Close()
-
-	if useStartTLS {
-		err = l.StartTLS(&tlsConfig)
-		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Error,
-				Summary:  "Failed to establish StartTLS session",
-				Detail:   fmt.Sprintf("Establishing StartTLS session failed with: %v", err),
-			})
-			return nil, diags
+	// the connections are never closed
+	pool := newLDAPPool(d.Get("max_connections").(int), dial)
+	if perSecond := d.Get("max_operations_per_second").(int); perSecond > 0 {
+		pool.limitRate(perSecond)
+	}
+	if modifies := d.Get("max_concurrent_modifies").(int); modifies > 0 {
+		pool.limitModifies(modifies)
+	}
+	pool.batchSize = d.Get("modify_batch_size").(int)
+	pool.bindDN = bindUser
+	for _, policy := range d.Get("retry_policy").([]interface{}) {
+		policy := policy.(map[string]interface{})
+		for _, code := range policy["result_codes"].(*schema.Set).List() {
+			pool.retryPolicies[uint16(code.(int))] = ldapRetryPolicy{
+				retries: policy["max_retries"].(int),
+				backoff: time.Duration(policy["backoff_seconds"].(int)) * time.Second,
+			}
 		}
 	}
-
-	err = l.Bind(bindUser, bindPassword)
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Failed to perform bind",
-			Detail:   fmt.Sprintf("Binding user failed with: %v", err),
-		})
-		return nil, diags
+	if d.Get("follow_referrals").(bool) {
+		credentials := map[string][2]string{}
+		for _, host := range d.Get("referral_hosts").(*schema.Set).List() {
+			credentials[strings.ToLower(host.(string))] = [2]string{bindUser, bindPassword}
+		}
+		for _, c := range d.Get("referral_credentials").([]interface{}) {
+			c := c.(map[string]interface{})
+			credentials[strings.ToLower(c["host"].(string))] = [2]string{c["bind_user"].(string), c["bind_password"].(string)}
+		}
+		pool.dialReferral = func(referral *neturl.URL) (*ldap.Conn, error) {
+			c, ok := credentials[strings.ToLower(referral.Host)]
+			if !ok {
+				// the credentials are never sent to servers not trusted with them
+				return nil, fmt.Errorf("the host %s is not listed in referral_credentials or referral_hosts", referral.Host)
+			}
+			switch strings.ToLower(referral.Scheme) {
+			case "ldaps":
+				return dialAs("ldaps://"+referral.Host, false, c[0], c[1])
+			case "ldap":
+				// the bind is never sent in clear
+				return dialAs("ldap://"+referral.Host, true, c[0], c[1])
+			default:
+				return nil, fmt.Errorf("unsupported referral scheme %q", referral.Scheme)
+			}
+		}
 	}
-
-	return l, diags
+	return pool, diags
 }
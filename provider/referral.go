@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// the number of referrals followed at most for one operation, against
+// servers referring to each other
+const ldapReferralHopLimit = 5
+
+// referralURLs returns the URLs of the referral (result 10) an operation
+// failed with, if any.
+func referralURLs(err error) []string {
+	e, ok := err.(*ldap.Error)
+	if !ok || e.ResultCode != ldap.LDAPResultReferral || e.Packet == nil || len(e.Packet.Children) < 2 {
+		return nil
+	}
+	urls := []string{}
+	for _, child := range e.Packet.Children[1].Children {
+		// the referral is the [3] SEQUENCE OF URI following the diagnostic
+		// message of the LDAPResult
+		if child.ClassType != ber.ClassContext || child.TagType != ber.TypeConstructed || child.Tag != 3 {
+			continue
+		}
+		for _, uri := range child.Children {
+			if value, ok := uri.Value.(string); ok {
+				urls = append(urls, value)
+			} else {
+				urls = append(urls, uri.Data.String())
+			}
+		}
+	}
+	return urls
+}
+
+// followReferral retries a write that failed with a referral on the referred
+// server, with retry given a connection to it and the DN to target there
+// (that of the referral URL, else the original one), as long as referrals
+// are followed (follow_referrals) and the hop limit is not reached. Other
+// errors are returned as they are, so retry explains its own on the referred
+// connection.
+func (p *ldapPool) followReferral(err error, dn string, retry func(conn *ldap.Conn, dn string) error) error {
+	for hop := 0; p.dialReferral != nil && hop < ldapReferralHopLimit; hop++ {
+		urls := referralURLs(err)
+		if len(urls) == 0 {
+			return err
+		}
+		var failures []string
+		followed := false
+		for _, u := range urls {
+			referral, parseErr := url.Parse(u)
+			if parseErr != nil || referral.Host == "" {
+				failures = append(failures, fmt.Sprintf("%s: invalid referral URL", u))
+				continue
+			}
+			target := dn
+			if referred := strings.TrimPrefix(referral.Path, "/"); referred != "" {
+				target = referred
+			}
+			log.Printf("[DEBUG] following the referral of %q to %s", dn, u)
+			conn, dialErr := p.dialReferral(referral)
+			if dialErr != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", u, dialErr))
+				continue
+			}
+			err = retry(conn, target)
+			conn.Close()
+			followed = true
+			break
+		}
+		if !followed {
+			return fmt.Errorf("unable to follow the referral of %q (%s): %v", dn, strings.Join(failures, "; "), err)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
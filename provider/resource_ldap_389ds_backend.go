@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the object classes of the root entry of a suffix, by naming attribute
+var rootEntryObjectClasses = map[string][]string{
+	"dc": {"top", "domain"},
+	"o":  {"top", "organization"},
+	"ou": {"top", "organizationalUnit"},
+	"c":  {"top", "country"},
+	"cn": {"top", "nsContainer"},
+}
+
+func resourceLDAP389DSBackend() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAP389DSBackendCreate,
+		Read:   resourceLDAP389DSBackendRead,
+		Delete: resourceLDAP389DSBackendDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAP389DSBackendImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the ldbm backend (e.g. userRoot).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"suffix": {
+				Type:        schema.TypeString,
+				Description: "The suffix served by the backend (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"create_root_entry": {
+				Type:        schema.TypeBool,
+				Description: "Whether to create the root entry of the suffix; its object classes are derived from the naming attribute (dc, o, ou, c or cn).",
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the backend entry; destroying the resource deletes the backend and all of its entries.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAP389DSBackendCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+	suffix := d.Get("suffix").(string)
+	dn := fmt.Sprintf("cn=%s,cn=ldbm database,cn=plugins,cn=config", ldap.EscapeDN(name))
+
+	var root *ldap.AddRequest
+	if d.Get("create_root_entry").(bool) {
+		parsed, err := ldap.ParseDN(suffix)
+		if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) != 1 {
+			return fmt.Errorf("invalid suffix %q: %v", suffix, err)
+		}
+		rdn := parsed.RDNs[0].Attributes[0]
+		objectClasses, ok := rootEntryObjectClasses[strings.ToLower(rdn.Type)]
+		if !ok {
+			return fmt.Errorf("cannot create the root entry of %q: unsupported naming attribute %q", suffix, rdn.Type)
+		}
+		root = ldap.NewAddRequest(suffix, []ldap.Control{})
+		root.Attribute("objectClass", objectClasses)
+		root.Attribute(rdn.Type, []string{rdn.Value})
+	}
+
+	log.Printf("[DEBUG] ldap_389ds_backend::create - creating backend %q for %q", name, suffix)
+
+	backend := ldap.NewAddRequest(dn, []ldap.Control{})
+	backend.Attribute("objectClass", []string{"top", "extensibleObject", "nsBackendInstance"})
+	backend.Attribute("cn", []string{name})
+	backend.Attribute("nsslapd-suffix", []string{suffix})
+	if err := client.Add(backend); err != nil {
+		log.Printf("[ERROR] ldap_389ds_backend::create - error creating backend %q: %v", dn, err)
+		return err
+	}
+	d.SetId(dn)
+
+	mappingTree := ldap.NewAddRequest(mappingTreeDN(suffix), []ldap.Control{})
+	mappingTree.Attribute("objectClass", []string{"top", "extensibleObject", "nsMappingTree"})
+	mappingTree.Attribute("cn", []string{suffix})
+	mappingTree.Attribute("nsslapd-state", []string{"backend"})
+	mappingTree.Attribute("nsslapd-backend", []string{name})
+	if err := client.Add(mappingTree); err != nil {
+		log.Printf("[ERROR] ldap_389ds_backend::create - error creating mapping tree entry of %q: %v", suffix, err)
+		return err
+	}
+
+	if root != nil {
+		log.Printf("[DEBUG] ldap_389ds_backend::create - creating root entry %q", suffix)
+		if err := client.Add(root); err != nil {
+			if err, ok := err.(*ldap.Error); !ok || err.ResultCode != ldap.LDAPResultEntryAlreadyExists {
+				log.Printf("[ERROR] ldap_389ds_backend::create - error creating root entry %q: %v", suffix, err)
+				return err
+			}
+		}
+	}
+
+	return resourceLDAP389DSBackendRead(d, meta)
+}
+
+func resourceLDAP389DSBackendRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_389ds_backend::read - reading backend %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "cn", "nsslapd-suffix")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_389ds_backend::read - backend %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	return d.Set("suffix", entry.GetEqualFoldAttributeValue("nsslapd-suffix"))
+}
+
+func resourceLDAP389DSBackendDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	suffix := d.Get("suffix").(string)
+
+	log.Printf("[DEBUG] ldap_389ds_backend::delete - removing backend %q", d.Id())
+
+	// the mapping tree entry refers to the backend, so it goes first; the
+	// server drops the database files along with the backend entry
+	for _, dn := range []string{mappingTreeDN(suffix), d.Id()} {
+		if err := client.Del(ldap.NewDelRequest(dn, nil)); err != nil {
+			if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+				continue
+			}
+			log.Printf("[ERROR] ldap_389ds_backend::delete - error removing %q: %v", dn, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceLDAP389DSBackendImport expects the DN of the backend entry.
+func resourceLDAP389DSBackendImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dn, err := ldap.ParseDN(d.Id())
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return nil, fmt.Errorf("invalid ID %q, expected the DN of a backend entry", d.Id())
+	}
+	d.Set("name", dn.RDNs[0].Attributes[0].Value)
+	d.Set("create_root_entry", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+// mappingTreeDN returns the DN of the mapping tree entry of a suffix.
+func mappingTreeDN(suffix string) string {
+	return fmt.Sprintf("cn=%s,cn=mapping tree,cn=config", ldap.EscapeDN(suffix))
+}
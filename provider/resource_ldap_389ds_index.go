@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLDAP389DSIndex() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAP389DSIndexCreate,
+		Read:   resourceLDAP389DSIndexRead,
+		Update: resourceLDAP389DSIndexUpdate,
+		Delete: resourceLDAP389DSIndexDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAP389DSIndexImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Description: "The name of the ldbm backend (e.g. userRoot).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute to index.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"types": {
+				Type:        schema.TypeSet,
+				Description: "The index types to maintain (eq, pres, sub, approx).",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"eq", "pres", "sub", "approx"}, false),
+				},
+				Set:      schema.HashString,
+				Required: true,
+				MinItems: 1,
+			},
+			"matching_rules": {
+				Type:        schema.TypeSet,
+				Description: "The matching rules to index (nsMatchingRule, e.g. for ordering or language-specific searches).",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"reindex": {
+				Type:        schema.TypeBool,
+				Description: "Whether to start a reindex task for the attribute when the index is created or changed; the task runs in the background.",
+				Optional:    true,
+				Default:     false,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the index entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAP389DSIndexCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	backend := d.Get("backend").(string)
+	attribute := d.Get("attribute").(string)
+	dn := fmt.Sprintf("cn=%s,cn=index,cn=%s,cn=ldbm database,cn=plugins,cn=config", ldap.EscapeDN(attribute), ldap.EscapeDN(backend))
+
+	log.Printf("[DEBUG] ldap_389ds_index::create - indexing %q on backend %q", attribute, backend)
+
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{"top", "nsIndex"})
+	request.Attribute("cn", []string{attribute})
+	request.Attribute("nsSystemIndex", []string{"false"})
+	request.Attribute("nsIndexType", toStringSlice(d.Get("types").(*schema.Set).List()))
+	if rules := toStringSlice(d.Get("matching_rules").(*schema.Set).List()); len(rules) > 0 {
+		request.Attribute("nsMatchingRule", rules)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_389ds_index::create - error indexing %q on backend %q: %v", attribute, backend, err)
+		return err
+	}
+
+	d.SetId(dn)
+	if d.Get("reindex").(bool) {
+		if err := start389DSReindexTask(client, backend, attribute); err != nil {
+			return err
+		}
+	}
+	return resourceLDAP389DSIndexRead(d, meta)
+}
+
+func resourceLDAP389DSIndexRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_389ds_index::read - reading index %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "cn", "nsIndexType", "nsMatchingRule")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_389ds_index::read - index %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	types := []string{}
+	for _, t := range entry.GetEqualFoldAttributeValues("nsIndexType") {
+		types = append(types, strings.ToLower(t))
+	}
+
+	d.Set("dn", dn)
+	d.Set("attribute", entry.GetAttributeValue("cn"))
+	d.Set("types", types)
+	return d.Set("matching_rules", entry.GetEqualFoldAttributeValues("nsMatchingRule"))
+}
+
+func resourceLDAP389DSIndexUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_index::update - performing update on %q", d.Id())
+
+	if d.HasChanges("types", "matching_rules") {
+		modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+		modify.Replace("nsIndexType", toStringSlice(d.Get("types").(*schema.Set).List()))
+		modify.Replace("nsMatchingRule", toStringSlice(d.Get("matching_rules").(*schema.Set).List()))
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_389ds_index::update - error updating index %q: %v", d.Id(), err)
+			return err
+		}
+		if d.Get("reindex").(bool) {
+			if err := start389DSReindexTask(client, d.Get("backend").(string), d.Get("attribute").(string)); err != nil {
+				return err
+			}
+		}
+	}
+	return resourceLDAP389DSIndexRead(d, meta)
+}
+
+func resourceLDAP389DSIndexDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_index::delete - removing index %q", d.Id())
+
+	if err := client.Del(ldap.NewDelRequest(d.Id(), nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_389ds_index::delete - error removing index %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// resourceLDAP389DSIndexImport expects the DN of the index entry, i.e.
+// cn=<attribute>,cn=index,cn=<backend>,cn=ldbm database,cn=plugins,cn=config.
+func resourceLDAP389DSIndexImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dn, err := ldap.ParseDN(d.Id())
+	if err != nil || len(dn.RDNs) < 3 || len(dn.RDNs[2].Attributes) == 0 {
+		return nil, fmt.Errorf("invalid ID %q, expected the DN of an index entry", d.Id())
+	}
+	d.Set("backend", dn.RDNs[2].Attributes[0].Value)
+	return []*schema.ResourceData{d}, nil
+}
+
+// start389DSReindexTask adds a task entry asking the server to rebuild the
+// index of an attribute; the server removes the entry once the task is done.
+func start389DSReindexTask(client ldap.Client, backend, attribute string) error {
+	name := fmt.Sprintf("terraform_%s_%s_%d", backend, attribute, time.Now().Unix())
+	dn := fmt.Sprintf("cn=%s,cn=index,cn=tasks,cn=config", ldap.EscapeDN(name))
+
+	log.Printf("[DEBUG] ldap_389ds_index::reindex - starting task %q", dn)
+
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{"top", "extensibleObject"})
+	request.Attribute("cn", []string{name})
+	request.Attribute("nsInstance", []string{backend})
+	request.Attribute("nsIndexAttribute", []string{attribute})
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_389ds_index::reindex - error starting task %q: %v", dn, err)
+		return err
+	}
+	return nil
+}
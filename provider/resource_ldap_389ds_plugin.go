@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAP389DSPlugin() *schema.Resource {
+	attribute := configAttributeSchema("Plugin-specific attributes to manage (e.g. memberOfGroupAttr); any attribute not listed here is left alone.", "The name of the attribute.")
+	attribute.Required = false
+	attribute.Optional = true
+	attribute.MinItems = 0
+
+	return &schema.Resource{
+		Create: resourceLDAP389DSPluginCreate,
+		Read:   resourceLDAP389DSPluginRead,
+		Update: resourceLDAP389DSPluginUpdate,
+		Delete: resourceLDAP389DSPluginDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAP389DSPluginImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the plugin entry under cn=plugins,cn=config (e.g. \"MemberOf Plugin\", \"referential integrity postoperation\").",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the plugin is enabled; unless nsslapd-dynamic-plugins is on, the server must be restarted for this to take effect.",
+				Required:    true,
+			},
+			"arguments": {
+				Type:        schema.TypeList,
+				Description: "The positional plugin arguments (nsslapd-pluginarg0, nsslapd-pluginarg1, ...), for plugins configured that way.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+			},
+			"attribute": attribute,
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the plugin entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAP389DSPluginCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := fmt.Sprintf("cn=%s,cn=plugins,cn=config", ldap.EscapeDN(d.Get("name").(string)))
+
+	log.Printf("[DEBUG] ldap_389ds_plugin::create - configuring plugin %q", dn)
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	for name, values := range configAttributes(d.Get("attribute").(*schema.Set)) {
+		modify.Replace(name, values)
+	}
+	for i, argument := range toStringSlice(d.Get("arguments").([]interface{})) {
+		modify.Replace(fmt.Sprintf("nsslapd-pluginarg%d", i), []string{argument})
+	}
+	// configure first, then enable, as some plugins validate their
+	// configuration when they are started
+	modify.Replace("nsslapd-pluginEnabled", []string{pluginEnabledValue(d.Get("enabled").(bool))})
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_389ds_plugin::create - error configuring plugin %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAP389DSPluginRead(d, meta)
+}
+
+func resourceLDAP389DSPluginRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_389ds_plugin::read - reading plugin %q", dn)
+
+	configured := configAttributes(d.Get("attribute").(*schema.Set))
+	attributes := []string{"cn", "nsslapd-pluginEnabled"}
+	for name := range configured {
+		attributes = append(attributes, name)
+	}
+	arguments := len(d.Get("arguments").([]interface{}))
+	for i := 0; i < arguments; i++ {
+		attributes = append(attributes, fmt.Sprintf("nsslapd-pluginarg%d", i))
+	}
+
+	entry, err := readConfigEntry(client, dn, attributes...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_389ds_plugin::read - plugin %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	values := []string{}
+	for i := 0; i < arguments; i++ {
+		if v := entry.GetEqualFoldAttributeValue(fmt.Sprintf("nsslapd-pluginarg%d", i)); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	d.Set("enabled", strings.EqualFold(entry.GetEqualFoldAttributeValue("nsslapd-pluginEnabled"), "on"))
+	d.Set("arguments", values)
+	return d.Set("attribute", flattenConfigAttributes(entry, configured))
+}
+
+func resourceLDAP389DSPluginUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_plugin::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+
+	// disabling goes first and enabling last, so that the plugin never runs
+	// with a half-applied configuration
+	enabled := d.Get("enabled").(bool)
+	if d.HasChange("enabled") && !enabled {
+		modify.Replace("nsslapd-pluginEnabled", []string{pluginEnabledValue(false)})
+	}
+	if d.HasChange("attribute") {
+		o, n := d.GetChange("attribute")
+		old := configAttributes(o.(*schema.Set))
+		new := configAttributes(n.(*schema.Set))
+		for name := range old {
+			if _, ok := new[name]; !ok {
+				modify.Replace(name, []string{})
+			}
+		}
+		for name, values := range new {
+			if !stringSlicesEqual(old[name], values) {
+				modify.Replace(name, values)
+			}
+		}
+	}
+	if d.HasChange("arguments") {
+		o, n := d.GetChange("arguments")
+		old := toStringSlice(o.([]interface{}))
+		new := toStringSlice(n.([]interface{}))
+		for i := len(new); i < len(old); i++ {
+			modify.Replace(fmt.Sprintf("nsslapd-pluginarg%d", i), []string{})
+		}
+		for i, argument := range new {
+			if i >= len(old) || old[i] != argument {
+				modify.Replace(fmt.Sprintf("nsslapd-pluginarg%d", i), []string{argument})
+			}
+		}
+	}
+	if d.HasChange("enabled") && enabled {
+		modify.Replace("nsslapd-pluginEnabled", []string{pluginEnabledValue(true)})
+	}
+
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_389ds_plugin::update - error updating plugin %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAP389DSPluginRead(d, meta)
+}
+
+func resourceLDAP389DSPluginDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_plugin::delete - disabling plugin %q", d.Id())
+
+	// plugin entries belong to the server: we disable the plugin and drop the
+	// configuration we added, but never remove the entry itself
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modify.Replace("nsslapd-pluginEnabled", []string{pluginEnabledValue(false)})
+	for name := range configAttributes(d.Get("attribute").(*schema.Set)) {
+		modify.Replace(name, []string{})
+	}
+	for i := range d.Get("arguments").([]interface{}) {
+		modify.Replace(fmt.Sprintf("nsslapd-pluginarg%d", i), []string{})
+	}
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_389ds_plugin::delete - error disabling plugin %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// resourceLDAP389DSPluginImport expects the DN of the plugin entry.
+func resourceLDAP389DSPluginImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	dn, err := ldap.ParseDN(d.Id())
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return nil, fmt.Errorf("invalid ID %q, expected the DN of a plugin entry", d.Id())
+	}
+	d.Set("name", dn.RDNs[0].Attributes[0].Value)
+	return []*schema.ResourceData{d}, nil
+}
+
+func pluginEnabledValue(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
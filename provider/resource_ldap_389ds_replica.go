@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the replica ID that 389-ds reserves for read-only replicas
+const readOnlyReplicaID = 65535
+
+func resourceLDAP389DSReplica() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAP389DSReplicaCreate,
+		Read:   resourceLDAP389DSReplicaRead,
+		Update: resourceLDAP389DSReplicaUpdate,
+		Delete: resourceLDAP389DSReplicaDelete,
+
+		Schema: map[string]*schema.Schema{
+			"suffix": {
+				Type:        schema.TypeString,
+				Description: "The suffix to replicate (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"role": {
+				Type:         schema.TypeString,
+				Description:  "The role of this server for the suffix: supplier, hub or consumer.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"supplier", "hub", "consumer"}, false),
+			},
+			"replica_id": {
+				Type:         schema.TypeInt,
+				Description:  "The replica ID, unique among the suppliers of the topology; required for suppliers.",
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(1, 65534),
+			},
+			"bind_dns": {
+				Type:        schema.TypeSet,
+				Description: "The DNs that suppliers use to bind when sending updates to this replica.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the replica configuration entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAP389DSReplicaCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	suffix := d.Get("suffix").(string)
+	role := d.Get("role").(string)
+	dn := replicaDN(suffix)
+
+	// 389-ds encodes the role in the replica type (3 is read-write, 2 is
+	// read-only) and in the changelog flag, which is off only for consumers
+	replicaType, flags, id := "2", "1", readOnlyReplicaID
+	switch role {
+	case "supplier":
+		v, ok := d.GetOk("replica_id")
+		if !ok {
+			return fmt.Errorf("replica_id is required for a supplier replica of %q", suffix)
+		}
+		replicaType, id = "3", v.(int)
+	case "consumer":
+		flags = "0"
+	}
+
+	log.Printf("[DEBUG] ldap_389ds_replica::create - enabling replication of %q as %s", suffix, role)
+
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{"top", "nsds5replica", "extensibleObject"})
+	request.Attribute("cn", []string{"replica"})
+	request.Attribute("nsDS5ReplicaRoot", []string{suffix})
+	request.Attribute("nsDS5ReplicaType", []string{replicaType})
+	request.Attribute("nsDS5Flags", []string{flags})
+	request.Attribute("nsDS5ReplicaId", []string{strconv.Itoa(id)})
+	if bindDNs := toStringSlice(d.Get("bind_dns").(*schema.Set).List()); len(bindDNs) > 0 {
+		request.Attribute("nsDS5ReplicaBindDN", bindDNs)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_389ds_replica::create - error enabling replication of %q: %v", suffix, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAP389DSReplicaRead(d, meta)
+}
+
+func resourceLDAP389DSReplicaRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_389ds_replica::read - reading replica %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "nsDS5ReplicaRoot", "nsDS5ReplicaType", "nsDS5Flags", "nsDS5ReplicaId", "nsDS5ReplicaBindDN")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_389ds_replica::read - replica %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	role := "consumer"
+	if entry.GetEqualFoldAttributeValue("nsDS5ReplicaType") == "3" {
+		role = "supplier"
+		id, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("nsDS5ReplicaId"))
+		d.Set("replica_id", id)
+	} else if entry.GetEqualFoldAttributeValue("nsDS5Flags") == "1" {
+		role = "hub"
+	}
+
+	d.Set("dn", dn)
+	d.Set("suffix", entry.GetEqualFoldAttributeValue("nsDS5ReplicaRoot"))
+	d.Set("role", role)
+	return d.Set("bind_dns", entry.GetEqualFoldAttributeValues("nsDS5ReplicaBindDN"))
+}
+
+func resourceLDAP389DSReplicaUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_replica::update - performing update on %q", d.Id())
+
+	if d.HasChange("bind_dns") {
+		modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+		modify.Replace("nsDS5ReplicaBindDN", toStringSlice(d.Get("bind_dns").(*schema.Set).List()))
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_389ds_replica::update - error updating replica %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAP389DSReplicaRead(d, meta)
+}
+
+func resourceLDAP389DSReplicaDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_replica::delete - disabling replication on %q", d.Id())
+
+	if err := client.Del(ldap.NewDelRequest(d.Id(), nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_389ds_replica::delete - error disabling replication on %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// replicaDN returns the DN of the replica configuration entry of a suffix,
+// which lives under the suffix's mapping tree entry.
+func replicaDN(suffix string) string {
+	return fmt.Sprintf("cn=replica,%s", mappingTreeDN(suffix))
+}
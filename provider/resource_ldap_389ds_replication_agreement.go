@@ -0,0 +1,265 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLDAP389DSReplicationAgreement() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAP389DSReplicationAgreementCreate,
+		Read:   resourceLDAP389DSReplicationAgreementRead,
+		Update: resourceLDAP389DSReplicationAgreementUpdate,
+		Delete: resourceLDAP389DSReplicationAgreementDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the agreement.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"suffix": {
+				Type:        schema.TypeString,
+				Description: "The replicated suffix; a replica must already be configured for it (see ldap_389ds_replica).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host": {
+				Type:        schema.TypeString,
+				Description: "The host name of the server receiving the updates.",
+				Required:    true,
+			},
+			"port": {
+				Type:         schema.TypeInt,
+				Description:  "The port of the server receiving the updates.",
+				Optional:     true,
+				Default:      389,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"transport": {
+				Type:         schema.TypeString,
+				Description:  "The connection type: LDAP, SSL (LDAPS) or StartTLS.",
+				Optional:     true,
+				Default:      "LDAP",
+				ValidateFunc: validation.StringInSlice([]string{"LDAP", "SSL", "StartTLS"}, false),
+			},
+			"bind_method": {
+				Type:         schema.TypeString,
+				Description:  "The bind method: SIMPLE, SSLCLIENTAUTH, SASL/GSSAPI or SASL/DIGEST-MD5.",
+				Optional:     true,
+				Default:      "SIMPLE",
+				ValidateFunc: validation.StringInSlice([]string{"SIMPLE", "SSLCLIENTAUTH", "SASL/GSSAPI", "SASL/DIGEST-MD5"}, false),
+			},
+			"bind_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN used to bind to the remote server.",
+				Optional:    true,
+			},
+			"bind_password": {
+				Type:        schema.TypeString,
+				Description: "The password used to bind to the remote server; the server stores it encrypted, so changes made outside of Terraform are not detected.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the agreement is active.",
+				Optional:    true,
+				Default:     true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "A free-form description of the agreement.",
+				Optional:    true,
+			},
+			"excluded_attributes": {
+				Type:        schema.TypeSet,
+				Description: "Attributes excluded from incremental updates (fractional replication).",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"schedule": {
+				Type:        schema.TypeString,
+				Description: "The replication schedule (e.g. \"0000-2359 0123456\"); replication is continuous when unset.",
+				Optional:    true,
+			},
+			"initialize": {
+				Type:        schema.TypeBool,
+				Description: "Whether to start a total update of the remote replica when the agreement is created.",
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the agreement entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAP389DSReplicationAgreementCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+	suffix := d.Get("suffix").(string)
+	dn := fmt.Sprintf("cn=%s,%s", ldap.EscapeDN(name), replicaDN(suffix))
+
+	log.Printf("[DEBUG] ldap_389ds_replication_agreement::create - creating agreement %q", dn)
+
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{"top", "nsds5replicationagreement"})
+	request.Attribute("cn", []string{name})
+	request.Attribute("nsDS5ReplicaRoot", []string{suffix})
+	for attribute, values := range replicationAgreementAttributes(d) {
+		if len(values) > 0 {
+			request.Attribute(attribute, values)
+		}
+	}
+	if d.Get("initialize").(bool) {
+		request.Attribute("nsds5BeginReplicaRefresh", []string{"start"})
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_389ds_replication_agreement::create - error creating agreement %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAP389DSReplicationAgreementRead(d, meta)
+}
+
+func resourceLDAP389DSReplicationAgreementRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_389ds_replication_agreement::read - reading agreement %q", dn)
+
+	entry, err := readConfigEntry(client, dn,
+		"cn",
+		"nsDS5ReplicaRoot",
+		"nsDS5ReplicaHost",
+		"nsDS5ReplicaPort",
+		"nsDS5ReplicaTransportInfo",
+		"nsDS5ReplicaBindMethod",
+		"nsDS5ReplicaBindDN",
+		"nsds5ReplicaEnabled",
+		"description",
+		"nsDS5ReplicatedAttributeList",
+		"nsds5ReplicaUpdateSchedule",
+	)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_389ds_replication_agreement::read - agreement %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	port, _ := strconv.Atoi(entry.GetEqualFoldAttributeValue("nsDS5ReplicaPort"))
+	// the attribute list has the form "(objectclass=*) $ EXCLUDE attr1 attr2"
+	excluded := []string{}
+	if list := entry.GetEqualFoldAttributeValue("nsDS5ReplicatedAttributeList"); list != "" {
+		if i := strings.Index(strings.ToUpper(list), "EXCLUDE"); i >= 0 {
+			excluded = strings.Fields(list[i+len("EXCLUDE"):])
+		}
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	d.Set("suffix", entry.GetEqualFoldAttributeValue("nsDS5ReplicaRoot"))
+	d.Set("host", entry.GetEqualFoldAttributeValue("nsDS5ReplicaHost"))
+	d.Set("port", port)
+	d.Set("transport", entry.GetEqualFoldAttributeValue("nsDS5ReplicaTransportInfo"))
+	d.Set("bind_method", entry.GetEqualFoldAttributeValue("nsDS5ReplicaBindMethod"))
+	d.Set("bind_dn", entry.GetEqualFoldAttributeValue("nsDS5ReplicaBindDN"))
+	d.Set("enabled", !strings.EqualFold(entry.GetEqualFoldAttributeValue("nsds5ReplicaEnabled"), "off"))
+	d.Set("description", entry.GetAttributeValue("description"))
+	d.Set("excluded_attributes", excluded)
+	d.Set("schedule", entry.GetEqualFoldAttributeValue("nsds5ReplicaUpdateSchedule"))
+	return nil
+}
+
+func resourceLDAP389DSReplicationAgreementUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_replication_agreement::update - performing update on %q", d.Id())
+
+	keys := map[string]string{
+		"host":                "nsDS5ReplicaHost",
+		"port":                "nsDS5ReplicaPort",
+		"transport":           "nsDS5ReplicaTransportInfo",
+		"bind_method":         "nsDS5ReplicaBindMethod",
+		"bind_dn":             "nsDS5ReplicaBindDN",
+		"bind_password":       "nsDS5ReplicaCredentials",
+		"enabled":             "nsds5ReplicaEnabled",
+		"description":         "description",
+		"excluded_attributes": "nsDS5ReplicatedAttributeList",
+		"schedule":            "nsds5ReplicaUpdateSchedule",
+	}
+	attributes := replicationAgreementAttributes(d)
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	for key, attribute := range keys {
+		if d.HasChange(key) {
+			modify.Replace(attribute, attributes[attribute])
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_389ds_replication_agreement::update - error updating agreement %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAP389DSReplicationAgreementRead(d, meta)
+}
+
+func resourceLDAP389DSReplicationAgreementDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_389ds_replication_agreement::delete - removing agreement %q", d.Id())
+
+	if err := client.Del(ldap.NewDelRequest(d.Id(), nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_389ds_replication_agreement::delete - error removing agreement %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// replicationAgreementAttributes maps the arguments of the resource to the
+// attributes of the agreement entry; unset arguments map to no values.
+func replicationAgreementAttributes(d *schema.ResourceData) map[string][]string {
+	optional := func(key string) []string {
+		if v := d.Get(key).(string); v != "" {
+			return []string{v}
+		}
+		return []string{}
+	}
+	excluded := toStringSlice(d.Get("excluded_attributes").(*schema.Set).List())
+	attributeList := []string{}
+	if len(excluded) > 0 {
+		attributeList = append(attributeList, fmt.Sprintf("(objectclass=*) $ EXCLUDE %s", strings.Join(excluded, " ")))
+	}
+	return map[string][]string{
+		"nsDS5ReplicaHost":             {d.Get("host").(string)},
+		"nsDS5ReplicaPort":             {strconv.Itoa(d.Get("port").(int))},
+		"nsDS5ReplicaTransportInfo":    {d.Get("transport").(string)},
+		"nsDS5ReplicaBindMethod":       {d.Get("bind_method").(string)},
+		"nsDS5ReplicaBindDN":           optional("bind_dn"),
+		"nsDS5ReplicaCredentials":      optional("bind_password"),
+		"nsds5ReplicaEnabled":          {pluginEnabledValue(d.Get("enabled").(bool))},
+		"description":                  optional("description"),
+		"nsDS5ReplicatedAttributeList": attributeList,
+		"nsds5ReplicaUpdateSchedule":   optional("schedule"),
+	}
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPADForeignSecurityPrincipal() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPADForeignSecurityPrincipalCreate,
+		Read:   resourceLDAPADForeignSecurityPrincipalRead,
+		Update: resourceLDAPADForeignSecurityPrincipalUpdate,
+		Delete: resourceLDAPADForeignSecurityPrincipalDelete,
+
+		Schema: map[string]*schema.Schema{
+			"sid": {
+				Type:        schema.TypeString,
+				Description: "The textual SID (e.g. S-1-5-21-...) of the principal in the trusted forest.",
+				Required:    true,
+				ForceNew:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					if _, err := util.ParseSID(v.(string)); err != nil {
+						es = append(es, fmt.Errorf("%q: %v", k, err))
+					}
+					return
+				},
+			},
+			"domain_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the domain whose CN=ForeignSecurityPrincipals container holds the principal.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"groups": {
+				Type:        schema.TypeSet,
+				Description: "The DNs of the groups the foreign security principal is a member of.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Required:    true,
+				MinItems:    1,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the foreign security principal object.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPADForeignSecurityPrincipalCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	sid := d.Get("sid").(string)
+
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::create - adding %q to groups", sid)
+
+	// the domain controller creates the foreign security principal object on
+	// the fly the first time the SID is added to a group using the <SID=...>
+	// form, so we only need to look it up afterwards
+	for _, group := range (d.Get("groups").(*schema.Set)).List() {
+		if err := addForeignSecurityPrincipalToGroup(client, fmt.Sprintf("<SID=%s>", sid), group.(string)); err != nil {
+			return err
+		}
+	}
+
+	dn, err := lookupForeignSecurityPrincipal(client, d.Get("domain_dn").(string), sid)
+	if err != nil {
+		return err
+	}
+	if dn == "" {
+		return fmt.Errorf("foreign security principal for %q not found after adding it to groups", sid)
+	}
+
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::create - %q resolved to %q", sid, dn)
+
+	d.SetId(dn)
+	return resourceLDAPADForeignSecurityPrincipalRead(d, meta)
+}
+
+func resourceLDAPADForeignSecurityPrincipalRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	sid := d.Get("sid").(string)
+
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::read - looking for %q", sid)
+
+	dn, err := lookupForeignSecurityPrincipal(client, d.Get("domain_dn").(string), sid)
+	if err != nil {
+		return err
+	}
+	if dn == "" {
+		log.Printf("[WARN] ldap_ad_foreign_security_principal::read - %q not found, removing from state", sid)
+		d.SetId("")
+		return nil
+	}
+	d.SetId(dn)
+	d.Set("dn", dn)
+
+	// only check the groups we know about, so that memberships managed
+	// outside of this resource do not show up as a diff
+	groups := []string{}
+	for _, group := range (d.Get("groups").(*schema.Set)).List() {
+		request := ldap.NewSearchRequest(
+			group.(string),
+			ldap.ScopeBaseObject,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			fmt.Sprintf("(member=%s)", ldap.EscapeFilter(dn)),
+			[]string{"dn"},
+			nil,
+		)
+		sr, err := client.Search(request)
+		if err != nil {
+			if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+				log.Printf("[WARN] ldap_ad_foreign_security_principal::read - group %q no longer exists", group)
+				continue
+			}
+			return err
+		}
+		if len(sr.Entries) > 0 {
+			groups = append(groups, group.(string))
+		}
+	}
+	return d.Set("groups", groups)
+}
+
+func resourceLDAPADForeignSecurityPrincipalUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::update - performing update on %q", d.Id())
+
+	if d.HasChange("groups") {
+		o, n := d.GetChange("groups")
+		for _, group := range o.(*schema.Set).Difference(n.(*schema.Set)).List() {
+			if err := removeForeignSecurityPrincipalFromGroup(client, d.Id(), group.(string)); err != nil {
+				return err
+			}
+		}
+		for _, group := range n.(*schema.Set).Difference(o.(*schema.Set)).List() {
+			if err := addForeignSecurityPrincipalToGroup(client, d.Id(), group.(string)); err != nil {
+				return err
+			}
+		}
+	}
+	return resourceLDAPADForeignSecurityPrincipalRead(d, meta)
+}
+
+func resourceLDAPADForeignSecurityPrincipalDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::delete - removing %q from its groups", d.Id())
+
+	// the principal object itself is owned by the domain controller: we only
+	// drop the memberships we manage
+	for _, group := range (d.Get("groups").(*schema.Set)).List() {
+		if err := removeForeignSecurityPrincipalFromGroup(client, d.Id(), group.(string)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupForeignSecurityPrincipal searches the ForeignSecurityPrincipals
+// container of the given domain for the object carrying the given SID,
+// returning its DN or an empty string if there is no such object.
+func lookupForeignSecurityPrincipal(client ldap.Client, domainDN, sid string) (string, error) {
+	b, err := util.ParseSID(sid)
+	if err != nil {
+		return "", err
+	}
+
+	request := ldap.NewSearchRequest(
+		fmt.Sprintf("CN=ForeignSecurityPrincipals,%s", domainDN),
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf("(&(objectClass=foreignSecurityPrincipal)(objectSid=%s))", util.EscapeFilterBytes(b)),
+		[]string{"dn"},
+		nil,
+	)
+
+	sr, err := client.Search(request)
+	if err != nil {
+		return "", err
+	}
+	if len(sr.Entries) == 0 {
+		return "", nil
+	}
+	return sr.Entries[0].DN, nil
+}
+
+func addForeignSecurityPrincipalToGroup(client ldap.Client, member, group string) error {
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::add - adding %q to %q", member, group)
+
+	modify := ldap.NewModifyRequest(group, []ldap.Control{})
+	modify.Add("member", []string{member})
+	err := client.Modify(modify)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultEntryAlreadyExists || err.ResultCode == ldap.LDAPResultAttributeOrValueExists) {
+			// already a member: Active Directory reports it as an existing
+			// entry, other servers as an existing value
+			return nil
+		}
+		log.Printf("[ERROR] ldap_ad_foreign_security_principal::add - error adding %q to %q: %v", member, group, err)
+		return err
+	}
+	return nil
+}
+
+func removeForeignSecurityPrincipalFromGroup(client ldap.Client, member, group string) error {
+	log.Printf("[DEBUG] ldap_ad_foreign_security_principal::remove - removing %q from %q", member, group)
+
+	modify := ldap.NewModifyRequest(group, []ldap.Control{})
+	modify.Delete("member", []string{member})
+	err := client.Modify(modify)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultNoSuchObject || err.ResultCode == ldap.LDAPResultNoSuchAttribute || err.ResultCode == ldap.LDAPResultUnwillingToPerform) {
+			// group is gone or the principal is not a member anymore
+			return nil
+		}
+		log.Printf("[ERROR] ldap_ad_foreign_security_principal::remove - error removing %q from %q: %v", member, group, err)
+		return err
+	}
+	return nil
+}
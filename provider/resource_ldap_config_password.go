@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLDAPConfigPassword() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPConfigPasswordCreate,
+		Read:   resourceLDAPConfigPasswordRead,
+		Update: resourceLDAPConfigPasswordUpdate,
+		Delete: resourceLDAPConfigPasswordDelete,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the configuration entry (e.g. olcDatabase={1}mdb,cn=config for OpenLDAP, cn=config for 389-ds).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"attribute": {
+				Type:        schema.TypeString,
+				Description: "The password attribute (e.g. olcRootPW, nsslapd-rootpw).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Description: "The cleartext password; only its salted hash is sent to the server and kept in the state.",
+				Required:    true,
+				Sensitive:   true,
+				// the state holds the hash, which matches the configured
+				// password as long as neither of them changed
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return util.VerifyPassword(old, new)
+				},
+			},
+			"scheme": {
+				Type:         schema.TypeString,
+				Description:  fmt.Sprintf("The hashing scheme: %s; OpenLDAP needs the pw-sha2, pw-pbkdf2 or pw-argon2 module for anything but SSHA and CRYPT.", strings.Join(util.PasswordSchemes, ", ")),
+				Optional:     true,
+				Default:      "SSHA",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(util.PasswordSchemes, false),
+			},
+		},
+	}
+}
+
+func resourceLDAPConfigPasswordCreate(d *schema.ResourceData, meta interface{}) error {
+	dn := d.Get("dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_config_password::create - setting %q of %q", attribute, dn)
+
+	if err := setConfigPassword(d, meta.(ldap.Client)); err != nil {
+		log.Printf("[ERROR] ldap_config_password::create - error setting %q of %q: %v", attribute, dn, err)
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", dn, attribute))
+	return resourceLDAPConfigPasswordRead(d, meta)
+}
+
+func resourceLDAPConfigPasswordRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_config_password::read - reading %q of %q", attribute, dn)
+
+	entry, err := readConfigEntry(client, dn, attribute)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_config_password::read - %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// a hash that does not verify against the configured password, or is in
+	// a scheme we cannot verify, shows up as a change of the password
+	hashed := entry.GetEqualFoldAttributeValue(attribute)
+	if hashed == "" {
+		log.Printf("[WARN] ldap_config_password::read - %q of %q not set, removing from state", attribute, dn)
+		d.SetId("")
+		return nil
+	}
+	d.Set("password", hashed)
+	if i := strings.Index(hashed, "}"); strings.HasPrefix(hashed, "{") && i > 0 {
+		d.Set("scheme", strings.ToUpper(hashed[1:i]))
+	}
+	return nil
+}
+
+func resourceLDAPConfigPasswordUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] ldap_config_password::update - performing update on %q", d.Id())
+
+	if d.HasChange("password") {
+		if err := setConfigPassword(d, meta.(ldap.Client)); err != nil {
+			log.Printf("[ERROR] ldap_config_password::update - error updating %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPConfigPasswordRead(d, meta)
+}
+
+func resourceLDAPConfigPasswordDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_config_password::delete - removing %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Get("dn").(string), []ldap.Control{})
+	modify.Replace(d.Get("attribute").(string), []string{})
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultNoSuchObject || err.ResultCode == ldap.LDAPResultNoSuchAttribute) {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_config_password::delete - error removing %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+func setConfigPassword(d *schema.ResourceData, client ldap.Client) error {
+	hashed, err := util.HashPassword(d.Get("scheme").(string), d.Get("password").(string))
+	if err != nil {
+		return err
+	}
+	modify := ldap.NewModifyRequest(d.Get("dn").(string), []ldap.Control{})
+	modify.Replace(d.Get("attribute").(string), []string{hashed})
+	return client.Modify(modify)
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the OID of the RFC 2589 Refresh extended operation
+const refreshOID = "1.3.6.1.4.1.1466.101.119.1"
+
+func resourceLDAPDynamicObject() *schema.Resource {
+	// a dynamic object is an ordinary object whose lifetime is bound to a
+	// TTL, so we reuse the ldap_object schema and add the TTL handling on top
+	s := resourceLDAPObject().Schema
+	s["ttl"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Description:  "The time to live of the object in seconds; the server may clamp it to its configured bounds.",
+		Required:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+	}
+	s["refresh_on_read"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Whether to refresh the TTL of the object every time it is read, so that it lives as long as it is planned or applied at least once per ttl; note that refreshing the state (e.g. terraform plan) then writes to the server.",
+		Optional:    true,
+		Default:     false,
+	}
+
+	return &schema.Resource{
+		Create: resourceLDAPDynamicObjectCreate,
+		Read:   resourceLDAPDynamicObjectRead,
+		Update: resourceLDAPDynamicObjectUpdate,
+		Delete: resourceLDAPObjectDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAPObjectImport,
+		},
+
+		CustomizeDiff: resourceLDAPObjectCustomizeDiff,
+
+		Schema: s,
+	}
+}
+
+func resourceLDAPDynamicObjectCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+
+	if !(d.Get("object_classes").(*schema.Set)).Contains("dynamicObject") {
+		return fmt.Errorf("object_classes of %q must include dynamicObject", dn)
+	}
+
+	if err := resourceLDAPObjectCreate(d, meta); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] ldap_dynamic_object::create - setting TTL of %q", dn)
+
+	return refreshLDAPDynamicObject(client, dn, d.Get("ttl").(int))
+}
+
+// resourceLDAPDynamicObjectRead only refreshes the TTL with refresh_on_read,
+// so that plans do not write to the server otherwise; an expired object is
+// removed from state.
+func resourceLDAPDynamicObjectRead(d *schema.ResourceData, meta interface{}) error {
+	if err := readLDAPObject(d, meta, true); err != nil || d.Id() == "" {
+		return err
+	}
+	if !d.Get("refresh_on_read").(bool) {
+		return nil
+	}
+
+	log.Printf("[DEBUG] ldap_dynamic_object::read - refreshing TTL of %q", d.Id())
+
+	return refreshLDAPDynamicObject(meta.(ldap.Client), d.Id(), d.Get("ttl").(int))
+}
+
+func resourceLDAPDynamicObjectUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	if d.HasChanges("object_classes", "attributes") {
+		if err := resourceLDAPObjectUpdate(d, meta); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("ttl") {
+		log.Printf("[DEBUG] ldap_dynamic_object::update - updating TTL of %q", d.Id())
+		if err := refreshLDAPDynamicObject(client, d.Id(), d.Get("ttl").(int)); err != nil {
+			return err
+		}
+	}
+	return readLDAPObject(d, meta, true)
+}
+
+// refreshLDAPDynamicObject sends a Refresh extended request (RFC 2589) for
+// the given dynamic object, asking the server to extend its lifetime by ttl
+// seconds.
+func refreshLDAPDynamicObject(client ldap.Client, dn string, ttl int) error {
+	value := ber.Encode(ber.ClassContext, ber.TypePrimitive, 1, nil, "Extended Request Value: Refresh Request")
+	refresh := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Refresh Request")
+	refresh.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, dn, "Entry Name"))
+	refresh.AppendChild(ber.NewInteger(ber.ClassContext, ber.TypePrimitive, 1, int64(ttl), "Request TTL"))
+	value.AppendChild(refresh)
+
+	_, err := client.Extended(ldap.NewExtendedRequest(refreshOID, value))
+	if err != nil {
+		log.Printf("[ERROR] ldap_dynamic_object::refresh - error refreshing %q: %v", dn, err)
+		return err
+	}
+	log.Printf("[DEBUG] ldap_dynamic_object::refresh - %q refreshed with a TTL of %d seconds", dn, ttl)
+	return nil
+}
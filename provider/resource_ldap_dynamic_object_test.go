@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestLDAPDynamicObjectManagedAttributes(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"dn":                           "cn=session,dc=example,dc=com",
+		"object_classes":               []interface{}{"dynamicObject", "device"},
+		"ttl":                          3600,
+		"read_managed_attributes_only": true,
+		"attributes": []interface{}{
+			map[string]interface{}{"description": "temporary"},
+			map[string]interface{}{"seeAlso": "cn=owner,dc=example,dc=com"},
+		},
+	})
+	diff, err := resourceLDAPDynamicObject().Diff(context.Background(), nil, config, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	names := []string{}
+	for key, attribute := range diff.Attributes {
+		if strings.HasPrefix(key, "managed_attributes.") && key != "managed_attributes.#" {
+			names = append(names, attribute.New)
+		}
+	}
+	sort.Strings(names)
+	if expected := []string{"description", "seeAlso"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected the configured attributes %v to be planned as managed, got %v", expected, names)
+	}
+}
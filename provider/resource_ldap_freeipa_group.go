@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the attributes of FreeIPA groups, by argument name
+var ipaGroupAttributes = map[string]string{
+	"description": "description",
+	"gid_number":  "gidNumber",
+}
+
+func resourceLDAPFreeIPAGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPFreeIPAGroupCreate,
+		Read:   resourceLDAPFreeIPAGroupRead,
+		Update: resourceLDAPFreeIPAGroupUpdate,
+		Delete: resourceLDAPFreeIPAGroupDelete,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The base DN of the FreeIPA domain (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the group.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Description:  "The type of the group: posix, nonposix or external (for trusted domain members).",
+				Optional:     true,
+				Default:      "posix",
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"posix", "nonposix", "external"}, false),
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the group.",
+				Optional:    true,
+			},
+			"gid_number": {
+				Type:        schema.TypeInt,
+				Description: "The GID number of a posix group; assigned from the ID range of the domain when unset.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"users": {
+				Type:        schema.TypeSet,
+				Description: "The logins of the direct user members.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"groups": {
+				Type:        schema.TypeSet,
+				Description: "The names of the direct group members; their members are members of this group too, and appear as such in the compat tree.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the group entry.",
+				Computed:    true,
+			},
+			"ipa_unique_id": {
+				Type:        schema.TypeString,
+				Description: "The unique ID assigned by FreeIPA.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPFreeIPAGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+	dn := ipaGroupDN(d.Get("base_dn").(string), name)
+
+	log.Printf("[DEBUG] ldap_freeipa_group::create - creating group %q", dn)
+
+	objectClasses := []string{"top", "groupOfNames", "nestedGroup", "ipaUserGroup", "ipaObject"}
+	switch d.Get("type").(string) {
+	case "posix":
+		objectClasses = append(objectClasses, "posixGroup")
+	case "external":
+		objectClasses = append(objectClasses, "ipaExternalGroup")
+	}
+
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", objectClasses)
+	request.Attribute("cn", []string{name})
+	request.Attribute("ipaUniqueID", []string{ipaAutogenerate})
+	addIPAAttributes(request, d, ipaGroupAttributes)
+	if _, ok := d.GetOk("gid_number"); !ok && d.Get("type").(string) == "posix" {
+		request.Attribute("gidNumber", []string{ipaAutogenerateID})
+	}
+	if members := ipaGroupMembers(d.Get("base_dn").(string), d.Get("users").(*schema.Set), d.Get("groups").(*schema.Set)); len(members) > 0 {
+		request.Attribute("member", members)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_freeipa_group::create - error creating group %q: %v", dn, err)
+		return explainEntryExists(client, err, "", dn)
+	}
+
+	d.SetId(dn)
+	return resourceLDAPFreeIPAGroupRead(d, meta)
+}
+
+func resourceLDAPFreeIPAGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_freeipa_group::read - reading group %q", dn)
+
+	entry, err := readConfigEntry(client, dn, ipaAttributeNames(ipaGroupAttributes, "cn", "ipaUniqueID", "member")...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_freeipa_group::read - group %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// only the users and groups of the domain are ours, other members (e.g.
+	// services or hosts added elsewhere) are left alone
+	baseDN := d.Get("base_dn").(string)
+	users, groups := []string{}, []string{}
+	for _, member := range entry.GetAttributeValues("member") {
+		if uid, ok := ipaMemberName(member, "uid", fmt.Sprintf("cn=users,cn=accounts,%s", baseDN)); ok {
+			users = append(users, uid)
+		} else if cn, ok := ipaMemberName(member, "cn", fmt.Sprintf("cn=groups,cn=accounts,%s", baseDN)); ok {
+			groups = append(groups, cn)
+		}
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	d.Set("ipa_unique_id", entry.GetEqualFoldAttributeValue("ipaUniqueID"))
+	d.Set("users", matchAttributeNames(users, toStringSlice(d.Get("users").(*schema.Set).List())))
+	d.Set("groups", matchAttributeNames(groups, toStringSlice(d.Get("groups").(*schema.Set).List())))
+	readIPAAttributes(d, entry, ipaGroupAttributes)
+	return nil
+}
+
+func resourceLDAPFreeIPAGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+
+	log.Printf("[DEBUG] ldap_freeipa_group::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modifyIPAAttributes(modify, d, ipaGroupAttributes)
+	if d.HasChanges("users", "groups") {
+		ou, nu := d.GetChange("users")
+		og, ng := d.GetChange("groups")
+		old := ipaGroupMembers(baseDN, ou.(*schema.Set), og.(*schema.Set))
+		new := ipaGroupMembers(baseDN, nu.(*schema.Set), ng.(*schema.Set))
+		if removed := stringSetDifference(old, new); len(removed) > 0 {
+			modify.Delete("member", removed)
+		}
+		if added := stringSetDifference(new, old); len(added) > 0 {
+			modify.Add("member", added)
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := modifyInBatches(client, modify); err != nil {
+			log.Printf("[ERROR] ldap_freeipa_group::update - error updating group %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPFreeIPAGroupRead(d, meta)
+}
+
+func resourceLDAPFreeIPAGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	return deleteIPAEntry(meta.(ldap.Client), "ldap_freeipa_group", d.Id())
+}
+
+// ipaGroupMembers returns the member DNs of the given users and groups.
+func ipaGroupMembers(baseDN string, users, groups *schema.Set) []string {
+	members := []string{}
+	for _, uid := range toStringSlice(users.List()) {
+		members = append(members, ipaUserDN(baseDN, uid))
+	}
+	for _, name := range toStringSlice(groups.List()) {
+		members = append(members, ipaGroupDN(baseDN, name))
+	}
+	return members
+}
+
+// stringSetDifference returns the values of a that are not in b, ignoring
+// case; b is hashed, so that the member lists of large groups are compared
+// in linear time.
+func stringSetDifference(a, b []string) []string {
+	folded := make(map[string]struct{}, len(b))
+	for _, w := range b {
+		folded[strings.ToLower(w)] = struct{}{}
+	}
+	result := []string{}
+	for _, v := range a {
+		if _, ok := folded[strings.ToLower(v)]; !ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the attributes of FreeIPA HBAC rules, by argument name
+var ipaHBACRuleAttributes = map[string]string{
+	"description":      "description",
+	"enabled":          "ipaEnabledFlag",
+	"user_category":    "userCategory",
+	"host_category":    "hostCategory",
+	"service_category": "serviceCategory",
+}
+
+// the member attributes of FreeIPA HBAC rules and the kinds of entries they
+// link to
+var ipaHBACRuleMembers = map[string][]ipaMemberKind{
+	"memberUser": {
+		{key: "users", attribute: "uid", container: "cn=users,cn=accounts"},
+		{key: "groups", attribute: "cn", container: "cn=groups,cn=accounts"},
+	},
+	"memberHost": {
+		{key: "hosts", attribute: "fqdn", container: "cn=computers,cn=accounts"},
+		{key: "host_groups", attribute: "cn", container: "cn=hostgroups,cn=accounts"},
+	},
+	"memberService": {
+		{key: "services", attribute: "cn", container: "cn=hbacservices,cn=hbac"},
+		{key: "service_groups", attribute: "cn", container: "cn=hbacservicegroups,cn=hbac"},
+	},
+}
+
+func resourceLDAPFreeIPAHBACRule() *schema.Resource {
+	category := func(description string, conflicts ...string) *schema.Schema {
+		return &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   description,
+			Optional:      true,
+			ValidateFunc:  validation.StringInSlice([]string{"all"}, false),
+			ConflictsWith: conflicts,
+		}
+	}
+	names := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: description,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+			Optional:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Create: resourceLDAPFreeIPAHBACRuleCreate,
+		Read:   resourceLDAPFreeIPAHBACRuleRead,
+		Update: resourceLDAPFreeIPAHBACRuleUpdate,
+		Delete: resourceLDAPFreeIPAHBACRuleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The base DN of the FreeIPA domain (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the rule.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the rule.",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the rule is enforced.",
+				Optional:    true,
+				Default:     true,
+			},
+			"user_category":    category("Set to \"all\" for the rule to apply to every user.", "users", "groups"),
+			"users":            names("The logins of the users the rule applies to."),
+			"groups":           names("The names of the user groups the rule applies to."),
+			"host_category":    category("Set to \"all\" for the rule to apply to every host.", "hosts", "host_groups"),
+			"hosts":            names("The FQDNs of the hosts the rule grants access to."),
+			"host_groups":      names("The names of the host groups the rule grants access to."),
+			"service_category": category("Set to \"all\" for the rule to apply to every service.", "services", "service_groups"),
+			"services":         names("The names of the HBAC services (e.g. sshd) the rule grants access to."),
+			"service_groups":   names("The names of the HBAC service groups the rule grants access to."),
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the rule entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPFreeIPAHBACRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+	containerDN := fmt.Sprintf("cn=hbac,%s", d.Get("base_dn").(string))
+
+	log.Printf("[DEBUG] ldap_freeipa_hbac_rule::create - creating rule %q", name)
+
+	// the UUID plugin replaces the magic RDN with the ID it assigns
+	request := ldap.NewAddRequest(fmt.Sprintf("ipaUniqueID=%s,%s", ipaAutogenerate, containerDN), []ldap.Control{})
+	request.Attribute("objectClass", []string{"ipaAssociation", "ipaHBACRule"})
+	request.Attribute("cn", []string{name})
+	request.Attribute("accessRuleType", []string{"allow"})
+	addIPAAttributes(request, d, ipaHBACRuleAttributes)
+	addIPAMembers(request, d, ipaHBACRuleMembers)
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_freeipa_hbac_rule::create - error creating rule %q: %v", name, err)
+		return err
+	}
+
+	dn, err := searchIPAEntry(client, containerDN, "ipaHBACRule", name)
+	if err != nil {
+		return err
+	}
+	d.SetId(dn)
+	return resourceLDAPFreeIPAHBACRuleRead(d, meta)
+}
+
+func resourceLDAPFreeIPAHBACRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_freeipa_hbac_rule::read - reading rule %q", dn)
+
+	attributes := append(ipaAttributeNames(ipaHBACRuleAttributes, "cn"), ipaMemberAttributeNames(ipaHBACRuleMembers)...)
+	entry, err := readConfigEntry(client, dn, attributes...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_freeipa_hbac_rule::read - rule %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	readIPAAttributes(d, entry, ipaHBACRuleAttributes)
+	readIPAMembers(d, entry, ipaHBACRuleMembers)
+	return nil
+}
+
+func resourceLDAPFreeIPAHBACRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_freeipa_hbac_rule::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modifyIPAAttributes(modify, d, ipaHBACRuleAttributes)
+	modifyIPAMembers(modify, d, ipaHBACRuleMembers)
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_freeipa_hbac_rule::update - error updating rule %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPFreeIPAHBACRuleRead(d, meta)
+}
+
+func resourceLDAPFreeIPAHBACRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	return deleteIPAEntry(meta.(ldap.Client), "ldap_freeipa_hbac_rule", d.Id())
+}
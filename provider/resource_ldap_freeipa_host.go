@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the attributes of FreeIPA hosts, by argument name
+var ipaHostAttributes = map[string]string{
+	"description":     "description",
+	"locality":        "l",
+	"location":        "nsHostLocation",
+	"platform":        "nsHardwarePlatform",
+	"os":              "nsOsVersion",
+	"user_classes":    "userClass",
+	"mac_addresses":   "macAddress",
+	"ssh_public_keys": "ipaSshPubKey",
+}
+
+func resourceLDAPFreeIPAHost() *schema.Resource {
+	names := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: description,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+			Optional:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Create: resourceLDAPFreeIPAHostCreate,
+		Read:   resourceLDAPFreeIPAHostRead,
+		Update: resourceLDAPFreeIPAHostUpdate,
+		Delete: resourceLDAPFreeIPAHostDelete,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The base DN of the FreeIPA domain (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"fqdn": {
+				Type:        schema.TypeString,
+				Description: "The fully qualified domain name of the host.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the host.",
+				Optional:    true,
+			},
+			"locality": {
+				Type:        schema.TypeString,
+				Description: "The locality of the host (e.g. Baltimore, MD).",
+				Optional:    true,
+			},
+			"location": {
+				Type:        schema.TypeString,
+				Description: "The physical location of the host (e.g. Lab 2).",
+				Optional:    true,
+			},
+			"platform": {
+				Type:        schema.TypeString,
+				Description: "The hardware platform of the host.",
+				Optional:    true,
+			},
+			"os": {
+				Type:        schema.TypeString,
+				Description: "The operating system of the host.",
+				Optional:    true,
+			},
+			"user_classes":    names("The user classes of the host, e.g. for automember rules."),
+			"mac_addresses":   names("The MAC addresses of the host."),
+			"ssh_public_keys": names("The SSH host keys of the host."),
+			"managed_by":      names("The FQDNs of the other hosts allowed to manage this host's keytab and certificates; a host always manages itself."),
+			"enrollment_password": {
+				Type:        schema.TypeString,
+				Description: "A one-time password for enrolling the host with ipa-client-install --password; the server clears it on enrollment, so it is never read back.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"realm": {
+				Type:        schema.TypeString,
+				Description: "The Kerberos realm of the host principal; read from the domain when unset.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the host entry.",
+				Computed:    true,
+			},
+			"ipa_unique_id": {
+				Type:        schema.TypeString,
+				Description: "The unique ID assigned by FreeIPA.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPFreeIPAHostCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+	fqdn := d.Get("fqdn").(string)
+	dn := ipaHostDN(baseDN, fqdn)
+
+	realm := d.Get("realm").(string)
+	if realm == "" {
+		var err error
+		if realm, err = ipaRealm(client, baseDN); err != nil {
+			return err
+		}
+		d.Set("realm", realm)
+	}
+
+	log.Printf("[DEBUG] ldap_freeipa_host::create - creating host %q", dn)
+
+	principal := fmt.Sprintf("host/%s@%s", fqdn, realm)
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{
+		"top",
+		"ipaObject",
+		"nsHost",
+		"ipaHost",
+		"pkiUser",
+		"ipaService",
+		"krbPrincipalAux",
+		"krbPrincipal",
+		"ieee802Device",
+		"ipaSshHost",
+		"ipaSshGroupOfPubKeys",
+	})
+	request.Attribute("fqdn", []string{fqdn})
+	request.Attribute("cn", []string{fqdn})
+	request.Attribute("serverHostName", []string{strings.SplitN(fqdn, ".", 2)[0]})
+	request.Attribute("krbPrincipalName", []string{principal})
+	request.Attribute("krbCanonicalName", []string{principal})
+	request.Attribute("ipaUniqueID", []string{ipaAutogenerate})
+	request.Attribute("managedBy", ipaHostManagedBy(d))
+	addIPAAttributes(request, d, ipaHostAttributes)
+	if v, ok := d.GetOk("enrollment_password"); ok {
+		request.Attribute("userPassword", []string{v.(string)})
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_freeipa_host::create - error creating host %q: %v", dn, err)
+		return explainEntryExists(client, err, "", dn)
+	}
+
+	d.SetId(dn)
+	return resourceLDAPFreeIPAHostRead(d, meta)
+}
+
+func resourceLDAPFreeIPAHostRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+	baseDN := d.Get("base_dn").(string)
+
+	log.Printf("[DEBUG] ldap_freeipa_host::read - reading host %q", dn)
+
+	entry, err := readConfigEntry(client, dn, ipaAttributeNames(ipaHostAttributes, "fqdn", "ipaUniqueID", "managedBy")...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_freeipa_host::read - host %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	fqdn := entry.GetEqualFoldAttributeValue("fqdn")
+	managedBy := []string{}
+	for _, manager := range entry.GetEqualFoldAttributeValues("managedBy") {
+		if name, ok := ipaMemberName(manager, "fqdn", fmt.Sprintf("cn=computers,cn=accounts,%s", baseDN)); ok && !strings.EqualFold(name, fqdn) {
+			managedBy = append(managedBy, name)
+		}
+	}
+
+	d.Set("dn", dn)
+	d.Set("fqdn", fqdn)
+	d.Set("ipa_unique_id", entry.GetEqualFoldAttributeValue("ipaUniqueID"))
+	d.Set("managed_by", matchAttributeNames(managedBy, toStringSlice(d.Get("managed_by").(*schema.Set).List())))
+	readIPAAttributes(d, entry, ipaHostAttributes)
+	return nil
+}
+
+func resourceLDAPFreeIPAHostUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_freeipa_host::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modifyIPAAttributes(modify, d, ipaHostAttributes)
+	if d.HasChange("managed_by") {
+		modify.Replace("managedBy", ipaHostManagedBy(d))
+	}
+	if d.HasChange("enrollment_password") {
+		modify.Replace("userPassword", ipaValues(d, "enrollment_password"))
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_freeipa_host::update - error updating host %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPFreeIPAHostRead(d, meta)
+}
+
+func resourceLDAPFreeIPAHostDelete(d *schema.ResourceData, meta interface{}) error {
+	return deleteIPAEntry(meta.(ldap.Client), "ldap_freeipa_host", d.Id())
+}
+
+func ipaHostDN(baseDN, fqdn string) string {
+	return fmt.Sprintf("fqdn=%s,cn=computers,cn=accounts,%s", ldap.EscapeDN(fqdn), baseDN)
+}
+
+// ipaHostManagedBy returns the DNs of the hosts managing a host: the host
+// itself, as IPA expects, and the configured ones.
+func ipaHostManagedBy(d *schema.ResourceData) []string {
+	baseDN := d.Get("base_dn").(string)
+	managedBy := []string{ipaHostDN(baseDN, d.Get("fqdn").(string))}
+	for _, fqdn := range toStringSlice(d.Get("managed_by").(*schema.Set).List()) {
+		managedBy = append(managedBy, ipaHostDN(baseDN, fqdn))
+	}
+	return managedBy
+}
@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the attributes of FreeIPA sudo rules, by argument name
+var ipaSudoRuleAttributes = map[string]string{
+	"description":           "description",
+	"enabled":               "ipaEnabledFlag",
+	"order":                 "sudoOrder",
+	"options":               "ipaSudoOpt",
+	"user_category":         "userCategory",
+	"host_category":         "hostCategory",
+	"command_category":      "cmdCategory",
+	"run_as_user_category":  "ipaSudoRunAsUserCategory",
+	"run_as_group_category": "ipaSudoRunAsGroupCategory",
+}
+
+// the member attributes of FreeIPA sudo rules and the kinds of entries they
+// link to
+var ipaSudoRuleMembers = map[string][]ipaMemberKind{
+	"memberUser": {
+		{key: "users", attribute: "uid", container: "cn=users,cn=accounts"},
+		{key: "groups", attribute: "cn", container: "cn=groups,cn=accounts"},
+	},
+	"memberHost": {
+		{key: "hosts", attribute: "fqdn", container: "cn=computers,cn=accounts"},
+		{key: "host_groups", attribute: "cn", container: "cn=hostgroups,cn=accounts"},
+	},
+	"ipaSudoRunAs": {
+		{key: "run_as_users", attribute: "uid", container: "cn=users,cn=accounts"},
+		{key: "run_as_user_groups", attribute: "cn", container: "cn=groups,cn=accounts"},
+	},
+	"ipaSudoRunAsGroup": {
+		{key: "run_as_groups", attribute: "cn", container: "cn=groups,cn=accounts"},
+	},
+}
+
+// the command attributes of FreeIPA sudo rules: sudo commands are named by
+// their ipaUniqueID, so they are looked up by command rather than mapped to a
+// DN like the other members
+var ipaSudoRuleCommands = map[string]struct {
+	key    string
+	groups ipaMemberKind
+}{
+	"memberAllowCmd": {"allow_commands", ipaMemberKind{key: "allow_command_groups", attribute: "cn", container: "cn=sudocmdgroups,cn=sudo"}},
+	"memberDenyCmd":  {"deny_commands", ipaMemberKind{key: "deny_command_groups", attribute: "cn", container: "cn=sudocmdgroups,cn=sudo"}},
+}
+
+func resourceLDAPFreeIPASudoRule() *schema.Resource {
+	category := func(description string, conflicts ...string) *schema.Schema {
+		return &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   description,
+			Optional:      true,
+			ValidateFunc:  validation.StringInSlice([]string{"all"}, false),
+			ConflictsWith: conflicts,
+		}
+	}
+	names := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: description,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+			Optional:    true,
+		}
+	}
+
+	return &schema.Resource{
+		Create: resourceLDAPFreeIPASudoRuleCreate,
+		Read:   resourceLDAPFreeIPASudoRuleRead,
+		Update: resourceLDAPFreeIPASudoRuleUpdate,
+		Delete: resourceLDAPFreeIPASudoRuleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The base DN of the FreeIPA domain (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the rule.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the rule.",
+				Optional:    true,
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Description: "Whether the rule is enforced.",
+				Optional:    true,
+				Default:     true,
+			},
+			"order": {
+				Type:        schema.TypeInt,
+				Description: "The order of the rule; rules with a higher order win.",
+				Optional:    true,
+			},
+			"options":               names("The sudo options of the rule (e.g. !authenticate)."),
+			"user_category":         category("Set to \"all\" for the rule to apply to every user.", "users", "groups"),
+			"users":                 names("The logins of the users the rule applies to."),
+			"groups":                names("The names of the user groups the rule applies to."),
+			"host_category":         category("Set to \"all\" for the rule to apply on every host.", "hosts", "host_groups"),
+			"hosts":                 names("The FQDNs of the hosts the rule applies on."),
+			"host_groups":           names("The names of the host groups the rule applies on."),
+			"command_category":      category("Set to \"all\" to allow every command.", "allow_commands", "allow_command_groups"),
+			"allow_commands":        names("The sudo commands allowed by the rule, which must exist in FreeIPA."),
+			"allow_command_groups":  names("The names of the sudo command groups allowed by the rule."),
+			"deny_commands":         names("The sudo commands denied by the rule, which must exist in FreeIPA."),
+			"deny_command_groups":   names("The names of the sudo command groups denied by the rule."),
+			"run_as_user_category":  category("Set to \"all\" to allow running commands as any user.", "run_as_users", "run_as_user_groups"),
+			"run_as_users":          names("The logins of the users commands may be run as."),
+			"run_as_user_groups":    names("The names of the groups whose members commands may be run as."),
+			"run_as_group_category": category("Set to \"all\" to allow running commands as any group.", "run_as_groups"),
+			"run_as_groups":         names("The names of the groups commands may be run as."),
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the rule entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPFreeIPASudoRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+	containerDN := fmt.Sprintf("cn=sudorules,cn=sudo,%s", d.Get("base_dn").(string))
+
+	log.Printf("[DEBUG] ldap_freeipa_sudo_rule::create - creating rule %q", name)
+
+	commands, err := ipaSudoRuleCommandDNs(client, d)
+	if err != nil {
+		return err
+	}
+
+	// the UUID plugin replaces the magic RDN with the ID it assigns
+	request := ldap.NewAddRequest(fmt.Sprintf("ipaUniqueID=%s,%s", ipaAutogenerate, containerDN), []ldap.Control{})
+	request.Attribute("objectClass", []string{"ipaAssociation", "ipaSudoRule"})
+	request.Attribute("cn", []string{name})
+	addIPAAttributes(request, d, ipaSudoRuleAttributes)
+	addIPAMembers(request, d, ipaSudoRuleMembers)
+	for attribute, dns := range commands {
+		if len(dns) > 0 {
+			request.Attribute(attribute, dns)
+		}
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_freeipa_sudo_rule::create - error creating rule %q: %v", name, err)
+		return err
+	}
+
+	dn, err := searchIPAEntry(client, containerDN, "ipaSudoRule", name)
+	if err != nil {
+		return err
+	}
+	d.SetId(dn)
+	return resourceLDAPFreeIPASudoRuleRead(d, meta)
+}
+
+func resourceLDAPFreeIPASudoRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+	baseDN := d.Get("base_dn").(string)
+
+	log.Printf("[DEBUG] ldap_freeipa_sudo_rule::read - reading rule %q", dn)
+
+	attributes := append(ipaAttributeNames(ipaSudoRuleAttributes, "cn"), ipaMemberAttributeNames(ipaSudoRuleMembers)...)
+	for attribute := range ipaSudoRuleCommands {
+		attributes = append(attributes, attribute)
+	}
+	entry, err := readConfigEntry(client, dn, attributes...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_freeipa_sudo_rule::read - rule %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	d.Set("name", entry.GetAttributeValue("cn"))
+	readIPAAttributes(d, entry, ipaSudoRuleAttributes)
+	readIPAMembers(d, entry, ipaSudoRuleMembers)
+
+	var known map[string]string
+	for attribute, link := range ipaSudoRuleCommands {
+		commands, groups := []string{}, []string{}
+		for _, member := range entry.GetEqualFoldAttributeValues(attribute) {
+			if name, ok := ipaMemberName(member, link.groups.attribute, fmt.Sprintf("%s,%s", link.groups.container, baseDN)); ok {
+				groups = append(groups, name)
+				continue
+			}
+			if known == nil {
+				if known, err = searchIPASudoCommands(client, baseDN); err != nil {
+					return err
+				}
+			}
+			for command, commandDN := range known {
+				if strings.EqualFold(commandDN, member) {
+					commands = append(commands, command)
+					break
+				}
+			}
+		}
+		d.Set(link.key, commands)
+		d.Set(link.groups.key, matchAttributeNames(groups, toStringSlice(d.Get(link.groups.key).(*schema.Set).List())))
+	}
+	return nil
+}
+
+func resourceLDAPFreeIPASudoRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_freeipa_sudo_rule::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modifyIPAAttributes(modify, d, ipaSudoRuleAttributes)
+	modifyIPAMembers(modify, d, ipaSudoRuleMembers)
+	if d.HasChanges("allow_commands", "allow_command_groups", "deny_commands", "deny_command_groups") {
+		commands, err := ipaSudoRuleCommandDNs(client, d)
+		if err != nil {
+			return err
+		}
+		for attribute, link := range ipaSudoRuleCommands {
+			if d.HasChanges(link.key, link.groups.key) {
+				modify.Replace(attribute, commands[attribute])
+			}
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_freeipa_sudo_rule::update - error updating rule %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPFreeIPASudoRuleRead(d, meta)
+}
+
+func resourceLDAPFreeIPASudoRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	return deleteIPAEntry(meta.(ldap.Client), "ldap_freeipa_sudo_rule", d.Id())
+}
+
+// ipaSudoRuleCommandDNs returns the values of the command attributes: the DNs
+// of the configured sudo commands and command groups.
+func ipaSudoRuleCommandDNs(client ldap.Client, d *schema.ResourceData) (map[string][]string, error) {
+	baseDN := d.Get("base_dn").(string)
+	var known map[string]string
+	result := map[string][]string{}
+	for attribute, link := range ipaSudoRuleCommands {
+		dns := ipaMemberDNs(d, baseDN, []ipaMemberKind{link.groups})
+		for _, command := range toStringSlice(d.Get(link.key).(*schema.Set).List()) {
+			if known == nil {
+				var err error
+				if known, err = searchIPASudoCommands(client, baseDN); err != nil {
+					return nil, err
+				}
+			}
+			dn, ok := known[command]
+			if !ok {
+				return nil, fmt.Errorf("sudo command %q does not exist in FreeIPA", command)
+			}
+			dns = append(dns, dn)
+		}
+		result[attribute] = dns
+	}
+	return result, nil
+}
+
+// searchIPASudoCommands returns the DNs of the sudo commands of a domain, by
+// command.
+func searchIPASudoCommands(client ldap.Client, baseDN string) (map[string]string, error) {
+	request := ldap.NewSearchRequest(
+		fmt.Sprintf("cn=sudocmds,cn=sudo,%s", baseDN),
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=ipaSudoCmd)",
+		[]string{"sudoCmd"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		return nil, err
+	}
+	commands := map[string]string{}
+	for _, entry := range sr.Entries {
+		commands[entry.GetEqualFoldAttributeValue("sudoCmd")] = entry.DN
+	}
+	return commands, nil
+}
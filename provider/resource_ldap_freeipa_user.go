@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the attributes of FreeIPA users, by argument name
+var ipaUserAttributes = map[string]string{
+	"first_name":      "givenName",
+	"last_name":       "sn",
+	"full_name":       "cn",
+	"display_name":    "displayName",
+	"initials":        "initials",
+	"gecos":           "gecos",
+	"email":           "mail",
+	"title":           "title",
+	"login_shell":     "loginShell",
+	"home_directory":  "homeDirectory",
+	"uid_number":      "uidNumber",
+	"gid_number":      "gidNumber",
+	"ssh_public_keys": "ipaSshPubKey",
+}
+
+func resourceLDAPFreeIPAUser() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPFreeIPAUserCreate,
+		Read:   resourceLDAPFreeIPAUserRead,
+		Update: resourceLDAPFreeIPAUserUpdate,
+		Delete: resourceLDAPFreeIPAUserDelete,
+
+		Schema: map[string]*schema.Schema{
+			"base_dn": {
+				Type:        schema.TypeString,
+				Description: "The base DN of the FreeIPA domain (e.g. dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"uid": {
+				Type:        schema.TypeString,
+				Description: "The login of the user.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"first_name": {
+				Type:        schema.TypeString,
+				Description: "The first name of the user (givenName).",
+				Required:    true,
+			},
+			"last_name": {
+				Type:        schema.TypeString,
+				Description: "The last name of the user (sn).",
+				Required:    true,
+			},
+			"full_name": {
+				Type:        schema.TypeString,
+				Description: "The full name of the user (cn); defaults to the first and last names.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"display_name": {
+				Type:        schema.TypeString,
+				Description: "The display name of the user; defaults to the full name.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"initials": {
+				Type:        schema.TypeString,
+				Description: "The initials of the user; defaults to those of the first and last names.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"gecos": {
+				Type:        schema.TypeString,
+				Description: "The GECOS field; defaults to the full name.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"email": {
+				Type:        schema.TypeSet,
+				Description: "The e-mail addresses of the user.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"title": {
+				Type:        schema.TypeString,
+				Description: "The job title of the user.",
+				Optional:    true,
+			},
+			"login_shell": {
+				Type:        schema.TypeString,
+				Description: "The login shell of the user; defaults to /bin/sh.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"home_directory": {
+				Type:        schema.TypeString,
+				Description: "The home directory of the user; defaults to /home/<uid>.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"uid_number": {
+				Type:        schema.TypeInt,
+				Description: "The UID number of the user; assigned from the ID range of the domain when unset.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"gid_number": {
+				Type:        schema.TypeInt,
+				Description: "The GID number of the primary group of the user; defaults to that of the user private group.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"ssh_public_keys": {
+				Type:        schema.TypeSet,
+				Description: "The SSH public keys of the user.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Description: "The initial password of the user, which FreeIPA expires on first use; it is only set on creation and when changed here.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"realm": {
+				Type:        schema.TypeString,
+				Description: "The Kerberos realm of the user principal; read from the domain when unset.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the user entry.",
+				Computed:    true,
+			},
+			"ipa_unique_id": {
+				Type:        schema.TypeString,
+				Description: "The unique ID assigned by FreeIPA.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPFreeIPAUserCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	baseDN := d.Get("base_dn").(string)
+	uid := d.Get("uid").(string)
+	dn := ipaUserDN(baseDN, uid)
+
+	realm := d.Get("realm").(string)
+	if realm == "" {
+		var err error
+		if realm, err = ipaRealm(client, baseDN); err != nil {
+			return err
+		}
+		d.Set("realm", realm)
+	}
+
+	// the defaults the ipa user-add command would fill in
+	first, last := d.Get("first_name").(string), d.Get("last_name").(string)
+	fullName := fmt.Sprintf("%s %s", first, last)
+	if v, ok := d.GetOk("full_name"); ok {
+		fullName = v.(string)
+	}
+	defaults := map[string]string{
+		"full_name":      fullName,
+		"display_name":   fullName,
+		"gecos":          fullName,
+		"initials":       initial(first) + initial(last),
+		"login_shell":    "/bin/sh",
+		"home_directory": fmt.Sprintf("/home/%s", uid),
+	}
+	for key, value := range defaults {
+		if _, ok := d.GetOk(key); !ok {
+			d.Set(key, value)
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_freeipa_user::create - creating user %q", dn)
+
+	principal := fmt.Sprintf("%s@%s", uid, realm)
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", []string{
+		"top",
+		"person",
+		"organizationalPerson",
+		"inetOrgPerson",
+		"inetUser",
+		"posixAccount",
+		"krbPrincipalAux",
+		"krbTicketPolicyAux",
+		"ipaObject",
+		"ipaSshUser",
+		"ipaSshGroupOfPubKeys",
+		"mepOriginEntry",
+	})
+	request.Attribute("uid", []string{uid})
+	request.Attribute("krbPrincipalName", []string{principal})
+	request.Attribute("krbCanonicalName", []string{principal})
+	request.Attribute("ipaUniqueID", []string{ipaAutogenerate})
+	addIPAAttributes(request, d, ipaUserAttributes)
+	// let the DNA plugin assign the IDs, and the managed entries plugin
+	// create the user private group, unless the IDs are given
+	for key, attribute := range map[string]string{"uid_number": "uidNumber", "gid_number": "gidNumber"} {
+		if _, ok := d.GetOk(key); !ok {
+			request.Attribute(attribute, []string{ipaAutogenerateID})
+		}
+	}
+	if v, ok := d.GetOk("password"); ok {
+		request.Attribute("userPassword", []string{v.(string)})
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_freeipa_user::create - error creating user %q: %v", dn, err)
+		return explainEntryExists(client, err, "", dn)
+	}
+
+	d.SetId(dn)
+	return resourceLDAPFreeIPAUserRead(d, meta)
+}
+
+func resourceLDAPFreeIPAUserRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_freeipa_user::read - reading user %q", dn)
+
+	entry, err := readConfigEntry(client, dn, ipaAttributeNames(ipaUserAttributes, "uid", "ipaUniqueID")...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_freeipa_user::read - user %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	d.Set("uid", entry.GetAttributeValue("uid"))
+	d.Set("ipa_unique_id", entry.GetEqualFoldAttributeValue("ipaUniqueID"))
+	readIPAAttributes(d, entry, ipaUserAttributes)
+	return nil
+}
+
+func resourceLDAPFreeIPAUserUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_freeipa_user::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modifyIPAAttributes(modify, d, ipaUserAttributes)
+	if d.HasChange("password") {
+		modify.Replace("userPassword", ipaValues(d, "password"))
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_freeipa_user::update - error updating user %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPFreeIPAUserRead(d, meta)
+}
+
+func resourceLDAPFreeIPAUserDelete(d *schema.ResourceData, meta interface{}) error {
+	// the managed entries plugin removes the user private group
+	return deleteIPAEntry(meta.(ldap.Client), "ldap_freeipa_user", d.Id())
+}
+
+func initial(name string) string {
+	for _, r := range name {
+		return string(r)
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPIDNumberReservation() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPIDNumberReservationCreate,
+		Read:   resourceLDAPIDNumberReservationRead,
+		Delete: resourceLDAPIDNumberReservationDelete,
+
+		Schema: map[string]*schema.Schema{
+			"counter_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the entry holding the next number, e.g. a sambaUnixIdPool entry or the configuration of a DNA plugin.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"counter_attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute of the counter entry holding the next number (e.g. uidNumber, gidNumber, dnaNextValue).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"min": {
+				Type:        schema.TypeInt,
+				Description: "The lowest number of the range.",
+				Optional:    true,
+				Default:     10000,
+				ForceNew:    true,
+			},
+			"max": {
+				Type:        schema.TypeInt,
+				Description: "The highest number of the range.",
+				Optional:    true,
+				Default:     60000,
+				ForceNew:    true,
+			},
+			"number": {
+				Type:        schema.TypeInt,
+				Description: "The reserved number, taken from the counter entry when the resource is created.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPIDNumberReservationCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("counter_dn").(string)
+	attribute := d.Get("counter_attribute").(string)
+	min, max := d.Get("min").(int), d.Get("max").(int)
+	if min > max {
+		return fmt.Errorf("min (%d) must not be greater than max (%d)", min, max)
+	}
+
+	log.Printf("[DEBUG] ldap_id_number_reservation::create - reserving %s of %q", attribute, dn)
+
+	number, err := readLDAPIDCounter(client, dn, attribute, min, max, true)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s:%d", dn, attribute, number))
+	return d.Set("number", number)
+}
+
+// resourceLDAPIDNumberReservationRead has nothing to read: the number stays
+// reserved whatever the counter holds now.
+func resourceLDAPIDNumberReservationRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceLDAPIDNumberReservationDelete(d *schema.ResourceData, meta interface{}) error {
+	// other numbers may have been taken since, so it is never given back
+	log.Printf("[DEBUG] ldap_id_number_reservation::delete - forgetting %q, the number is not returned to the counter", d.Id())
+	return nil
+}
@@ -4,16 +4,17 @@ package provider
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"hash/crc32"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/trevex/terraform-provider-ldap/util"
 
 	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"golang.org/x/text/encoding/unicode"
 )
 
 func resourceLDAPObject() *schema.Resource {
@@ -22,11 +23,12 @@ func resourceLDAPObject() *schema.Resource {
 		Read:   resourceLDAPObjectRead,
 		Update: resourceLDAPObjectUpdate,
 		Delete: resourceLDAPObjectDelete,
-		Exists: resourceLDAPObjectExists,
 
-		// Importer: &schema.ResourceImporter{
-		// 	State: resourceLDAPObjectImport,
-		// },
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAPObjectImport,
+		},
+
+		CustomizeDiff: resourceLDAPObjectCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"dn": {
@@ -74,50 +76,115 @@ func resourceLDAPObject() *schema.Resource {
 				Set:         schema.HashString,
 				Optional:    true,
 			},
+			"decode_binary_attributes": {
+				Type:        schema.TypeBool,
+				Description: "Whether to read the well-known binary attributes of Active Directory, which are read-only, as text: GUIDs (e.g. objectGUID) and SIDs (e.g. objectSid).",
+				Optional:    true,
+				Default:     false,
+			},
+			"read_managed_attributes_only": {
+				Type:        schema.TypeBool,
+				Description: "Whether to read only the attributes set in attributes (and objectClass) rather than all of them, to refresh objects carrying large attributes populated by the server (e.g. jpegPhoto, memberOf) cheaply; other attributes added outside of Terraform then go unnoticed.",
+				Optional:    true,
+				Default:     false,
+			},
+			"managed_attributes": {
+				Type:        schema.TypeSet,
+				Description: "The names of the attributes set in attributes when read_managed_attributes_only is set, which are read even when the object has no values for them.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
 		},
 	}
 }
 
-func resourceLDAPObjectExists(d *schema.ResourceData, meta interface{}) (b bool, e error) {
-	l := meta.(*ldap.Conn)
-	dn := d.Get("dn").(string)
+// resourceLDAPObjectCustomizeDiff records the names of the configured
+// attributes when only those are read: the refresh is given the prior state
+// alone, which lacks the attributes the object had no values for.
+func resourceLDAPObjectCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("read_managed_attributes_only").(bool) {
+		if d.Get("managed_attributes").(*schema.Set).Len() > 0 {
+			return d.SetNew("managed_attributes", []string{})
+		}
+		return nil
+	}
+	if !d.NewValueKnown("attributes") {
+		return d.SetNewComputed("managed_attributes")
+	}
+	names := []string{}
+	for attribute := range groupAttributeValues(d.Get("attributes").(*schema.Set)) {
+		names = append(names, attribute)
+	}
+	return d.SetNew("managed_attributes", names)
+}
 
-	log.Printf("[DEBUG] ldap_object::exists - checking if %q exists", dn)
+// resourceLDAPObjectImport accepts either the DN of the object or, for Active
+// Directory, its objectGUID; the latter is resolved to the current DN so that
+// the import keeps working if the object has been renamed or moved.
+func resourceLDAPObjectImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(ldap.Client)
+	id := d.Id()
 
-	// search by primary key (that is, set the DN as base DN and use a "base
-	// object" scope); no attributes are retrieved since we are onòy checking
-	// for existence; all objects have an "objectClass" attribute, so the filter
-	// is a "match all"
-	request := ldap.NewSearchRequest(
-		dn,
-		ldap.ScopeBaseObject,
-		ldap.NeverDerefAliases,
-		0,
-		0,
-		false,
-		"(objectClass=*)",
-		nil,
-		nil,
-	)
+	if guid, err := util.ParseGUID(id); err == nil {
+		log.Printf("[DEBUG] ldap_object::import - looking up object with GUID %q", id)
+		dn, err := searchLDAPObjectByGUID(client, guid)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[DEBUG] ldap_object::import - GUID %q resolved to %q", id, dn)
+		id = dn
+	}
+
+	d.SetId(id)
+	d.Set("dn", id)
+	return []*schema.ResourceData{d}, nil
+}
 
-	_, err := l.Search(request)
+// searchLDAPObjectByGUID looks for the object with the given binary
+// objectGUID in every naming context advertised in the Root DSE (the domain,
+// but also e.g. the configuration and the DNS application partitions).
+func searchLDAPObjectByGUID(client ldap.Client, guid []byte) (string, error) {
+	rootDSE, err := readRootDSE(client, "namingContexts")
 	if err != nil {
-		if err, ok := err.(*ldap.Error); ok {
-			if err.ResultCode == 32 { // no such object
-				log.Printf("[WARN] ldap_object::exists - lookup for %q returned no value: deleted on server?", dn)
-				return false, nil
-			}
-		}
-		log.Printf("[DEBUG] ldap_object::exists - lookup for %q returned an error %v", dn, err)
-		return false, err
+		return "", err
+	}
+	if rootDSE == nil || len(rootDSE.GetEqualFoldAttributeValues("namingContexts")) == 0 {
+		return "", fmt.Errorf("the root DSE has no namingContexts, cannot search by GUID")
 	}
 
-	log.Printf("[DEBUG] ldap_object::exists - object %q exists", dn)
-	return true, nil
+	found := map[string]string{}
+	for _, base := range rootDSE.GetEqualFoldAttributeValues("namingContexts") {
+		request := ldap.NewSearchRequest(
+			base,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0,
+			0,
+			false,
+			fmt.Sprintf("(objectGUID=%s)", util.EscapeFilterBytes(guid)),
+			[]string{"objectGUID"},
+			nil,
+		)
+		sr, err := client.Search(request)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range sr.Entries {
+			// nested naming contexts may return the same object twice
+			found[strings.ToLower(entry.DN)] = entry.DN
+		}
+	}
+	if len(found) != 1 {
+		return "", fmt.Errorf("expected exactly one object with GUID in the naming contexts of the server, found %d", len(found))
+	}
+	for _, dn := range found {
+		return dn, nil
+	}
+	return "", nil
 }
 
 func resourceLDAPObjectCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ldap.Conn)
+	client := meta.(ldap.Client)
 	dn := d.Get("dn").(string)
 
 	log.Printf("[DEBUG] ldap_object::create - creating a new object under %q", dn)
@@ -165,7 +232,7 @@ func resourceLDAPObjectCreate(d *schema.ResourceData, meta interface{}) error {
 						continue
 					}
 					if len(attributesToSet) > 0 && !stringSliceContains(attributesToSet, name) {
-						log.Printf("[DEBUG] ldap_object::create - %q skipping unselected attribute", dn, name)
+						log.Printf("[DEBUG] ldap_object::create - %q skipping unselected attribute %q", dn, name)
 						continue
 					}
 					log.Printf("[DEBUG] ldap_object::create - %q has attribute[%v] => %v (%T)", dn, name, value, value)
@@ -182,7 +249,7 @@ func resourceLDAPObjectCreate(d *schema.ResourceData, meta interface{}) error {
 
 	err := client.Add(request)
 	if err != nil {
-		return err
+		return explainEntryExists(client, err, "ldap_object", dn)
 	}
 
 	log.Printf("[DEBUG] ldap_object::create - object %q added to LDAP server", dn)
@@ -200,12 +267,20 @@ func stringSliceContains(haystack []string, needle string) bool {
 	return false
 }
 
+func toStringSlice(values []interface{}) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.(string))
+	}
+	return result
+}
+
 func resourceLDAPObjectRead(d *schema.ResourceData, meta interface{}) error {
 	return readLDAPObject(d, meta, true)
 }
 
 func resourceLDAPObjectUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ldap.Conn)
+	client := meta.(ldap.Client)
 
 	log.Printf("[DEBUG] ldap_object::update - performing update on %q", d.Id())
 
@@ -231,10 +306,24 @@ func resourceLDAPObjectUpdate(d *schema.ResourceData, meta interface{}) error {
 		if err != nil {
 			return err
 		}
+
+		if d.Get("read_managed_attributes_only").(bool) {
+			// the attributes configured since the last refresh were not
+			// read, the object may already have values for them
+			read := groupAttributeValues(o.(*schema.Set))
+			managed, _ := d.GetChange("managed_attributes")
+			for _, attribute := range managed.(*schema.Set).List() {
+				read[attribute.(string)] = nil
+			}
+			for i, change := range modify.Changes {
+				if _, ok := read[change.Modification.Type]; !ok && change.Operation == ldap.AddAttribute {
+					modify.Changes[i].Operation = ldap.ReplaceAttribute
+				}
+			}
+		}
 	}
 
-	err := client.Modify(modify)
-	if err != nil {
+	if err := modifyInBatches(client, modify); err != nil {
 		log.Printf("[ERROR] ldap_object::update - error modifying LDAP object %q with values %v", d.Id(), err)
 		return err
 	}
@@ -242,7 +331,7 @@ func resourceLDAPObjectUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceLDAPObjectDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*ldap.Conn)
+	client := meta.(ldap.Client)
 	dn := d.Get("dn").(string)
 
 	log.Printf("[DEBUG] ldap_object::delete - removing %q", dn)
@@ -259,11 +348,22 @@ func resourceLDAPObjectDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func readLDAPObject(d *schema.ResourceData, meta interface{}, updateState bool) error {
-	client := meta.(*ldap.Conn)
+	client := meta.(ldap.Client)
 	dn := d.Get("dn").(string)
 
 	log.Printf("[DEBUG] ldap_object::read - looking for object %q", dn)
 
+	requested := []string{"*"}
+	if d.Get("read_managed_attributes_only").(bool) {
+		requested = []string{"objectClass"}
+		for attribute := range groupAttributeValues(d.Get("attributes").(*schema.Set)) {
+			requested = append(requested, attribute)
+		}
+		for _, attribute := range d.Get("managed_attributes").(*schema.Set).List() {
+			requested = append(requested, attribute.(string))
+		}
+	}
+
 	// when searching by DN, you don't need t specify the base DN a search
 	// filter a "subtree" scope: just put the DN (i.e. the primary key) as the
 	// base DN with a "base object" scope, and the returned object will be the
@@ -276,7 +376,7 @@ func readLDAPObject(d *schema.ResourceData, meta interface{}, updateState bool)
 		0,
 		false,
 		"(objectclass=*)",
-		[]string{"*"},
+		requested,
 		nil,
 	)
 
@@ -295,8 +395,16 @@ func readLDAPObject(d *schema.ResourceData, meta interface{}, updateState bool)
 
 	log.Printf("[DEBUG] ldap_object::read - query for %q returned %v", dn, sr)
 
+	if err := readRangedAttributes(client, sr.Entries[0]); err != nil {
+		log.Printf("[DEBUG] ldap_object::read - lookup for %q returned an error %v", dn, err)
+		return err
+	}
+
 	d.SetId(dn)
 	d.Set("object_classes", sr.Entries[0].GetAttributeValues("objectClass"))
+	if d.Get("decode_binary_attributes").(bool) {
+		decodeADBinaryAttributes(sr.Entries[0])
+	}
 
 	// retrieve attributes to skip from HCL
 	attributesToSkip := []string{"objectClass"}
@@ -316,6 +424,8 @@ func readLDAPObject(d *schema.ResourceData, meta interface{}, updateState bool)
 	set := &schema.Set{
 		F: attributeHash,
 	}
+	configured := groupAttributeValues(d.Get("attributes").(*schema.Set))
+	matcher := &ldapValueMatcher{client: client}
 
 	for _, attribute := range sr.Entries[0].Attributes {
 		log.Printf("[DEBUG] ldap_object::read - treating attribute %q of %q (%d values: %v)", attribute.Name, dn, len(attribute.Values), attribute.Values)
@@ -341,7 +451,7 @@ func readLDAPObject(d *schema.ResourceData, meta interface{}, updateState bool)
 		// we do not handle name => []values, and we have a set of maps each
 		// holding a single entry name => value; multiple maps may share the
 		// same key.
-		for _, value := range attribute.Values {
+		for _, value := range matcher.match(attribute.Name, attribute.Values, configured[attribute.Name]) {
 			log.Printf("[DEBUG] ldap_object::read - for %q, setting %q => %q", dn, attribute.Name, value)
 			set.Add(map[string]interface{}{
 				attribute.Name: value,
@@ -389,91 +499,83 @@ func printAttributes(prefix string, attributes interface{}) string {
 	return buffer.String()
 }
 
+// computeAndAddDeltas adds to the modify request the changes turning the old
+// attributes into the new ones. The values are grouped by attribute in one
+// pass over each set and diffed through maps, so that attributes with tens
+// of thousands of values are handled in linear time; a changed attribute
+// gets its removed and added values when there are fewer of them than of
+// its new values (e.g. a few members of a large group), and is replaced
+// otherwise (e.g. single-valued attributes).
 func computeAndAddDeltas(modify *ldap.ModifyRequest, os, ns *schema.Set) error {
-	rk := util.NewSet() // names of removed attributes
-	for _, v := range os.Difference(ns).List() {
-		for k := range v.(map[string]interface{}) {
-			rk.Add(k)
-		}
-	}
+	old, new := groupAttributeValues(os), groupAttributeValues(ns)
 
-	ak := util.NewSet() // names of added attributes
-	for _, v := range ns.Difference(os).List() {
-		for k := range v.(map[string]interface{}) {
-			ak.Add(k)
-		}
+	names := make([]string, 0, len(old)+len(new))
+	for k := range old {
+		names = append(names, k)
 	}
-
-	kk := util.NewSet() // names of kept attributes
-	for _, v := range ns.Intersection(os).List() {
-		for k := range v.(map[string]interface{}) {
-			kk.Add(k)
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			names = append(names, k)
 		}
 	}
+	sort.Strings(names)
 
-	ck := util.NewSet() // names of changed attributes
-
-	// loop over remove attributes' names
-	for _, k := range rk.List() {
-		if !ak.Contains(k) && !kk.Contains(k) {
-			// one value under this name has been removed, no other value has
-			// been added back, and there is no further value under the same
-			// name among those that were untouched; this means that it has
-			// been dropped and must go among the RemovedAttributes
-			log.Printf("[DEBUG} ldap_object::deltas - dropping attribute %q", k)
+	for _, k := range names {
+		oldValues, newValues := old[k], new[k]
+		switch {
+		case len(newValues) == 0:
+			log.Printf("[DEBUG] ldap_object::deltas - dropping attribute %q", k)
 			modify.Delete(k, []string{})
-		} else {
-			ck.Add(k)
-		}
-	}
-
-	for _, k := range ak.List() {
-		if !rk.Contains(k) && !kk.Contains(k) {
-			// this is the first value under this name: no value is being
-			// removed and no value is being kept; so we're adding this new
-			// attribute to the LDAP object (AddedAttributes), getting all
-			// the values under this name from the new set
-			values := []string{}
-			for _, m := range ns.List() {
-				for mk, mv := range m.(map[string]interface{}) {
-					if k == mk {
-						v := toAttributeValue(k, mv.(string))
-						values = append(values, v)
-					}
+		case len(oldValues) == 0:
+			log.Printf("[DEBUG] ldap_object::deltas - adding new attribute %q with %d values", k, len(newValues))
+			modify.Add(k, toAttributeValues(k, newValues))
+		default:
+			removed, added := util.DiffValues(oldValues, newValues)
+			switch {
+			case len(removed) == 0 && len(added) == 0:
+				continue
+			case len(removed)+len(added) < len(newValues):
+				log.Printf("[DEBUG] ldap_object::deltas - removing %d and adding %d values of attribute %q", len(removed), len(added), k)
+				if len(removed) > 0 {
+					modify.Delete(k, toAttributeValues(k, removed))
 				}
+				if len(added) > 0 {
+					modify.Add(k, toAttributeValues(k, added))
+				}
+			default:
+				log.Printf("[DEBUG] ldap_object::deltas - changing attribute %q with %d values", k, len(newValues))
+				modify.Replace(k, toAttributeValues(k, newValues))
 			}
-			modify.Add(k, values)
-			log.Printf("[DEBUG} ldap_object::deltas - adding new attribute %q with values %v", k, values)
-		} else {
-			ck.Add(k)
 		}
 	}
+	return nil
+}
 
-	// now loop over changed attributes and
-	for _, k := range ck.List() {
-		// the attributes in this set have been changed, in that a new value has
-		// been added or removed and it was not the last/first one; so we're
-		// adding this new attribute to the LDAP object (ModifiedAttributes),
-		// getting all the values under this name from the new set
-		values := []string{}
-		for _, m := range ns.List() {
-			for mk, mv := range m.(map[string]interface{}) {
-				if k == mk {
-					v := toAttributeValue(k, mv.(string))
-					values = append(values, v)
-				}
-			}
+// groupAttributeValues returns the values of the attributes set of an
+// ldap_object, by attribute name.
+func groupAttributeValues(attributes *schema.Set) map[string][]string {
+	values := map[string][]string{}
+	for _, m := range attributes.List() {
+		for k, v := range m.(map[string]interface{}) {
+			values[k] = append(values[k], v.(string))
 		}
-		modify.Replace(k, values)
-		log.Printf("[DEBUG} ldap_object::deltas - changing attribute %q with values %v", k, values)
 	}
-	return nil
+	return values
+}
+
+// toAttributeValues returns the values to send for an attribute, see
+// toAttributeValue.
+func toAttributeValues(name string, values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, toAttributeValue(name, value))
+	}
+	return result
 }
 
 func toAttributeValue(name, value string) string {
 	if name == "unicodePwd" {
-		utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
-		pwdEncoded, _ := utf16.NewEncoder().String("\"" + value + "\"")
+		pwdEncoded, _ := util.EncodeUnicodePwd(value)
 		return pwdEncoded
 	}
 	return value
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPOlcAccess() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcAccessCreate,
+		Read:   resourceLDAPOlcAccessRead,
+		Update: resourceLDAPOlcAccessUpdate,
+		Delete: resourceLDAPOlcAccessDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the database in cn=config the rules apply to (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"rules": {
+				Type:        schema.TypeList,
+				Description: "The ordered list of access rules, without the {n} index prefix (e.g. \"to attrs=userPassword by self write by anonymous auth by * none\").",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Required:    true,
+				MinItems:    1,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcAccessCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+
+	log.Printf("[DEBUG] ldap_olc_access::create - setting access rules of %q", dn)
+
+	// the resource owns all the rules of the database, so whatever is there
+	// beforehand (e.g. the defaults from slapd.ldif) gets replaced
+	entry, err := readConfigEntry(client, dn, "olcAccess")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("database %q does not exist", dn)
+	}
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	addOrderedDeltas(modify, "olcAccess", util.SortOrderedValues(entry.GetAttributeValues("olcAccess")), toStringSlice(d.Get("rules").([]interface{})))
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_access::create - error setting access rules of %q: %v", dn, err)
+			return err
+		}
+	}
+
+	d.SetId(dn)
+	return resourceLDAPOlcAccessRead(d, meta)
+}
+
+func resourceLDAPOlcAccessRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_access::read - reading access rules of %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "olcAccess")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_access::read - database %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// slapd rewrites the rules it is given in its own form, keep the
+	// configured ones it considers equal
+	rules := util.SortOrderedValues(entry.GetAttributeValues("olcAccess"))
+	configured := toStringSlice(d.Get("rules").([]interface{}))
+	for i := range rules {
+		if i < len(configured) && util.NormalizeOlcAccess(rules[i]) == util.NormalizeOlcAccess(configured[i]) {
+			rules[i] = configured[i]
+		}
+	}
+
+	d.Set("database_dn", dn)
+	return d.Set("rules", rules)
+}
+
+func resourceLDAPOlcAccessUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_access::update - performing update on %q", d.Id())
+
+	if d.HasChange("rules") {
+		o, n := d.GetChange("rules")
+		modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+		// all deletions and insertions go into a single modify request, which
+		// slapd applies atomically, so the database is never left with a
+		// partially reordered ACL
+		addOrderedDeltas(modify, "olcAccess", toStringSlice(o.([]interface{})), toStringSlice(n.([]interface{})))
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_access::update - error updating access rules of %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPOlcAccessRead(d, meta)
+}
+
+func resourceLDAPOlcAccessDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_access::delete - removing access rules of %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modify.Delete("olcAccess", []string{})
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultNoSuchObject || err.ResultCode == ldap.LDAPResultNoSuchAttribute) {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc_access::delete - error removing access rules of %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
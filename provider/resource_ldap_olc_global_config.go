@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPOlcGlobalConfig() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcGlobalConfigCreate,
+		Read:   resourceLDAPOlcGlobalConfigRead,
+		Update: resourceLDAPOlcGlobalConfigUpdate,
+		Delete: resourceLDAPOlcGlobalConfigDelete,
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the configuration entry; olcPasswordHash for example lives in olcDatabase={-1}frontend,cn=config.",
+				Optional:    true,
+				Default:     "cn=config",
+				ForceNew:    true,
+			},
+			"attribute": configAttributeSchema("The configuration attributes to manage; any attribute not listed here is left alone.", "The name of the attribute (e.g. olcLogLevel, olcIdleTimeout, olcTLSCipherSuite)."),
+		},
+	}
+}
+
+func resourceLDAPOlcGlobalConfigCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+
+	log.Printf("[DEBUG] ldap_olc_global_config::create - setting attributes of %q", dn)
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	for name, values := range configAttributes(d.Get("attribute").(*schema.Set)) {
+		modify.Replace(name, values)
+	}
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_olc_global_config::create - error setting attributes of %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAPOlcGlobalConfigRead(d, meta)
+}
+
+func resourceLDAPOlcGlobalConfigRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_global_config::read - reading attributes of %q", dn)
+
+	configured := configAttributes(d.Get("attribute").(*schema.Set))
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+
+	entry, err := readConfigEntry(client, dn, names...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_global_config::read - %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	return d.Set("attribute", flattenConfigAttributes(entry, configured))
+}
+
+func resourceLDAPOlcGlobalConfigUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_global_config::update - performing update on %q", d.Id())
+
+	if d.HasChange("attribute") {
+		o, n := d.GetChange("attribute")
+		old := configAttributes(o.(*schema.Set))
+		new := configAttributes(n.(*schema.Set))
+
+		modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+		for name := range old {
+			if _, ok := new[name]; !ok {
+				// no longer managed: fall back to the server default
+				modify.Replace(name, []string{})
+			}
+		}
+		for name, values := range new {
+			if !stringSlicesEqual(old[name], values) {
+				modify.Replace(name, values)
+			}
+		}
+		if len(modify.Changes) > 0 {
+			if err := client.Modify(modify); err != nil {
+				log.Printf("[ERROR] ldap_olc_global_config::update - error updating attributes of %q: %v", d.Id(), err)
+				return err
+			}
+		}
+	}
+	return resourceLDAPOlcGlobalConfigRead(d, meta)
+}
+
+func resourceLDAPOlcGlobalConfigDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_global_config::delete - removing attributes of %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	for name := range configAttributes(d.Get("attribute").(*schema.Set)) {
+		modify.Replace(name, []string{})
+	}
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_olc_global_config::delete - error removing attributes of %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLDAPOlcIndex() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcIndexCreate,
+		Read:   resourceLDAPOlcIndexRead,
+		Update: resourceLDAPOlcIndexUpdate,
+		Delete: resourceLDAPOlcIndexDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAPOlcIndexImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the mdb database in cn=config (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute to index, or \"default\" for the default index types.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"types": {
+				Type:        schema.TypeSet,
+				Description: "The index types to maintain (eq, pres, sub, approx, subinitial, subany, subfinal, nolang, nosubtypes).",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"eq", "pres", "sub", "approx", "subinitial", "subany", "subfinal", "nolang", "nosubtypes", "notags"}, false),
+				},
+				Set:      schema.HashString,
+				Required: true,
+				MinItems: 1,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcIndexCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_olc_index::create - indexing %q on %q", attribute, dn)
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	modify.Add("olcDbIndex", []string{formatOlcIndex(attribute, d.Get("types").(*schema.Set))})
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_olc_index::create - error indexing %q on %q: %v", attribute, dn, err)
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", dn, attribute))
+	return resourceLDAPOlcIndexRead(d, meta)
+}
+
+func resourceLDAPOlcIndexRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_olc_index::read - reading index of %q on %q", attribute, dn)
+
+	entry, err := readConfigEntry(client, dn, "olcDbIndex")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_index::read - database %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// slapd may group several attributes sharing the same types in a single
+	// value (e.g. "uid,cn eq"), so look for ours in every value
+	for _, value := range entry.GetAttributeValues("olcDbIndex") {
+		attributes, types := parseOlcIndex(value)
+		for _, a := range attributes {
+			if strings.EqualFold(a, attribute) {
+				return d.Set("types", types)
+			}
+		}
+	}
+
+	log.Printf("[WARN] ldap_olc_index::read - index of %q on %q not found, removing from state", attribute, dn)
+	d.SetId("")
+	return nil
+}
+
+func resourceLDAPOlcIndexUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_olc_index::update - performing update on %q", d.Id())
+
+	if d.HasChange("types") {
+		o, n := d.GetChange("types")
+		modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+		modify.Delete("olcDbIndex", []string{formatOlcIndex(attribute, o.(*schema.Set))})
+		modify.Add("olcDbIndex", []string{formatOlcIndex(attribute, n.(*schema.Set))})
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_index::update - error updating index of %q on %q: %v", attribute, dn, err)
+			return err
+		}
+	}
+	return resourceLDAPOlcIndexRead(d, meta)
+}
+
+func resourceLDAPOlcIndexDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+	attribute := d.Get("attribute").(string)
+
+	log.Printf("[DEBUG] ldap_olc_index::delete - removing index of %q on %q", attribute, dn)
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	modify.Delete("olcDbIndex", []string{formatOlcIndex(attribute, d.Get("types").(*schema.Set))})
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultNoSuchObject || err.ResultCode == ldap.LDAPResultNoSuchAttribute) {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc_index::delete - error removing index of %q on %q: %v", attribute, dn, err)
+		return err
+	}
+	return nil
+}
+
+// resourceLDAPOlcIndexImport expects an ID in the form <database DN>:<attribute>.
+func resourceLDAPOlcIndexImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	i := strings.LastIndex(d.Id(), ":")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid ID %q, expected <database DN>:<attribute>", d.Id())
+	}
+	d.Set("database_dn", d.Id()[:i])
+	d.Set("attribute", d.Id()[i+1:])
+	return []*schema.ResourceData{d}, nil
+}
+
+func formatOlcIndex(attribute string, types *schema.Set) string {
+	values := toStringSlice(types.List())
+	sort.Strings(values)
+	return fmt.Sprintf("%s %s", attribute, strings.Join(values, ","))
+}
+
+// parseOlcIndex splits an olcDbIndex value into the indexed attributes and
+// the index types.
+func parseOlcIndex(value string) ([]string, []string) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	attributes := strings.Split(fields[0], ",")
+	types := []string{}
+	if len(fields) > 1 {
+		types = strings.Split(fields[1], ",")
+	}
+	return attributes, types
+}
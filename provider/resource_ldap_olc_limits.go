@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPOlcLimits() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcLimitsCreate,
+		Read:   resourceLDAPOlcLimitsRead,
+		Update: resourceLDAPOlcLimitsUpdate,
+		Delete: resourceLDAPOlcLimitsDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the database in cn=config (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"limit": {
+				Type:        schema.TypeList,
+				Description: "The ordered list of per-identity limits (olcLimits); the first matching selector wins.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"selector": {
+							Type:        schema.TypeString,
+							Description: "Who the limits apply to (e.g. dn.exact=\"cn=replicator,dc=example,dc=com\", users, anonymous).",
+							Required:    true,
+						},
+						"limits": {
+							Type:        schema.TypeMap,
+							Description: "The limits and their values (e.g. size = \"unlimited\", time.soft = \"10\").",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Required:    true,
+						},
+					},
+				},
+			},
+			"size_limit": {
+				Type:        schema.TypeString,
+				Description: "The default size limit of the database (olcSizeLimit, e.g. 500 or \"size.soft=500 size.hard=1000\").",
+				Optional:    true,
+			},
+			"time_limit": {
+				Type:        schema.TypeString,
+				Description: "The default time limit of the database (olcTimeLimit, e.g. 3600 or unlimited).",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcLimitsCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+
+	log.Printf("[DEBUG] ldap_olc_limits::create - setting limits of %q", dn)
+
+	values := []string{}
+	for i, limit := range d.Get("limit").([]interface{}) {
+		values = append(values, fmt.Sprintf("{%d}%s", i, formatOlcLimit(limit.(map[string]interface{}))))
+	}
+
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	modify.Replace("olcLimits", values)
+	if v, ok := d.GetOk("size_limit"); ok {
+		modify.Replace("olcSizeLimit", []string{v.(string)})
+	}
+	if v, ok := d.GetOk("time_limit"); ok {
+		modify.Replace("olcTimeLimit", []string{v.(string)})
+	}
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_olc_limits::create - error setting limits of %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAPOlcLimitsRead(d, meta)
+}
+
+func resourceLDAPOlcLimitsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_limits::read - reading limits of %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "olcLimits", "olcSizeLimit", "olcTimeLimit")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_limits::read - database %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// slapd normalizes the DN patterns in the selectors, so a value that only
+	// differs from the configured one by case is kept as configured
+	configured := d.Get("limit").([]interface{})
+	limits := []interface{}{}
+	for i, value := range util.SortOrderedValues(entry.GetAttributeValues("olcLimits")) {
+		limit, err := parseOlcLimit(value)
+		if err != nil {
+			return err
+		}
+		if i < len(configured) && strings.EqualFold(formatOlcLimit(configured[i].(map[string]interface{})), formatOlcLimit(limit)) {
+			limit = configured[i].(map[string]interface{})
+		}
+		limits = append(limits, limit)
+	}
+
+	d.Set("database_dn", dn)
+	if _, ok := d.GetOk("size_limit"); ok {
+		d.Set("size_limit", entry.GetAttributeValue("olcSizeLimit"))
+	}
+	if _, ok := d.GetOk("time_limit"); ok {
+		d.Set("time_limit", entry.GetAttributeValue("olcTimeLimit"))
+	}
+	return d.Set("limit", limits)
+}
+
+func resourceLDAPOlcLimitsUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_limits::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	if d.HasChange("limit") {
+		o, n := d.GetChange("limit")
+		old, new := []string{}, []string{}
+		for _, limit := range o.([]interface{}) {
+			old = append(old, formatOlcLimit(limit.(map[string]interface{})))
+		}
+		for _, limit := range n.([]interface{}) {
+			new = append(new, formatOlcLimit(limit.(map[string]interface{})))
+		}
+		addOrderedDeltas(modify, "olcLimits", old, new)
+	}
+	for attribute, key := range map[string]string{"olcSizeLimit": "size_limit", "olcTimeLimit": "time_limit"} {
+		if d.HasChange(key) {
+			if v := d.Get(key).(string); v != "" {
+				modify.Replace(attribute, []string{v})
+			} else {
+				modify.Replace(attribute, []string{})
+			}
+		}
+	}
+
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_limits::update - error updating limits of %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPOlcLimitsRead(d, meta)
+}
+
+func resourceLDAPOlcLimitsDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_limits::delete - removing limits of %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modify.Replace("olcLimits", []string{})
+	if _, ok := d.GetOk("size_limit"); ok {
+		modify.Replace("olcSizeLimit", []string{})
+	}
+	if _, ok := d.GetOk("time_limit"); ok {
+		modify.Replace("olcTimeLimit", []string{})
+	}
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc_limits::delete - error removing limits of %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// formatOlcLimit renders a limit block as an olcLimits value (without the
+// index prefix), with the limits sorted by name.
+func formatOlcLimit(limit map[string]interface{}) string {
+	limits := limit["limits"].(map[string]interface{})
+	keys := make([]string, 0, len(limits))
+	for k := range limits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := []util.KeyValue{}
+	for _, k := range keys {
+		kvs = append(kvs, util.KeyValue{Key: k, Value: limits[k].(string)})
+	}
+	return fmt.Sprintf("%s %s", limit["selector"].(string), util.FormatKeyValues(kvs))
+}
+
+// parseOlcLimit splits an olcLimits value into its selector and its limits.
+func parseOlcLimit(value string) (map[string]interface{}, error) {
+	kvs, err := util.ParseKeyValues(value)
+	if err != nil || len(kvs) == 0 {
+		return nil, fmt.Errorf("invalid olcLimits value %q: %v", value, err)
+	}
+
+	selector := kvs[0].Key
+	if kvs[0].Value != "" {
+		selector = fmt.Sprintf("%s=\"%s\"", kvs[0].Key, kvs[0].Value)
+	}
+	limits := map[string]interface{}{}
+	for _, kv := range kvs[1:] {
+		limits[kv.Key] = kv.Value
+	}
+	return map[string]interface{}{
+		"selector": selector,
+		"limits":   limits,
+	}, nil
+}
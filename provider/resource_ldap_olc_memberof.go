@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceLDAPOlcMemberOf() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcMemberOfCreate,
+		Read:   resourceLDAPOlcMemberOfRead,
+		Update: resourceLDAPOlcMemberOfUpdate,
+		Delete: resourceLDAPOlcMemberOfDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the database in cn=config (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"load_modules": {
+				Type:        schema.TypeBool,
+				Description: "Whether to load the memberof and refint modules; disable this when slapd is built with the overlays statically linked.",
+				Optional:    true,
+				Default:     true,
+			},
+			"group_object_class": {
+				Type:        schema.TypeString,
+				Description: "The object class of the groups to maintain memberOf for.",
+				Optional:    true,
+				Default:     "groupOfNames",
+			},
+			"member_attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute of the groups listing their members.",
+				Optional:    true,
+				Default:     "member",
+			},
+			"memberof_attribute": {
+				Type:        schema.TypeString,
+				Description: "The attribute of the members listing their groups.",
+				Optional:    true,
+				Default:     "memberOf",
+			},
+			"dangling": {
+				Type:         schema.TypeString,
+				Description:  "What to do with references to entries that do not exist: ignore, drop or error.",
+				Optional:     true,
+				Default:      "ignore",
+				ValidateFunc: validation.StringInSlice([]string{"ignore", "drop", "error"}, false),
+			},
+			"refint": {
+				Type:        schema.TypeBool,
+				Description: "Whether to attach the refint overlay, so that renamed or deleted members are updated in their groups.",
+				Optional:    true,
+				Default:     true,
+			},
+			"refint_attributes": {
+				Type:        schema.TypeSet,
+				Description: "The attributes kept consistent by refint; defaults to the member and memberof attributes.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"memberof_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the memberof overlay entry.",
+				Computed:    true,
+			},
+			"refint_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the refint overlay entry, if any.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcMemberOfCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+
+	// the modules must be loaded before the overlays can be attached, and
+	// refint goes last so that it sits on top of memberof
+	if d.Get("load_modules").(bool) {
+		if err := loadOlcModules(client, "memberof", "refint"); err != nil {
+			log.Printf("[ERROR] ldap_olc_memberof::create - error loading modules: %v", err)
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_olc_memberof::create - attaching memberof overlay to %q", dn)
+
+	request := ldap.NewAddRequest(fmt.Sprintf("olcOverlay=memberof,%s", dn), []ldap.Control{})
+	request.Attribute("objectClass", []string{"olcOverlayConfig", "olcMemberOf"})
+	request.Attribute("olcOverlay", []string{"memberof"})
+	for attribute, values := range olcMemberOfAttributes(d) {
+		request.Attribute(attribute, values)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_olc_memberof::create - error attaching memberof overlay to %q: %v", dn, err)
+		return err
+	}
+	d.SetId(dn)
+
+	if d.Get("refint").(bool) {
+		if err := addOlcRefint(client, dn, olcRefintAttributes(d)); err != nil {
+			return err
+		}
+	}
+	return resourceLDAPOlcMemberOfRead(d, meta)
+}
+
+func resourceLDAPOlcMemberOfRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_memberof::read - reading overlays of %q", dn)
+
+	memberof, err := findOlcOverlay(client, dn, "olcMemberOf",
+		"olcMemberOfGroupOC",
+		"olcMemberOfMemberAD",
+		"olcMemberOfMemberOfAD",
+		"olcMemberOfDangling",
+	)
+	if err != nil {
+		return err
+	}
+	if memberof == nil {
+		log.Printf("[WARN] ldap_olc_memberof::read - memberof overlay of %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+	refint, err := findOlcOverlay(client, dn, "olcRefintConfig", "olcRefintAttribute")
+	if err != nil {
+		return err
+	}
+
+	d.Set("database_dn", dn)
+	d.Set("memberof_dn", memberof.DN)
+	for key, attribute := range map[string]string{
+		"group_object_class": "olcMemberOfGroupOC",
+		"member_attribute":   "olcMemberOfMemberAD",
+		"memberof_attribute": "olcMemberOfMemberOfAD",
+		"dangling":           "olcMemberOfDangling",
+	} {
+		// slapd returns the canonical names of the object class and the
+		// attributes, so keep the configured case when they match
+		value := memberof.GetAttributeValue(attribute)
+		if !strings.EqualFold(value, d.Get(key).(string)) {
+			d.Set(key, value)
+		}
+	}
+	if refint == nil {
+		d.Set("refint", false)
+		return d.Set("refint_dn", "")
+	}
+	d.Set("refint", true)
+	d.Set("refint_dn", refint.DN)
+	// the default attributes follow the memberof configuration, so they are
+	// only tracked when set explicitly
+	if configured := toStringSlice(d.Get("refint_attributes").(*schema.Set).List()); len(configured) > 0 {
+		return d.Set("refint_attributes", matchAttributeNames(refint.GetAttributeValues("olcRefintAttribute"), configured))
+	}
+	return nil
+}
+
+func resourceLDAPOlcMemberOfUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_memberof::update - performing update on %q", dn)
+
+	if d.HasChange("load_modules") && d.Get("load_modules").(bool) {
+		if err := loadOlcModules(client, "memberof", "refint"); err != nil {
+			log.Printf("[ERROR] ldap_olc_memberof::update - error loading modules: %v", err)
+			return err
+		}
+	}
+
+	if d.HasChanges("group_object_class", "member_attribute", "memberof_attribute", "dangling") {
+		modify := ldap.NewModifyRequest(d.Get("memberof_dn").(string), []ldap.Control{})
+		for attribute, values := range olcMemberOfAttributes(d) {
+			modify.Replace(attribute, values)
+		}
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_memberof::update - error updating memberof overlay of %q: %v", dn, err)
+			return err
+		}
+	}
+
+	refintDN := d.Get("refint_dn").(string)
+	switch {
+	case d.Get("refint").(bool) && refintDN == "":
+		if err := addOlcRefint(client, dn, olcRefintAttributes(d)); err != nil {
+			return err
+		}
+	case !d.Get("refint").(bool) && refintDN != "":
+		if err := deleteOlcOverlay(client, refintDN); err != nil {
+			return err
+		}
+	case refintDN != "" && d.HasChanges("refint_attributes", "member_attribute", "memberof_attribute"):
+		modify := ldap.NewModifyRequest(refintDN, []ldap.Control{})
+		modify.Replace("olcRefintAttribute", olcRefintAttributes(d))
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_memberof::update - error updating refint overlay of %q: %v", dn, err)
+			return err
+		}
+	}
+	return resourceLDAPOlcMemberOfRead(d, meta)
+}
+
+func resourceLDAPOlcMemberOfDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_memberof::delete - detaching overlays from %q", d.Id())
+
+	// reverse order of creation: refint sits on top of memberof
+	for _, dn := range []string{d.Get("refint_dn").(string), d.Get("memberof_dn").(string)} {
+		if dn == "" {
+			continue
+		}
+		if err := deleteOlcOverlay(client, dn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func olcMemberOfAttributes(d *schema.ResourceData) map[string][]string {
+	return map[string][]string{
+		"olcMemberOfGroupOC":    {d.Get("group_object_class").(string)},
+		"olcMemberOfMemberAD":   {d.Get("member_attribute").(string)},
+		"olcMemberOfMemberOfAD": {d.Get("memberof_attribute").(string)},
+		"olcMemberOfDangling":   {d.Get("dangling").(string)},
+		"olcMemberOfRefInt":     {"TRUE"},
+	}
+}
+
+// olcRefintAttributes returns the configured refint attributes, or the member
+// and memberof attributes if none are configured.
+func olcRefintAttributes(d *schema.ResourceData) []string {
+	if attributes := toStringSlice(d.Get("refint_attributes").(*schema.Set).List()); len(attributes) > 0 {
+		return attributes
+	}
+	return []string{d.Get("memberof_attribute").(string), d.Get("member_attribute").(string)}
+}
+
+func addOlcRefint(client ldap.Client, databaseDN string, attributes []string) error {
+	log.Printf("[DEBUG] ldap_olc_memberof::refint - attaching refint overlay to %q", databaseDN)
+
+	request := ldap.NewAddRequest(fmt.Sprintf("olcOverlay=refint,%s", databaseDN), []ldap.Control{})
+	request.Attribute("objectClass", []string{"olcOverlayConfig", "olcRefintConfig"})
+	request.Attribute("olcOverlay", []string{"refint"})
+	request.Attribute("olcRefintAttribute", attributes)
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_olc_memberof::refint - error attaching refint overlay to %q: %v", databaseDN, err)
+		return err
+	}
+	return nil
+}
+
+// matchAttributeNames returns the attribute names read from the server,
+// replacing those that only differ in case from a configured one with the
+// configured name.
+func matchAttributeNames(names, configured []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, c := range configured {
+			if strings.EqualFold(name, c) {
+				name = c
+				break
+			}
+		}
+		result = append(result, name)
+	}
+	return result
+}
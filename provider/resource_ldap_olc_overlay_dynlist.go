@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLDAPOlcOverlayDynlist() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcOverlayDynlistCreate,
+		Read:   resourceLDAPOlcOverlayDynlistRead,
+		Update: resourceLDAPOlcOverlayDynlistUpdate,
+		Delete: resourceLDAPOlcOverlayDynlistDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the database in cn=config (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"load_module": {
+				Type:        schema.TypeBool,
+				Description: "Whether to load the dynlist module; disable this when slapd is built with the overlay statically linked.",
+				Optional:    true,
+				Default:     true,
+			},
+			"attr_set": {
+				Type:        schema.TypeList,
+				Description: "The ordered dynamic list definitions (olcDynListAttrSet).",
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"object_class": {
+							Type:        schema.TypeString,
+							Description: "The object class of the dynamic entries (e.g. groupOfURLs).",
+							Required:    true,
+						},
+						"url_attribute": {
+							Type:        schema.TypeString,
+							Description: "The attribute holding the LDAP URLs to expand.",
+							Optional:    true,
+							Default:     "memberURL",
+						},
+						"member": {
+							Type:        schema.TypeList,
+							Description: "The attributes to fill with the expanded entries; without any, the attributes of the URLs are returned.",
+							Optional:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"attribute": {
+										Type:        schema.TypeString,
+										Description: "The attribute listing the DNs of the matching entries (e.g. member).",
+										Required:    true,
+									},
+									"mapped_attribute": {
+										Type:        schema.TypeString,
+										Description: "The attribute of the matching entries to return instead of their DN.",
+										Optional:    true,
+									},
+									"member_of_attribute": {
+										Type:        schema.TypeString,
+										Description: "The attribute listing the dynamic groups on the matching entries (e.g. dgMemberOf).",
+										Optional:    true,
+									},
+									"static_object_class": {
+										Type:        schema.TypeString,
+										Description: "The object class of static groups to also consider for member_of_attribute.",
+										Optional:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"overlay_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the dynlist overlay entry.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcOverlayDynlistCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+
+	if d.Get("load_module").(bool) {
+		if err := loadOlcModules(client, "dynlist"); err != nil {
+			log.Printf("[ERROR] ldap_olc_overlay_dynlist::create - error loading module: %v", err)
+			return err
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_olc_overlay_dynlist::create - attaching dynlist overlay to %q", dn)
+
+	values := []string{}
+	for i, attrSet := range olcDynlistAttrSets(d.Get("attr_set").([]interface{})) {
+		values = append(values, fmt.Sprintf("{%d}%s", i, attrSet))
+	}
+	request := ldap.NewAddRequest(fmt.Sprintf("olcOverlay=dynlist,%s", dn), []ldap.Control{})
+	request.Attribute("objectClass", []string{"olcOverlayConfig", "olcDynListConfig"})
+	request.Attribute("olcOverlay", []string{"dynlist"})
+	request.Attribute("olcDynListAttrSet", values)
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_olc_overlay_dynlist::create - error attaching dynlist overlay to %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAPOlcOverlayDynlistRead(d, meta)
+}
+
+func resourceLDAPOlcOverlayDynlistRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_overlay_dynlist::read - reading dynlist overlay of %q", dn)
+
+	entry, err := findOlcOverlay(client, dn, "olcDynListConfig", "olcDynListAttrSet")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_overlay_dynlist::read - dynlist overlay of %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// slapd prints the canonical names of the object classes and attributes,
+	// so a value only differing in case from the configured one is kept as
+	// configured
+	configured := d.Get("attr_set").([]interface{})
+	formatted := olcDynlistAttrSets(configured)
+	attrSets := []interface{}{}
+	for i, value := range util.SortOrderedValues(entry.GetAttributeValues("olcDynListAttrSet")) {
+		if i < len(formatted) && strings.EqualFold(formatted[i], value) {
+			attrSets = append(attrSets, configured[i])
+			continue
+		}
+		attrSets = append(attrSets, parseOlcDynlistAttrSet(value))
+	}
+
+	d.Set("database_dn", dn)
+	d.Set("overlay_dn", entry.DN)
+	return d.Set("attr_set", attrSets)
+}
+
+func resourceLDAPOlcOverlayDynlistUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_overlay_dynlist::update - performing update on %q", d.Id())
+
+	if d.HasChange("load_module") && d.Get("load_module").(bool) {
+		if err := loadOlcModules(client, "dynlist"); err != nil {
+			log.Printf("[ERROR] ldap_olc_overlay_dynlist::update - error loading module: %v", err)
+			return err
+		}
+	}
+
+	if d.HasChange("attr_set") {
+		o, n := d.GetChange("attr_set")
+		modify := ldap.NewModifyRequest(d.Get("overlay_dn").(string), []ldap.Control{})
+		addOrderedDeltas(modify, "olcDynListAttrSet", olcDynlistAttrSets(o.([]interface{})), olcDynlistAttrSets(n.([]interface{})))
+		if len(modify.Changes) > 0 {
+			if err := client.Modify(modify); err != nil {
+				log.Printf("[ERROR] ldap_olc_overlay_dynlist::update - error updating dynlist overlay of %q: %v", d.Id(), err)
+				return err
+			}
+		}
+	}
+	return resourceLDAPOlcOverlayDynlistRead(d, meta)
+}
+
+func resourceLDAPOlcOverlayDynlistDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] ldap_olc_overlay_dynlist::delete - detaching dynlist overlay from %q", d.Id())
+
+	return deleteOlcOverlay(meta.(ldap.Client), d.Get("overlay_dn").(string))
+}
+
+func olcDynlistAttrSets(attrSets []interface{}) []string {
+	values := []string{}
+	for _, attrSet := range attrSets {
+		values = append(values, formatOlcDynlistAttrSet(attrSet.(map[string]interface{})))
+	}
+	return values
+}
+
+// formatOlcDynlistAttrSet renders an attr_set block as an olcDynListAttrSet
+// value (without the index prefix): the object class and the URL attribute,
+// followed by a [<mapped>:]<member>[+<memberOf>[@<static class>]] spec for
+// every member block.
+func formatOlcDynlistAttrSet(attrSet map[string]interface{}) string {
+	fields := []string{attrSet["object_class"].(string), attrSet["url_attribute"].(string)}
+	for _, m := range attrSet["member"].([]interface{}) {
+		member := m.(map[string]interface{})
+		spec := member["attribute"].(string)
+		if mapped := member["mapped_attribute"].(string); mapped != "" {
+			spec = mapped + ":" + spec
+		}
+		if memberOf := member["member_of_attribute"].(string); memberOf != "" {
+			spec += "+" + memberOf
+			if static := member["static_object_class"].(string); static != "" {
+				spec += "@" + static
+			}
+		}
+		fields = append(fields, spec)
+	}
+	return strings.Join(fields, " ")
+}
+
+// parseOlcDynlistAttrSet is the reverse of formatOlcDynlistAttrSet.
+func parseOlcDynlistAttrSet(value string) map[string]interface{} {
+	fields := strings.Fields(value)
+	attrSet := map[string]interface{}{
+		"object_class":  "",
+		"url_attribute": "",
+		"member":        []interface{}{},
+	}
+	if len(fields) > 0 {
+		attrSet["object_class"] = fields[0]
+	}
+	if len(fields) > 1 {
+		attrSet["url_attribute"] = fields[1]
+	}
+	members := []interface{}{}
+	for i, spec := range fields {
+		if i < 2 {
+			continue
+		}
+		member := map[string]interface{}{
+			"mapped_attribute":    "",
+			"member_of_attribute": "",
+			"static_object_class": "",
+		}
+		if i := strings.Index(spec, "+"); i >= 0 {
+			memberOf := spec[i+1:]
+			if j := strings.Index(memberOf, "@"); j >= 0 {
+				member["static_object_class"] = memberOf[j+1:]
+				memberOf = memberOf[:j]
+			}
+			member["member_of_attribute"] = memberOf
+			spec = spec[:i]
+		}
+		if i := strings.Index(spec, ":"); i >= 0 {
+			member["mapped_attribute"] = spec[:i]
+			spec = spec[i+1:]
+		}
+		member["attribute"] = spec
+		members = append(members, member)
+	}
+	attrSet["member"] = members
+	return attrSet
+}
@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const olcSchemaBaseDN = "cn=schema,cn=config"
+
+func resourceLDAPOlcSchema() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcSchemaCreate,
+		Read:   resourceLDAPOlcSchemaRead,
+		Update: resourceLDAPOlcSchemaUpdate,
+		Delete: resourceLDAPOlcSchemaDelete,
+
+		CustomizeDiff: resourceLDAPOlcSchemaCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the schema entry under cn=schema,cn=config, without the {n} index prefix.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"attribute_types": {
+				Type:          schema.TypeList,
+				Description:   "The ordered list of attribute type definitions (e.g. \"( 1.3.6.1.4.1.99999.1.1 NAME 'exampleBadge' ... )\").",
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"schema"},
+			},
+			"object_classes": {
+				Type:          schema.TypeList,
+				Description:   "The ordered list of object class definitions.",
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"schema"},
+			},
+			"schema": {
+				Type:        schema.TypeString,
+				Description: "The content of an OpenLDAP .schema or LDIF file to load the definitions from, as an alternative to attribute_types and object_classes.",
+				Optional:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, es []error) {
+					if _, _, err := util.ParseSchema(v.(string)); err != nil {
+						es = append(es, fmt.Errorf("%q: %v", k, err))
+					}
+					return
+				},
+			},
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the schema entry, including the index assigned by the server.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// resourceLDAPOlcSchemaCustomizeDiff plans the definitions of the schema file
+// into attribute_types and object_classes when the definitions read from
+// the server differ, as nothing else compares them with the file once it is
+// unchanged (e.g. after definitions were changed or removed by hand).
+func resourceLDAPOlcSchemaCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	text, ok := d.GetOk("schema")
+	if !ok || d.Id() == "" || !d.NewValueKnown("schema") {
+		return nil
+	}
+	attributeTypes, objectClasses, err := util.ParseSchema(text.(string))
+	if err != nil {
+		return err
+	}
+	if !stringSlicesEqual(toStringSlice(d.Get("attribute_types").([]interface{})), attributeTypes) {
+		if err := d.SetNew("attribute_types", attributeTypes); err != nil {
+			return err
+		}
+	}
+	if !stringSlicesEqual(toStringSlice(d.Get("object_classes").([]interface{})), objectClasses) {
+		return d.SetNew("object_classes", objectClasses)
+	}
+	return nil
+}
+
+func resourceLDAPOlcSchemaCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	name := d.Get("name").(string)
+
+	attributeTypes, objectClasses, err := olcSchemaDefinitions(d)
+	if err != nil {
+		return err
+	}
+
+	entries, err := searchOlcSchemas(client)
+	if err != nil {
+		return err
+	}
+	entry := findOlcSchema(entries, name)
+
+	if entry != nil {
+		// the schema has already been loaded (e.g. by a previous run whose
+		// state got lost or by hand): adopt it and bring it in line
+		log.Printf("[DEBUG] ldap_olc_schema::create - schema %q already loaded as %q, adopting it", name, entry.DN)
+		modify := ldap.NewModifyRequest(entry.DN, []ldap.Control{})
+		addOrderedDeltas(modify, "olcAttributeTypes", matchSchemaDefinitions(attributeTypes, entry.GetAttributeValues("olcAttributeTypes")), attributeTypes)
+		addOrderedDeltas(modify, "olcObjectClasses", matchSchemaDefinitions(objectClasses, entry.GetAttributeValues("olcObjectClasses")), objectClasses)
+		if len(modify.Changes) > 0 {
+			if err := client.Modify(modify); err != nil {
+				log.Printf("[ERROR] ldap_olc_schema::create - error updating schema %q: %v", entry.DN, err)
+				return err
+			}
+		}
+		d.SetId(entry.DN)
+		return resourceLDAPOlcSchemaRead(d, meta)
+	}
+
+	// definitions cannot be loaded twice, so report any OID that is already
+	// owned by another schema instead of letting slapd fail with a generic
+	// duplicate error
+	desired := map[string]bool{}
+	for _, definition := range append(append([]string{}, attributeTypes...), objectClasses...) {
+		desired[util.DefinitionOID(definition)] = true
+	}
+	for _, other := range entries {
+		for _, definition := range append(other.GetAttributeValues("olcAttributeTypes"), other.GetAttributeValues("olcObjectClasses")...) {
+			if oid := util.DefinitionOID(definition); desired[oid] {
+				return fmt.Errorf("OID %s is already loaded by schema %q", oid, other.DN)
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] ldap_olc_schema::create - loading schema %q", name)
+
+	request := ldap.NewAddRequest(fmt.Sprintf("cn=%s,%s", ldap.EscapeDN(name), olcSchemaBaseDN), []ldap.Control{})
+	request.Attribute("objectClass", []string{"olcSchemaConfig"})
+	request.Attribute("cn", []string{name})
+	if len(attributeTypes) > 0 {
+		request.Attribute("olcAttributeTypes", attributeTypes)
+	}
+	if len(objectClasses) > 0 {
+		request.Attribute("olcObjectClasses", objectClasses)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_olc_schema::create - error loading schema %q: %v", name, err)
+		return err
+	}
+
+	// the server prefixes the RDN with the index of the schema, so we have to
+	// look the entry up again to know its actual DN
+	entries, err = searchOlcSchemas(client)
+	if err != nil {
+		return err
+	}
+	entry = findOlcSchema(entries, name)
+	if entry == nil {
+		return fmt.Errorf("schema %q not found after loading it", name)
+	}
+
+	log.Printf("[DEBUG] ldap_olc_schema::create - schema %q loaded as %q", name, entry.DN)
+
+	d.SetId(entry.DN)
+	return resourceLDAPOlcSchemaRead(d, meta)
+}
+
+func resourceLDAPOlcSchemaRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_schema::read - reading schema %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "olcAttributeTypes", "olcObjectClasses")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_schema::read - schema %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	attributeTypes, objectClasses, err := olcSchemaDefinitions(d)
+	if err != nil {
+		return err
+	}
+
+	d.Set("dn", dn)
+	d.Set("attribute_types", matchSchemaDefinitions(attributeTypes, entry.GetAttributeValues("olcAttributeTypes")))
+	d.Set("object_classes", matchSchemaDefinitions(objectClasses, entry.GetAttributeValues("olcObjectClasses")))
+	return nil
+}
+
+func resourceLDAPOlcSchemaUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_schema::update - performing update on %q", d.Id())
+
+	attributeTypes, objectClasses, err := olcSchemaDefinitions(d)
+	if err != nil {
+		return err
+	}
+
+	// the lists in state always reflect what was read from the server, no
+	// matter whether the definitions come from the lists or from a file
+	o, _ := d.GetChange("attribute_types")
+	oc, _ := d.GetChange("object_classes")
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	addOrderedDeltas(modify, "olcAttributeTypes", normalizeDefinitions(toStringSlice(o.([]interface{}))), normalizeDefinitions(attributeTypes))
+	addOrderedDeltas(modify, "olcObjectClasses", normalizeDefinitions(toStringSlice(oc.([]interface{}))), normalizeDefinitions(objectClasses))
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_schema::update - error updating schema %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPOlcSchemaRead(d, meta)
+}
+
+func resourceLDAPOlcSchemaDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_schema::delete - removing schema %q", dn)
+
+	err := client.Del(ldap.NewDelRequest(dn, nil))
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultUnwillingToPerform {
+			// older slapd releases cannot unload schema at runtime
+			log.Printf("[WARN] ldap_olc_schema::delete - server refused to remove schema %q, it will stay loaded: %v", dn, err)
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc_schema::delete - error removing schema %q: %v", dn, err)
+		return err
+	}
+	return nil
+}
+
+// olcSchemaDefinitions returns the desired definitions, either parsed from
+// the schema file or taken verbatim from the explicit lists.
+func olcSchemaDefinitions(d *schema.ResourceData) ([]string, []string, error) {
+	if text, ok := d.GetOk("schema"); ok {
+		return util.ParseSchema(text.(string))
+	}
+	return toStringSlice(d.Get("attribute_types").([]interface{})), toStringSlice(d.Get("object_classes").([]interface{})), nil
+}
+
+func normalizeDefinitions(definitions []string) []string {
+	result := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		result = append(result, util.NormalizeDefinition(definition))
+	}
+	return result
+}
+
+// matchSchemaDefinitions strips the index from the definitions returned by
+// the server and, for every definition whose OID is also among the desired
+// ones and which only differs in layout, returns the desired definition
+// instead, so that the server's own formatting does not cause a diff.
+func matchSchemaDefinitions(desired, loaded []string) []string {
+	byOID := map[string]string{}
+	for _, definition := range desired {
+		byOID[util.DefinitionOID(definition)] = definition
+	}
+	result := []string{}
+	for _, definition := range util.SortOrderedValues(loaded) {
+		definition = util.NormalizeDefinition(definition)
+		if d, ok := byOID[util.DefinitionOID(definition)]; ok && util.NormalizeDefinition(d) == definition {
+			definition = d
+		}
+		result = append(result, definition)
+	}
+	return result
+}
+
+// searchOlcSchemas returns all the schema entries loaded in cn=config.
+func searchOlcSchemas(client ldap.Client) ([]*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		olcSchemaBaseDN,
+		ldap.ScopeSingleLevel,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=olcSchemaConfig)",
+		[]string{"cn", "olcAttributeTypes", "olcObjectClasses"},
+		nil,
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		return nil, err
+	}
+	return sr.Entries, nil
+}
+
+// findOlcSchema looks for the schema entry with the given name, whatever
+// index the server assigned to it.
+func findOlcSchema(entries []*ldap.Entry, name string) *ldap.Entry {
+	for _, entry := range entries {
+		for _, cn := range entry.GetAttributeValues("cn") {
+			if _, n := util.SplitOrderedValue(cn); n == name {
+				return entry
+			}
+		}
+	}
+	return nil
+}
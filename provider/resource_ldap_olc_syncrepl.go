@@ -0,0 +1,318 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/trevex/terraform-provider-ldap/util"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the olcSyncrepl keywords that are modelled as dedicated arguments, in the
+// order in which they are written out
+var syncreplKeywords = []string{
+	"provider",
+	"bindmethod",
+	"binddn",
+	"saslmech",
+	"credentials",
+	"searchbase",
+	"filter",
+	"scope",
+	"attrs",
+	"type",
+	"interval",
+	"retry",
+	"starttls",
+	"tls_reqcert",
+}
+
+// the keywords shown for consumers that are not in the configuration
+var syncreplIdentifyingKeywords = []string{"provider", "bindmethod", "binddn", "searchbase", "type"}
+
+func resourceLDAPOlcSyncrepl() *schema.Resource {
+	consumer := map[string]*schema.Schema{
+		"rid": {
+			Type:         schema.TypeInt,
+			Description:  "The replica ID, unique among the consumers of the server.",
+			Required:     true,
+			ValidateFunc: validation.IntBetween(0, 999),
+		},
+		"provider": {
+			Type:        schema.TypeString,
+			Description: "The URL of the provider to replicate from (e.g. ldaps://ldap1.example.com).",
+			Required:    true,
+		},
+		"searchbase": {
+			Type:        schema.TypeString,
+			Description: "The base DN of the replicated content.",
+			Required:    true,
+		},
+		"type": {
+			Type:         schema.TypeString,
+			Description:  "The replication mode, either refreshOnly or refreshAndPersist.",
+			Optional:     true,
+			Default:      "refreshAndPersist",
+			ValidateFunc: validation.StringInSlice([]string{"refreshOnly", "refreshAndPersist"}, false),
+		},
+		"bindmethod": {
+			Type:         schema.TypeString,
+			Description:  "The bind method used to connect to the provider, either simple or sasl.",
+			Optional:     true,
+			Default:      "simple",
+			ValidateFunc: validation.StringInSlice([]string{"simple", "sasl"}, false),
+		},
+		"credentials": {
+			Type:        schema.TypeString,
+			Description: "The password used for simple binds.",
+			Optional:    true,
+			Sensitive:   true,
+		},
+		"options": {
+			Type:        schema.TypeMap,
+			Description: "Any further olcSyncrepl keyword and its value (e.g. timeout, keepalive, schemachecking).",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Optional:    true,
+		},
+	}
+	for _, keyword := range syncreplKeywords {
+		if _, ok := consumer[keyword]; !ok {
+			consumer[keyword] = &schema.Schema{
+				Type:        schema.TypeString,
+				Description: fmt.Sprintf("The value of the %s olcSyncrepl keyword.", keyword),
+				Optional:    true,
+			}
+		}
+	}
+
+	return &schema.Resource{
+		Create: resourceLDAPOlcSyncreplCreate,
+		Read:   resourceLDAPOlcSyncreplRead,
+		Update: resourceLDAPOlcSyncreplUpdate,
+		Delete: resourceLDAPOlcSyncreplDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the consumer database in cn=config (e.g. olcDatabase={1}mdb,cn=config).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"consumer": {
+				Type:        schema.TypeList,
+				Description: "The ordered list of replication consumers, one per provider.",
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Resource{Schema: consumer},
+			},
+			"mirror_mode": {
+				Type:        schema.TypeBool,
+				Description: "Whether the database accepts writes while being a consumer, as needed for multi-provider replication.",
+				Optional:    true,
+				Default:     false,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcSyncreplCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("database_dn").(string)
+
+	log.Printf("[DEBUG] ldap_olc_syncrepl::create - configuring replication of %q", dn)
+
+	values := []string{}
+	for i, consumer := range d.Get("consumer").([]interface{}) {
+		values = append(values, fmt.Sprintf("{%d}%s", i, formatSyncrepl(consumer.(map[string]interface{}))))
+	}
+
+	// slapd refuses mirror mode on a database without a consumer, so the
+	// consumers have to come first
+	modify := ldap.NewModifyRequest(dn, []ldap.Control{})
+	modify.Replace("olcSyncrepl", values)
+	if d.Get("mirror_mode").(bool) {
+		modify.Replace("olcMirrorMode", []string{"TRUE"})
+	} else {
+		modify.Replace("olcMirrorMode", []string{})
+	}
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_olc_syncrepl::create - error configuring replication of %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAPOlcSyncreplRead(d, meta)
+}
+
+func resourceLDAPOlcSyncreplRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_olc_syncrepl::read - reading replication of %q", dn)
+
+	entry, err := readConfigEntry(client, dn, "olcSyncrepl", "olcMirrorMode")
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_syncrepl::read - database %q not found, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	// slapd writes back every keyword with its default value, so for the
+	// consumers we know about only the keywords present in the configuration
+	// are retained
+	configured := map[int]map[string]interface{}{}
+	for _, consumer := range d.Get("consumer").([]interface{}) {
+		configured[consumer.(map[string]interface{})["rid"].(int)] = consumer.(map[string]interface{})
+	}
+
+	consumers := []interface{}{}
+	for _, value := range util.SortOrderedValues(entry.GetAttributeValues("olcSyncrepl")) {
+		consumer, err := parseSyncrepl(value, configured)
+		if err != nil {
+			return err
+		}
+		consumers = append(consumers, consumer)
+	}
+
+	d.Set("database_dn", dn)
+	d.Set("mirror_mode", entry.GetAttributeValue("olcMirrorMode") == "TRUE")
+	return d.Set("consumer", consumers)
+}
+
+func resourceLDAPOlcSyncreplUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_syncrepl::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+
+	// mirror mode has to be dropped before the last consumer goes away and
+	// can only be enabled once there is a consumer
+	mirrorMode := d.Get("mirror_mode").(bool)
+	if d.HasChange("mirror_mode") && !mirrorMode {
+		modify.Replace("olcMirrorMode", []string{})
+	}
+	if d.HasChange("consumer") {
+		o, n := d.GetChange("consumer")
+		old, new := []string{}, []string{}
+		for _, consumer := range o.([]interface{}) {
+			old = append(old, formatSyncrepl(consumer.(map[string]interface{})))
+		}
+		for _, consumer := range n.([]interface{}) {
+			new = append(new, formatSyncrepl(consumer.(map[string]interface{})))
+		}
+		addOrderedDeltas(modify, "olcSyncrepl", old, new)
+	}
+	if d.HasChange("mirror_mode") && mirrorMode {
+		modify.Replace("olcMirrorMode", []string{"TRUE"})
+	}
+
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_syncrepl::update - error updating replication of %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPOlcSyncreplRead(d, meta)
+}
+
+func resourceLDAPOlcSyncreplDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_syncrepl::delete - removing replication of %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	modify.Replace("olcMirrorMode", []string{})
+	modify.Replace("olcSyncrepl", []string{})
+	if err := client.Modify(modify); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_olc_syncrepl::delete - error removing replication of %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// formatSyncrepl renders a consumer block as an olcSyncrepl value (without
+// the index prefix), with the keywords in a stable order.
+func formatSyncrepl(consumer map[string]interface{}) string {
+	kvs := []util.KeyValue{{Key: "rid", Value: fmt.Sprintf("%03d", consumer["rid"].(int))}}
+	for _, keyword := range syncreplKeywords {
+		if v, ok := consumer[keyword].(string); ok && v != "" {
+			kvs = append(kvs, util.KeyValue{Key: keyword, Value: v})
+		}
+	}
+	if options, ok := consumer["options"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(options))
+		for k := range options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kvs = append(kvs, util.KeyValue{Key: k, Value: options[k].(string)})
+		}
+	}
+	return util.FormatKeyValues(kvs)
+}
+
+// parseSyncrepl turns an olcSyncrepl value back into a consumer block. If the
+// configuration has a consumer with the same rid, only the keywords it sets
+// are kept; otherwise only the required ones are.
+func parseSyncrepl(value string, configured map[int]map[string]interface{}) (map[string]interface{}, error) {
+	kvs, err := util.ParseKeyValues(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid olcSyncrepl value %q: %v", value, err)
+	}
+
+	consumer := map[string]interface{}{}
+	options := map[string]interface{}{}
+	for _, kv := range kvs {
+		if kv.Key == "rid" {
+			rid, err := strconv.Atoi(kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rid in olcSyncrepl value %q: %v", value, err)
+			}
+			consumer["rid"] = rid
+			continue
+		}
+		if stringSliceContains(syncreplKeywords, kv.Key) {
+			consumer[kv.Key] = kv.Value
+		} else {
+			options[kv.Key] = kv.Value
+		}
+	}
+
+	rid, _ := consumer["rid"].(int)
+	wanted, ok := configured[rid]
+	for _, keyword := range syncreplKeywords {
+		if ok {
+			if v, _ := wanted[keyword].(string); v != "" {
+				continue
+			}
+		} else if stringSliceContains(syncreplIdentifyingKeywords, keyword) {
+			continue
+		}
+		delete(consumer, keyword)
+	}
+	wantedOptions, _ := wanted["options"].(map[string]interface{})
+	for k := range options {
+		if _, ok := wantedOptions[k]; !ok {
+			delete(options, k)
+		}
+	}
+	consumer["options"] = options
+	return consumer, nil
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"log"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the TLS settings of slapd, by argument name
+var olcTLSAttributes = map[string]string{
+	"certificate_file":     "olcTLSCertificateFile",
+	"certificate_key_file": "olcTLSCertificateKeyFile",
+	"ca_certificate_file":  "olcTLSCACertificateFile",
+	"ca_certificate_path":  "olcTLSCACertificatePath",
+	"verify_client":        "olcTLSVerifyClient",
+	"cipher_suite":         "olcTLSCipherSuite",
+	"protocol_min":         "olcTLSProtocolMin",
+	"crl_check":            "olcTLSCRLCheck",
+	"dh_param_file":        "olcTLSDHParamFile",
+}
+
+func resourceLDAPOlcTLS() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPOlcTLSCreate,
+		Read:   resourceLDAPOlcTLSRead,
+		Update: resourceLDAPOlcTLSUpdate,
+		Delete: resourceLDAPOlcTLSDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"certificate_file": {
+				Type:         schema.TypeString,
+				Description:  "The path of the server certificate (olcTLSCertificateFile).",
+				Optional:     true,
+				RequiredWith: []string{"certificate_key_file"},
+			},
+			"certificate_key_file": {
+				Type:         schema.TypeString,
+				Description:  "The path of the private key of the server certificate (olcTLSCertificateKeyFile).",
+				Optional:     true,
+				RequiredWith: []string{"certificate_file"},
+			},
+			"ca_certificate_file": {
+				Type:        schema.TypeString,
+				Description: "The path of the trusted CA certificates (olcTLSCACertificateFile).",
+				Optional:    true,
+			},
+			"ca_certificate_path": {
+				Type:        schema.TypeString,
+				Description: "The directory of the trusted CA certificates (olcTLSCACertificatePath).",
+				Optional:    true,
+			},
+			"verify_client": {
+				Type:         schema.TypeString,
+				Description:  "Whether to request and check client certificates: never, allow, try or demand (olcTLSVerifyClient).",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"never", "allow", "try", "demand", "hard", "true"}, false),
+			},
+			"cipher_suite": {
+				Type:        schema.TypeString,
+				Description: "The accepted ciphers, in the syntax of the TLS library slapd is built with (olcTLSCipherSuite).",
+				Optional:    true,
+			},
+			"protocol_min": {
+				Type:        schema.TypeString,
+				Description: "The minimum protocol version, e.g. 3.3 for TLS 1.2 (olcTLSProtocolMin).",
+				Optional:    true,
+			},
+			"crl_check": {
+				Type:         schema.TypeString,
+				Description:  "Whether to check client certificates against CRLs: none, peer or all (olcTLSCRLCheck, OpenSSL only).",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"none", "peer", "all"}, false),
+			},
+			"dh_param_file": {
+				Type:        schema.TypeString,
+				Description: "The path of the Diffie-Hellman parameters (olcTLSDHParamFile).",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPOlcTLSCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_tls::create - setting TLS configuration")
+
+	// slapd reloads the TLS context after each modify and rejects a
+	// certificate that does not match the key, so everything goes in a
+	// single request
+	modify := ldap.NewModifyRequest("cn=config", []ldap.Control{})
+	for key, attribute := range olcTLSAttributes {
+		if v, ok := d.GetOk(key); ok {
+			modify.Replace(attribute, []string{v.(string)})
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_tls::create - error setting TLS configuration: %v", err)
+			return err
+		}
+	}
+
+	d.SetId("cn=config")
+	return resourceLDAPOlcTLSRead(d, meta)
+}
+
+func resourceLDAPOlcTLSRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_tls::read - reading TLS configuration")
+
+	attributes := make([]string, 0, len(olcTLSAttributes))
+	for _, attribute := range olcTLSAttributes {
+		attributes = append(attributes, attribute)
+	}
+	entry, err := readConfigEntry(client, d.Id(), attributes...)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		log.Printf("[WARN] ldap_olc_tls::read - %q not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	for key, attribute := range olcTLSAttributes {
+		d.Set(key, entry.GetEqualFoldAttributeValue(attribute))
+	}
+	return nil
+}
+
+func resourceLDAPOlcTLSUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_tls::update - performing update on %q", d.Id())
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	for key, attribute := range olcTLSAttributes {
+		if !d.HasChange(key) {
+			continue
+		}
+		if v := d.Get(key).(string); v != "" {
+			modify.Replace(attribute, []string{v})
+		} else {
+			modify.Replace(attribute, []string{})
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_tls::update - error updating TLS configuration: %v", err)
+			return err
+		}
+	}
+	return resourceLDAPOlcTLSRead(d, meta)
+}
+
+func resourceLDAPOlcTLSDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_olc_tls::delete - removing TLS configuration")
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	for key, attribute := range olcTLSAttributes {
+		if _, ok := d.GetOk(key); ok {
+			modify.Replace(attribute, []string{})
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_olc_tls::delete - error removing TLS configuration: %v", err)
+			return err
+		}
+	}
+	return nil
+}
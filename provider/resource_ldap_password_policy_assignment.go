@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"log"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the LDAP Relax Rules control, which OpenLDAP requires to write some
+// operational attributes
+const relaxRulesOID = "1.3.6.1.4.1.4203.666.5.12"
+
+func resourceLDAPPasswordPolicyAssignment() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLDAPPasswordPolicyAssignmentCreate,
+		Read:   resourceLDAPPasswordPolicyAssignmentRead,
+		Update: resourceLDAPPasswordPolicyAssignmentUpdate,
+		Delete: resourceLDAPPasswordPolicyAssignmentDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAPPasswordPolicyAssignmentImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the entry (usually a user) the policy applies to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"policy_dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the password policy entry (pwdPolicySubentry) overriding the default policy.",
+				Required:    true,
+			},
+			"relax_rules": {
+				Type:        schema.TypeBool,
+				Description: "Whether to send the Relax Rules control, for servers that only allow pwdPolicySubentry to be written with it.",
+				Optional:    true,
+				Default:     false,
+			},
+			"manage_dsa_it": {
+				Type:        schema.TypeBool,
+				Description: "Whether to send the ManageDsaIT control, e.g. when the entry is a referral object.",
+				Optional:    true,
+				Default:     false,
+			},
+		},
+	}
+}
+
+func resourceLDAPPasswordPolicyAssignmentCreate(d *schema.ResourceData, meta interface{}) error {
+	dn := d.Get("dn").(string)
+
+	log.Printf("[DEBUG] ldap_password_policy_assignment::create - assigning %q to %q", d.Get("policy_dn").(string), dn)
+
+	if err := setPasswordPolicySubentry(d, meta.(ldap.Client), []string{d.Get("policy_dn").(string)}); err != nil {
+		log.Printf("[ERROR] ldap_password_policy_assignment::create - error assigning policy to %q: %v", dn, err)
+		return err
+	}
+
+	d.SetId(dn)
+	return resourceLDAPPasswordPolicyAssignmentRead(d, meta)
+}
+
+func resourceLDAPPasswordPolicyAssignmentRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_password_policy_assignment::read - reading policy of %q", dn)
+
+	request := ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=*)",
+		[]string{"pwdPolicySubentry"},
+		passwordPolicyAssignmentControls(d),
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			log.Printf("[WARN] ldap_password_policy_assignment::read - %q not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	policy := sr.Entries[0].GetEqualFoldAttributeValue("pwdPolicySubentry")
+	if policy == "" {
+		log.Printf("[WARN] ldap_password_policy_assignment::read - no policy assigned to %q, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("dn", dn)
+	// servers normalize DNs, so keep the configured form when they match
+	if !strings.EqualFold(policy, d.Get("policy_dn").(string)) {
+		d.Set("policy_dn", policy)
+	}
+	return nil
+}
+
+func resourceLDAPPasswordPolicyAssignmentUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] ldap_password_policy_assignment::update - performing update on %q", d.Id())
+
+	if d.HasChange("policy_dn") {
+		if err := setPasswordPolicySubentry(d, meta.(ldap.Client), []string{d.Get("policy_dn").(string)}); err != nil {
+			log.Printf("[ERROR] ldap_password_policy_assignment::update - error assigning policy to %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPPasswordPolicyAssignmentRead(d, meta)
+}
+
+func resourceLDAPPasswordPolicyAssignmentDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] ldap_password_policy_assignment::delete - removing policy of %q", d.Id())
+
+	if err := setPasswordPolicySubentry(d, meta.(ldap.Client), []string{}); err != nil {
+		if err, ok := err.(*ldap.Error); ok && (err.ResultCode == ldap.LDAPResultNoSuchObject || err.ResultCode == ldap.LDAPResultNoSuchAttribute) {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_password_policy_assignment::delete - error removing policy of %q: %v", d.Id(), err)
+		return err
+	}
+	return nil
+}
+
+// resourceLDAPPasswordPolicyAssignmentImport expects the DN of the entry.
+func resourceLDAPPasswordPolicyAssignmentImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("dn", d.Id())
+	d.Set("relax_rules", false)
+	d.Set("manage_dsa_it", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+func setPasswordPolicySubentry(d *schema.ResourceData, client ldap.Client, values []string) error {
+	modify := ldap.NewModifyRequest(d.Get("dn").(string), passwordPolicyAssignmentControls(d))
+	modify.Replace("pwdPolicySubentry", values)
+	return client.Modify(modify)
+}
+
+func passwordPolicyAssignmentControls(d *schema.ResourceData) []ldap.Control {
+	controls := []ldap.Control{}
+	if d.Get("relax_rules").(bool) {
+		controls = append(controls, ldap.NewControlString(relaxRulesOID, true, ""))
+	}
+	if d.Get("manage_dsa_it").(bool) {
+		controls = append(controls, ldap.NewControlManageDsaIT(true))
+	}
+	return controls
+}
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// the OID of the RFC 3672 Subentries control
+const subentriesOID = "1.3.6.1.4.1.4203.1.10.1"
+
+// subentriesControl is the RFC 3672 Subentries control, which makes searches
+// return the subentries in scope (and only them) when visible is true.
+type subentriesControl struct {
+	visible bool
+}
+
+func (c *subentriesControl) GetControlType() string {
+	return subentriesOID
+}
+
+func (c *subentriesControl) Encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, subentriesOID, "Control Type (Subentries)"))
+	value := ber.Encode(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nil, "Control Value (Subentries)")
+	value.AppendChild(ber.NewBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, c.visible, "Visibility"))
+	packet.AppendChild(value)
+	return packet
+}
+
+func (c *subentriesControl) String() string {
+	return fmt.Sprintf("Control Type: Subentries (%q)  Visibility: %t", subentriesOID, c.visible)
+}
+
+func resourceLDAPSubentry() *schema.Resource {
+	attributes := configAttributeSchema(
+		"The attributes of the subentry besides cn and subtreeSpecification, e.g. the collective attributes of a collectiveAttributeSubentry.",
+		"The name of the attribute (e.g. c-l).",
+	)
+	attributes.Required = false
+	attributes.Optional = true
+	attributes.MinItems = 0
+
+	return &schema.Resource{
+		Create: resourceLDAPSubentryCreate,
+		Read:   resourceLDAPSubentryRead,
+		Update: resourceLDAPSubentryUpdate,
+		Delete: resourceLDAPSubentryDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceLDAPSubentryImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"dn": {
+				Type:        schema.TypeString,
+				Description: "The DN of the subentry, whose parent is the administrative point (e.g. cn=locality,dc=example,dc=com).",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"subtree_specification": {
+				Type:        schema.TypeString,
+				Description: "The RFC 3672 subtree specification of the subentry (e.g. { base \"ou=people\" }); defaults to the whole administrative area.",
+				Optional:    true,
+				Default:     "{}",
+			},
+			"object_classes": {
+				Type:        schema.TypeSet,
+				Description: "The auxiliary classes of the subentry (e.g. collectiveAttributeSubentry), besides top and subentry.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+			"attributes": attributes,
+			"administrative_roles": {
+				Type:        schema.TypeSet,
+				Description: "The administrative roles (e.g. collectiveAttributeSpecificArea) the parent entry must hold for the subentry to apply; they are added to its administrativeRole attribute and left there on destroy, as other subentries may rely on them.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceLDAPSubentryImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	d.Set("dn", d.Id())
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceLDAPSubentryCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Get("dn").(string)
+
+	if err := addLDAPAdministrativeRoles(client, d); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] ldap_subentry::create - creating subentry %q", dn)
+
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return err
+	}
+	objectClasses := append([]string{"top", "subentry"}, toStringSlice(d.Get("object_classes").(*schema.Set).List())...)
+	request := ldap.NewAddRequest(dn, []ldap.Control{})
+	request.Attribute("objectClass", objectClasses)
+	request.Attribute("cn", []string{parsed.RDNs[0].Attributes[0].Value})
+	request.Attribute("subtreeSpecification", []string{d.Get("subtree_specification").(string)})
+	for name, values := range configAttributes(d.Get("attributes").(*schema.Set)) {
+		request.Attribute(name, values)
+	}
+	if err := client.Add(request); err != nil {
+		log.Printf("[ERROR] ldap_subentry::create - error creating subentry %q: %v", dn, err)
+		return explainEntryExists(client, err, "ldap_subentry", dn)
+	}
+
+	d.SetId(dn)
+	return resourceLDAPSubentryRead(d, meta)
+}
+
+func resourceLDAPSubentryRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_subentry::read - reading subentry %q", dn)
+
+	configured := configAttributes(d.Get("attributes").(*schema.Set))
+	attributes := []string{"objectClass", "subtreeSpecification"}
+	for name := range configured {
+		attributes = append(attributes, name)
+	}
+
+	// subentries are hidden from ordinary searches on servers that implement
+	// RFC 3672 to the letter, so ask for them explicitly
+	request := ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeBaseObject,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		"(objectClass=subentry)",
+		attributes,
+		[]ldap.Control{&subentriesControl{visible: true}},
+	)
+	sr, err := client.Search(request)
+	if err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			log.Printf("[WARN] ldap_subentry::read - subentry %q not found, removing from state", dn)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	if len(sr.Entries) == 0 {
+		log.Printf("[WARN] ldap_subentry::read - %q is not a subentry, removing from state", dn)
+		d.SetId("")
+		return nil
+	}
+	entry := sr.Entries[0]
+
+	objectClasses := []string{}
+	for _, objectClass := range entry.GetEqualFoldAttributeValues("objectClass") {
+		if !strings.EqualFold(objectClass, "top") && !strings.EqualFold(objectClass, "subentry") {
+			objectClasses = append(objectClasses, objectClass)
+		}
+	}
+
+	d.Set("dn", dn)
+	d.Set("subtree_specification", entry.GetEqualFoldAttributeValue("subtreeSpecification"))
+	d.Set("object_classes", matchAttributeNames(objectClasses, toStringSlice(d.Get("object_classes").(*schema.Set).List())))
+	d.Set("attributes", flattenConfigAttributes(entry, configured))
+
+	roles, err := readLDAPAdministrativeRoles(client, dn)
+	if err != nil {
+		return err
+	}
+	// the administrative point may hold other roles, only track ours
+	configuredRoles := toStringSlice(d.Get("administrative_roles").(*schema.Set).List())
+	held := stringSetDifference(configuredRoles, stringSetDifference(configuredRoles, roles))
+	d.Set("administrative_roles", held)
+	return nil
+}
+
+func resourceLDAPSubentryUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+
+	log.Printf("[DEBUG] ldap_subentry::update - performing update on %q", d.Id())
+
+	if d.HasChange("administrative_roles") {
+		if err := addLDAPAdministrativeRoles(client, d); err != nil {
+			return err
+		}
+	}
+
+	modify := ldap.NewModifyRequest(d.Id(), []ldap.Control{})
+	if d.HasChange("subtree_specification") {
+		modify.Replace("subtreeSpecification", []string{d.Get("subtree_specification").(string)})
+	}
+	if d.HasChange("object_classes") {
+		objectClasses := append([]string{"top", "subentry"}, toStringSlice(d.Get("object_classes").(*schema.Set).List())...)
+		modify.Replace("objectClass", objectClasses)
+	}
+	if d.HasChange("attributes") {
+		o, n := d.GetChange("attributes")
+		old, new := configAttributes(o.(*schema.Set)), configAttributes(n.(*schema.Set))
+		for name := range old {
+			if _, ok := new[name]; !ok {
+				modify.Replace(name, []string{})
+			}
+		}
+		for name, values := range new {
+			if !stringSlicesEqual(old[name], values) {
+				modify.Replace(name, values)
+			}
+		}
+	}
+	if len(modify.Changes) > 0 {
+		if err := client.Modify(modify); err != nil {
+			log.Printf("[ERROR] ldap_subentry::update - error updating subentry %q: %v", d.Id(), err)
+			return err
+		}
+	}
+	return resourceLDAPSubentryRead(d, meta)
+}
+
+func resourceLDAPSubentryDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(ldap.Client)
+	dn := d.Id()
+
+	log.Printf("[DEBUG] ldap_subentry::delete - removing subentry %q", dn)
+
+	if err := client.Del(ldap.NewDelRequest(dn, nil)); err != nil {
+		if err, ok := err.(*ldap.Error); ok && err.ResultCode == ldap.LDAPResultNoSuchObject {
+			return nil
+		}
+		log.Printf("[ERROR] ldap_subentry::delete - error removing subentry %q: %v", dn, err)
+		return err
+	}
+	return nil
+}
+
+// administrativePointDN returns the DN of the administrative point of a
+// subentry, which is its parent.
+func administrativePointDN(dn string) (string, error) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed.RDNs) < 2 {
+		return "", fmt.Errorf("subentry %q has no parent entry", dn)
+	}
+	return (&ldap.DN{RDNs: parsed.RDNs[1:]}).String(), nil
+}
+
+// addLDAPAdministrativeRoles adds the configured roles to the
+// administrativeRole attribute of the administrative point of a subentry,
+// keeping the roles it already holds.
+func addLDAPAdministrativeRoles(client ldap.Client, d *schema.ResourceData) error {
+	roles := toStringSlice(d.Get("administrative_roles").(*schema.Set).List())
+	if len(roles) == 0 {
+		return nil
+	}
+	parentDN, err := administrativePointDN(d.Get("dn").(string))
+	if err != nil {
+		return err
+	}
+	current, err := readLDAPAdministrativeRoles(client, d.Get("dn").(string))
+	if err != nil {
+		return err
+	}
+	missing := stringSetDifference(roles, current)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] ldap_subentry::roles - adding administrative roles %v to %q", missing, parentDN)
+
+	modify := ldap.NewModifyRequest(parentDN, []ldap.Control{})
+	modify.Add("administrativeRole", missing)
+	if err := client.Modify(modify); err != nil {
+		log.Printf("[ERROR] ldap_subentry::roles - error adding administrative roles to %q: %v", parentDN, err)
+		return err
+	}
+	return nil
+}
+
+// readLDAPAdministrativeRoles returns the roles held by the administrative
+// point of a subentry.
+func readLDAPAdministrativeRoles(client ldap.Client, dn string) ([]string, error) {
+	parentDN, err := administrativePointDN(dn)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := readConfigEntry(client, parentDN, "administrativeRole")
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return entry.GetEqualFoldAttributeValues("administrativeRole"), nil
+}
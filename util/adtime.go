@@ -0,0 +1,25 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ADIntervalSeconds converts an Active Directory time interval (e.g. maxPwdAge
+// or msDS-LockoutDuration), stored as a negative number of 100-nanosecond
+// ticks, into seconds. The "never" value (the minimum int64) and zero both
+// yield 0.
+func ADIntervalSeconds(value string) (int64, error) {
+	ticks, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %v", value, err)
+	}
+	if ticks == math.MinInt64 {
+		return 0, nil
+	}
+	if ticks < 0 {
+		ticks = -ticks
+	}
+	return ticks / 10000000, nil
+}
@@ -0,0 +1,20 @@
+package util
+
+import "testing"
+
+func TestADIntervalSeconds(t *testing.T) {
+	tests := map[string]int64{
+		"-36288000000000":      3628800, // 42 days
+		"-18000000000":         1800,    // 30 minutes
+		"0":                    0,
+		"-9223372036854775808": 0,
+	}
+	for value, expected := range tests {
+		if seconds, err := ADIntervalSeconds(value); err != nil || seconds != expected {
+			t.Errorf("Invalid conversion of %q, got %d (%v)", value, seconds, err)
+		}
+	}
+	if _, err := ADIntervalSeconds("forever"); err == nil {
+		t.Errorf("Expected an error for a non-numeric interval")
+	}
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2Params are the cost parameters of an Argon2id hash.
+type argon2Params struct {
+	memory  uint32 // in KiB
+	time    uint32
+	threads uint8
+}
+
+// the second recommended option of RFC 9106, for memory-constrained servers
+var defaultArgon2Params = argon2Params{memory: 64 * 1024, time: 3, threads: 4}
+
+const argon2KeySize = 32
+
+// argon2Hash returns the Argon2id hash of a password in the PHC string
+// format used by OpenLDAP's pw-argon2 module and 389-ds, e.g.
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>.
+func argon2Hash(password string, salt []byte, params argon2Params) string {
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeySize)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.memory, params.time, params.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// parseArgon2 returns the salt and cost parameters of an Argon2id hash.
+func parseArgon2(hashed string) ([]byte, argon2Params, bool) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, argon2Params{}, false
+	}
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, argon2Params{}, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || params.time == 0 || params.threads == 0 {
+		return nil, argon2Params{}, false
+	}
+	return salt, params, true
+}
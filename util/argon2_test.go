@@ -0,0 +1,22 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArgon2Hash(t *testing.T) {
+	hashed := argon2Hash("secret", []byte("saltsaltsaltsalt"), defaultArgon2Params)
+	if !strings.HasPrefix(hashed, "$argon2id$v=19$m=65536,t=3,p=4$c2FsdHNhbHRzYWx0c2FsdA$") {
+		t.Errorf("Unexpected hash %s", hashed)
+	}
+	salt, params, ok := parseArgon2(hashed)
+	if !ok || string(salt) != "saltsaltsaltsalt" || params != defaultArgon2Params {
+		t.Errorf("Unexpected salt %q and parameters %+v parsing %s", salt, params, hashed)
+	}
+	for _, invalid := range []string{"", "$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$AA", "$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$AA", "$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$AA", "$argon2id$v=19$m=65536$c2FsdA$AA"} {
+		if _, _, ok := parseArgon2(invalid); ok {
+			t.Errorf("Expected %q not to parse", invalid)
+		}
+	}
+}
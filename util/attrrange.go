@@ -0,0 +1,37 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseAttributeRange parses the name of an attribute returned by Active
+// Directory in ranges, e.g. member;range=1500-2999, into the attribute
+// description without the range option and the bounds of the range; high
+// is -1 for the last range (member;range=3000-*). ok is false for names
+// without a valid range option.
+func ParseAttributeRange(name string) (attribute string, low, high int, ok bool) {
+	options := strings.Split(name, ";")
+	for i, option := range options {
+		if i == 0 || !strings.HasPrefix(strings.ToLower(option), "range=") {
+			continue
+		}
+		bounds := strings.SplitN(option[len("range="):], "-", 2)
+		if len(bounds) != 2 {
+			return "", 0, 0, false
+		}
+		low, err := strconv.Atoi(bounds[0])
+		if err != nil || low < 0 {
+			return "", 0, 0, false
+		}
+		high := -1
+		if bounds[1] != "*" {
+			if high, err = strconv.Atoi(bounds[1]); err != nil || high < low {
+				return "", 0, 0, false
+			}
+		}
+		attribute := strings.Join(append(append([]string{}, options[:i]...), options[i+1:]...), ";")
+		return attribute, low, high, true
+	}
+	return "", 0, 0, false
+}
@@ -0,0 +1,26 @@
+package util
+
+import "testing"
+
+func TestParseAttributeRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		attribute string
+		low, high int
+	}{
+		{"member;range=0-1499", "member", 0, 1499},
+		{"member;Range=1500-*", "member", 1500, -1},
+		{"userCertificate;binary;range=0-9", "userCertificate;binary", 0, 9},
+	}
+	for _, test := range tests {
+		attribute, low, high, ok := ParseAttributeRange(test.name)
+		if !ok || attribute != test.attribute || low != test.low || high != test.high {
+			t.Errorf("Invalid parsing of %q, got %q %d-%d (%v)", test.name, attribute, low, high, ok)
+		}
+	}
+	for _, invalid := range []string{"member", "range=0-1", "member;range=0", "member;range=x-1", "member;range=5-1", "member;lang-en"} {
+		if _, _, _, ok := ParseAttributeRange(invalid); ok {
+			t.Errorf("Expected %q not to parse", invalid)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+// SplitRDN splits a DN string into its leftmost RDN and the DN of its parent,
+// at the first comma that is not escaped with a backslash or quoted, keeping
+// both parts exactly as written. The parent of a single RDN is empty.
+func SplitRDN(dn string) (rdn, parent string) {
+	quoted := false
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ',', ';':
+			if !quoted {
+				return dn[:i], trimLeadingSpaces(dn[i+1:])
+			}
+		}
+	}
+	return dn, ""
+}
+
+func trimLeadingSpaces(s string) string {
+	for len(s) > 0 && s[0] == ' ' {
+		s = s[1:]
+	}
+	return s
+}
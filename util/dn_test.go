@@ -0,0 +1,20 @@
+package util
+
+import "testing"
+
+func TestSplitRDN(t *testing.T) {
+	tests := []struct {
+		dn, rdn, parent string
+	}{
+		{"ou=people,dc=example,dc=com", "ou=people", "dc=example,dc=com"},
+		{`cn=Smith\, John,ou=people,dc=example`, `cn=Smith\, John`, "ou=people,dc=example"},
+		{`cn="Smith, John", dc=example`, `cn="Smith, John"`, "dc=example"},
+		{`cn=a\\,dc=example`, `cn=a\\`, "dc=example"},
+		{"dc=com", "dc=com", ""},
+	}
+	for _, test := range tests {
+		if rdn, parent := SplitRDN(test.dn); rdn != test.rdn || parent != test.parent {
+			t.Errorf("Invalid split of %q, got %q and %q", test.dn, rdn, parent)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ParseGUID converts the textual representation of a GUID (e.g.
+// 6f9619ff-8b86-d011-b42d-00c04fc964ff, optionally enclosed in braces) into
+// the binary form stored by Active Directory in the objectGUID attribute,
+// where the first three groups are little-endian.
+func ParseGUID(guid string) ([]byte, error) {
+	s := strings.TrimSuffix(strings.TrimPrefix(guid, "{"), "}")
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return nil, fmt.Errorf("invalid GUID %q", guid)
+	}
+	raw, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GUID %q: %v", guid, err)
+	}
+
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b[0:], binary.BigEndian.Uint32(raw[0:]))
+	binary.LittleEndian.PutUint16(b[4:], binary.BigEndian.Uint16(raw[4:]))
+	binary.LittleEndian.PutUint16(b[6:], binary.BigEndian.Uint16(raw[6:]))
+	copy(b[8:], raw[8:])
+	return b, nil
+}
+
+// GUIDToString converts the binary form of a GUID, as found in the
+// objectGUID attribute, into its canonical textual representation.
+func GUIDToString(b []byte) (string, error) {
+	if len(b) != 16 {
+		return "", fmt.Errorf("invalid GUID: expected 16 bytes, got %d", len(b))
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:]),
+		binary.LittleEndian.Uint16(b[4:]),
+		binary.LittleEndian.Uint16(b[6:]),
+		b[8:10],
+		b[10:16],
+	), nil
+}
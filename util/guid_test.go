@@ -0,0 +1,44 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+var guid = []byte{
+	0xff, 0x19, 0x96, 0x6f,
+	0x86, 0x8b,
+	0x11, 0xd0,
+	0xb4, 0x2d,
+	0x00, 0xc0, 0x4f, 0xc9, 0x64, 0xff,
+}
+
+func TestParseGUID(t *testing.T) {
+	for _, s := range []string{"6f9619ff-8b86-d011-b42d-00c04fc964ff", "{6F9619FF-8B86-D011-B42D-00C04FC964FF}"} {
+		b, err := ParseGUID(s)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", s, err)
+		}
+		if !bytes.Equal(b, guid) {
+			t.Errorf("Invalid binary GUID for %q, got %x", s, b)
+		}
+	}
+	for _, invalid := range []string{"", "6f9619ff8b86d011b42d00c04fc964ff", "6f9619ff-8b86-d011-b42d-00c04fc964fg", "cn=admin,dc=example,dc=com"} {
+		if _, err := ParseGUID(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestGUIDToString(t *testing.T) {
+	s, err := GUIDToString(guid)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding GUID: %v", err)
+	}
+	if s != "6f9619ff-8b86-d011-b42d-00c04fc964ff" {
+		t.Errorf("Invalid GUID string, got %s", s)
+	}
+	if _, err := GUIDToString(guid[:8]); err == nil {
+		t.Error("Expected an error decoding a truncated GUID")
+	}
+}
@@ -0,0 +1,20 @@
+package util
+
+import "fmt"
+
+// NextIDNumber returns the number following the highest of the used numbers
+// within [min, max], or min when none is used, failing when the range is
+// exhausted. Gaps are never reused, as files may still belong to the IDs of
+// deleted accounts.
+func NextIDNumber(used []int, min, max int) (int, error) {
+	next := min
+	for _, id := range used {
+		if id >= next && id <= max {
+			next = id + 1
+		}
+	}
+	if next > max {
+		return 0, fmt.Errorf("no free ID number left in [%d, %d]", min, max)
+	}
+	return next, nil
+}
@@ -0,0 +1,24 @@
+package util
+
+import "testing"
+
+func TestNextIDNumber(t *testing.T) {
+	tests := []struct {
+		used     []int
+		min, max int
+		next     int
+	}{
+		{nil, 10000, 60000, 10000},
+		{[]int{10000, 10001, 10005}, 10000, 60000, 10006},
+		{[]int{500, 10002, 65534}, 10000, 60000, 10003},
+		{[]int{9999}, 10000, 60000, 10000},
+	}
+	for _, test := range tests {
+		if next, err := NextIDNumber(test.used, test.min, test.max); err != nil || next != test.next {
+			t.Errorf("Invalid next ID number for %v in [%d, %d], got %d (%v)", test.used, test.min, test.max, next, err)
+		}
+	}
+	if _, err := NextIDNumber([]int{10, 11, 12}, 10, 12); err == nil {
+		t.Errorf("Expected an error for an exhausted range")
+	}
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyValue is a single key=value option, as found in OpenLDAP directives such
+// as olcSyncrepl or olcLimits.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// ParseKeyValues splits a whitespace separated list of key=value options into
+// its components; values may be enclosed in double quotes to include spaces,
+// and a backslash escapes the following character inside quotes. Options
+// without an equal sign are returned with an empty value.
+func ParseKeyValues(s string) ([]KeyValue, error) {
+	result := []KeyValue{}
+	for i := 0; i < len(s); {
+		// skip the separating whitespace
+		if s[i] == ' ' || s[i] == '\t' || s[i] == '\n' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' && s[i] != '\t' && s[i] != '\n' {
+			i++
+		}
+		kv := KeyValue{Key: s[start:i]}
+		if i < len(s) && s[i] == '=' {
+			i++
+			var value strings.Builder
+			if i < len(s) && s[i] == '"' {
+				i++
+				closed := false
+				for i < len(s) {
+					if s[i] == '\\' && i+1 < len(s) {
+						value.WriteByte(s[i+1])
+						i += 2
+						continue
+					}
+					if s[i] == '"' {
+						closed = true
+						i++
+						break
+					}
+					value.WriteByte(s[i])
+					i++
+				}
+				if !closed {
+					return nil, fmt.Errorf("unterminated quoted value for %q", kv.Key)
+				}
+			} else {
+				for i < len(s) && s[i] != ' ' && s[i] != '\t' && s[i] != '\n' {
+					value.WriteByte(s[i])
+					i++
+				}
+			}
+			kv.Value = value.String()
+		}
+		result = append(result, kv)
+	}
+	return result, nil
+}
+
+// FormatKeyValues renders a list of options as accepted by ParseKeyValues,
+// quoting the values that contain whitespace, quotes or are empty.
+func FormatKeyValues(kvs []KeyValue) string {
+	parts := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		parts = append(parts, fmt.Sprintf("%s=%s", kv.Key, QuoteValue(kv.Value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// QuoteValue encloses a value in double quotes if it is empty or contains
+// whitespace or quotes, escaping quotes and backslashes.
+func QuoteValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"\\") {
+		return value
+	}
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	return fmt.Sprintf("\"%s\"", value)
+}
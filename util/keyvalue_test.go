@@ -0,0 +1,38 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	kvs, err := ParseKeyValues(`rid=001 provider=ldap://ldap1.example.com binddn="cn=replicator,dc=example,dc=com" retry="60 +" credentials="se\"cret" flag`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []KeyValue{
+		{"rid", "001"},
+		{"provider", "ldap://ldap1.example.com"},
+		{"binddn", "cn=replicator,dc=example,dc=com"},
+		{"retry", "60 +"},
+		{"credentials", "se\"cret"},
+		{"flag", ""},
+	}
+	if !reflect.DeepEqual(kvs, expected) {
+		t.Errorf("Invalid key values, got %v", kvs)
+	}
+	if _, err := ParseKeyValues(`retry="60 +`); err == nil {
+		t.Error("Expected an error parsing an unterminated value")
+	}
+}
+
+func TestFormatKeyValues(t *testing.T) {
+	s := FormatKeyValues([]KeyValue{{"rid", "001"}, {"retry", "60 +"}, {"credentials", "se\"cret"}})
+	if s != `rid=001 retry="60 +" credentials="se\"cret"` {
+		t.Errorf("Invalid formatted string, got %s", s)
+	}
+	kvs, err := ParseKeyValues(s)
+	if err != nil || len(kvs) != 3 || kvs[2].Value != "se\"cret" {
+		t.Errorf("Invalid round trip, got %v (%v)", kvs, err)
+	}
+}
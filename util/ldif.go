@@ -0,0 +1,138 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// LDIFAttribute is an attribute value of an LDIF entry.
+type LDIFAttribute struct {
+	Name  string
+	Value string
+}
+
+// LDIFEntry is an entry of an LDIF file, with its attribute values in file
+// order.
+type LDIFEntry struct {
+	DN         string
+	Attributes []LDIFAttribute
+}
+
+// ParseLDIF parses the content records of an LDIF file (RFC 2849), as
+// exported by ldapsearch or slapcat. Base64 values (attr:: ...) are
+// decoded and folded lines joined; change records other than adds and
+// values referenced by URL (attr:< ...) are rejected.
+func ParseLDIF(text string) ([]LDIFEntry, error) {
+	// unfold lines first: a line starting with a space continues the
+	// previous one
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, " ") && len(lines) > 0 && lines[len(lines)-1] != "" {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	entries := []LDIFEntry{}
+	var entry *LDIFEntry
+	for i, line := range lines {
+		if line == "" {
+			entry = nil
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon <= 0 {
+			return nil, fmt.Errorf("line %d: expected attribute: value, got %q", i+1, line)
+		}
+		name, value := line[:colon], line[colon+1:]
+		switch {
+		case strings.HasPrefix(value, ":"):
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid base64 value of %s: %v", i+1, name, err)
+			}
+			value = string(decoded)
+		case strings.HasPrefix(value, "<"):
+			return nil, fmt.Errorf("line %d: values referenced by URL are not supported", i+1)
+		default:
+			value = strings.TrimLeft(value, " ")
+		}
+
+		if entry == nil {
+			switch {
+			case strings.EqualFold(name, "version") && len(entries) == 0:
+				continue
+			case !strings.EqualFold(name, "dn"):
+				return nil, fmt.Errorf("line %d: expected a dn, got %s", i+1, name)
+			}
+			entries = append(entries, LDIFEntry{DN: value})
+			entry = &entries[len(entries)-1]
+			continue
+		}
+		if strings.EqualFold(name, "changetype") {
+			if !strings.EqualFold(value, "add") {
+				return nil, fmt.Errorf("line %d: only content and add records are supported, got changetype %s", i+1, value)
+			}
+			continue
+		}
+		if strings.EqualFold(name, "control") {
+			return nil, fmt.Errorf("line %d: controls are not supported", i+1)
+		}
+		entry.Attributes = append(entry.Attributes, LDIFAttribute{Name: name, Value: value})
+	}
+	return entries, nil
+}
+
+// the length LDIF lines are folded at
+const ldifLineLength = 76
+
+// FormatLDIF renders entries as the content records of an LDIF file,
+// separated by blank lines. Values that are not safe strings per RFC 2849
+// (non-ASCII, control characters, or leading or trailing special
+// characters) are base64 encoded, and long lines are folded.
+func FormatLDIF(entries []LDIFEntry) string {
+	var buffer strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(formatLDIFLine("dn", entry.DN))
+		for _, attribute := range entry.Attributes {
+			buffer.WriteString(formatLDIFLine(attribute.Name, attribute.Value))
+		}
+	}
+	return buffer.String()
+}
+
+func formatLDIFLine(name, value string) string {
+	line := name + ": " + value
+	if value == "" {
+		line = name + ":"
+	} else if !isLDIFSafeString(value) {
+		line = name + ":: " + base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	var buffer strings.Builder
+	for len(line) > ldifLineLength {
+		buffer.WriteString(line[:ldifLineLength] + "\n")
+		line = " " + line[ldifLineLength:]
+	}
+	buffer.WriteString(line + "\n")
+	return buffer.String()
+}
+
+func isLDIFSafeString(value string) bool {
+	if value[0] == ' ' || value[0] == ':' || value[0] == '<' || value[len(value)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c == 0 || c == '\n' || c == '\r' || c > 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLDIF(t *testing.T) {
+	ldif := `version: 1
+
+# a user
+dn: uid=jdoe,ou=people,dc=example,dc=com
+objectClass: inetOrgPerson
+cn: John
+  Doe
+sn:  Doe
+description:: w6l0w6k=
+mail: jdoe@example.com
+mail: john.doe@example.com
+
+dn:: Y249w6l0w6ksZGM9ZXhhbXBsZSxkYz1jb20=
+changetype: add
+objectClass: organizationalRole
+`
+	expected := []LDIFEntry{
+		{
+			DN: "uid=jdoe,ou=people,dc=example,dc=com",
+			Attributes: []LDIFAttribute{
+				{"objectClass", "inetOrgPerson"},
+				{"cn", "John Doe"},
+				{"sn", "Doe"},
+				{"description", "été"},
+				{"mail", "jdoe@example.com"},
+				{"mail", "john.doe@example.com"},
+			},
+		},
+		{
+			DN:         "cn=été,dc=example,dc=com",
+			Attributes: []LDIFAttribute{{"objectClass", "organizationalRole"}},
+		},
+	}
+	entries, err := ParseLDIF(ldif)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+
+	for _, invalid := range []string{
+		"cn: x",
+		"dn: cn=x\nchangetype: delete",
+		"dn: cn=x\njpegPhoto:< file:///tmp/x.jpg",
+		"dn: cn=x\ndescription:: !!",
+		"dn: cn=x\nnot a value",
+	} {
+		if _, err := ParseLDIF(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestFormatLDIF(t *testing.T) {
+	entries := []LDIFEntry{
+		{
+			DN: "cn=été,dc=example,dc=com",
+			Attributes: []LDIFAttribute{
+				{"objectClass", "organizationalRole"},
+				{"description", "first line\nsecond line"},
+				{"street", " leading space"},
+				{"info", ":colon"},
+				{"seeAlso", ""},
+				{"l", strings.Repeat("x", 80)},
+			},
+		},
+		{DN: "cn=b,dc=example,dc=com"},
+	}
+	expected := `dn:: Y249w6l0w6ksZGM9ZXhhbXBsZSxkYz1jb20=
+objectClass: organizationalRole
+description:: Zmlyc3QgbGluZQpzZWNvbmQgbGluZQ==
+street:: IGxlYWRpbmcgc3BhY2U=
+info:: OmNvbG9u
+seeAlso:
+l: ` + strings.Repeat("x", 73) + `
+ ` + strings.Repeat("x", 7) + `
+
+dn: cn=b,dc=example,dc=com
+`
+	formatted := FormatLDIF(entries)
+	if formatted != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, formatted)
+	}
+	if parsed, err := ParseLDIF(formatted); err != nil || !reflect.DeepEqual(parsed, entries) {
+		t.Errorf("Expected %+v to round-trip, got %+v (%v)", entries, parsed, err)
+	}
+}
@@ -0,0 +1,127 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// the equality matching rules NormalizeValue knows, by lowercase name and
+// OID; distinguishedNameMatch needs a DN parser and is left to the caller
+var equalityNormalizers = map[string]func(string) string{
+	"caseignorematch":            normalizeCaseIgnore,
+	"2.5.13.2":                   normalizeCaseIgnore,
+	"caseignoreia5match":         normalizeCaseIgnore,
+	"1.3.6.1.4.1.1466.109.114.2": normalizeCaseIgnore,
+	"caseignorelistmatch":        normalizeCaseIgnoreList,
+	"2.5.13.11":                  normalizeCaseIgnoreList,
+	"caseexactmatch":             normalizeSpaces,
+	"2.5.13.5":                   normalizeSpaces,
+	"caseexactia5match":          normalizeSpaces,
+	"1.3.6.1.4.1.1466.109.114.1": normalizeSpaces,
+	"telephonenumbermatch":       normalizeTelephoneNumber,
+	"2.5.13.20":                  normalizeTelephoneNumber,
+	"numericstringmatch":         normalizeNumericString,
+	"2.5.13.8":                   normalizeNumericString,
+	"integermatch":               normalizeInteger,
+	"2.5.13.14":                  normalizeInteger,
+	"booleanmatch":               strings.ToUpper,
+	"2.5.13.13":                  strings.ToUpper,
+	"generalizedtimematch":       normalizeGeneralizedTime,
+	"2.5.13.27":                  normalizeGeneralizedTime,
+	"octetstringmatch":           func(value string) string { return value },
+	"2.5.13.17":                  func(value string) string { return value },
+}
+
+// the syntaxes NormalizeSyntaxValue knows, by OID; the DN syntax is left to
+// the caller
+var syntaxNormalizers = map[string]func(string) string{
+	"1.3.6.1.4.1.1466.115.121.1.50": normalizeTelephoneSeparators, // Telephone Number
+	"1.3.6.1.4.1.1466.115.121.1.41": normalizePostalAddress,       // Postal Address
+	"1.3.6.1.4.1.1466.115.121.1.36": normalizeNumericString,       // Numeric String
+	"1.3.6.1.4.1.1466.115.121.1.27": normalizeInteger,             // INTEGER
+	"1.3.6.1.4.1.1466.115.121.1.7":  strings.ToUpper,              // Boolean
+	"1.3.6.1.4.1.1466.115.121.1.24": normalizeGeneralizedTime,     // Generalized Time
+}
+
+// NormalizeSyntaxValue returns the canonical form of an attribute value of
+// the given syntax (e.g. a telephone number without separators), so that
+// values only differing in their formatting compare identical, and whether
+// the syntax is known. The syntax is an OID, optionally followed by a length
+// bound such as {32}.
+func NormalizeSyntaxValue(syntax, value string) (string, bool) {
+	if i := strings.Index(syntax, "{"); i >= 0 {
+		syntax = syntax[:i]
+	}
+	normalize, ok := syntaxNormalizers[strings.TrimSpace(syntax)]
+	if !ok {
+		return value, false
+	}
+	return normalize(value), true
+}
+
+// NormalizeValue returns the form of an attribute value under which the
+// values equal per the given equality matching rule (e.g. caseIgnoreMatch or
+// telephoneNumberMatch) are identical, and whether the rule is known.
+func NormalizeValue(rule, value string) (string, bool) {
+	normalize, ok := equalityNormalizers[strings.ToLower(rule)]
+	if !ok {
+		return value, false
+	}
+	return normalize(value), true
+}
+
+// normalizeSpaces removes the leading and trailing spaces of a value and
+// collapses the others, as they are insignificant to the string rules.
+func normalizeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func normalizeCaseIgnore(value string) string {
+	return strings.ToLower(normalizeSpaces(value))
+}
+
+// normalizeCaseIgnoreList normalizes each line of a $-separated value.
+func normalizeCaseIgnoreList(value string) string {
+	return strings.ToLower(normalizePostalAddress(value))
+}
+
+// normalizePostalAddress trims and collapses the spaces of each line of a
+// postal address, so that "1 Main St $ Springfield" and "1 Main St$Springfield"
+// are written the same.
+func normalizePostalAddress(value string) string {
+	lines := strings.Split(value, "$")
+	for i, line := range lines {
+		lines[i] = normalizeSpaces(line)
+	}
+	return strings.Join(lines, "$")
+}
+
+// normalizeTelephoneSeparators removes the spaces and hyphens grouping the
+// digits of a telephone number, e.g. +1 555 010-0100, the only characters
+// telephoneNumberMatch ignores: "(555)0100" and "5550100" are different
+// values to the server.
+func normalizeTelephoneSeparators(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+func normalizeTelephoneNumber(value string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(value))
+}
+
+func normalizeNumericString(value string) string {
+	return strings.Replace(value, " ", "", -1)
+}
+
+func normalizeInteger(value string) string {
+	if i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+		return strconv.FormatInt(i, 10)
+	}
+	return value
+}
+
+func normalizeGeneralizedTime(value string) string {
+	if t, err := ParseTimestamp(value); err == nil {
+		return FormatGeneralizedTime(t)
+	}
+	return value
+}
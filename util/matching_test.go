@@ -0,0 +1,56 @@
+package util
+
+import "testing"
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		rule, a, b string
+		equal      bool
+	}{
+		{"caseIgnoreMatch", "John  Smith ", "john smith", true},
+		{"2.5.13.2", "John", "Jon", false},
+		{"caseExactMatch", " John Smith", "John Smith", true},
+		{"caseExactMatch", "John", "john", false},
+		{"telephoneNumberMatch", "+1 555-0100", "+15550100", true},
+		{"telephoneNumberMatch", "(555)0100", "5550100", false},
+		{"telephoneNumberMatch", "555.0100", "5550100", false},
+		{"numericStringMatch", "123 456", "123456", true},
+		{"integerMatch", "+042", "42", true},
+		{"booleanMatch", "true", "TRUE", true},
+		{"generalizedTimeMatch", "20210401140000+0200", "20210401120000Z", true},
+		{"octetStringMatch", "a", "A", false},
+	}
+	for _, test := range tests {
+		a, ok := NormalizeValue(test.rule, test.a)
+		b, _ := NormalizeValue(test.rule, test.b)
+		if !ok || (a == b) != test.equal {
+			t.Errorf("Expected %q and %q equal (%v) under %s, got %q and %q", test.a, test.b, test.equal, test.rule, a, b)
+		}
+	}
+	if a, _ := NormalizeValue("caseIgnoreListMatch", "1 Main St $ Springfield"); a != "1 main st$springfield" {
+		t.Errorf("Unexpected normalized list %q", a)
+	}
+	if _, ok := NormalizeValue("distinguishedNameMatch", "cn=a"); ok {
+		t.Errorf("Expected distinguishedNameMatch to be left to the caller")
+	}
+}
+
+func TestNormalizeSyntaxValue(t *testing.T) {
+	tests := []struct {
+		syntax, value, normalized string
+	}{
+		{"1.3.6.1.4.1.1466.115.121.1.50", "+1 555 010-0100", "+15550100100"},
+		{"1.3.6.1.4.1.1466.115.121.1.50{32}", "+1 (555) 010-0100", "+1(555)0100100"},
+		{"1.3.6.1.4.1.1466.115.121.1.50", "+1.555.0100", "+1.555.0100"},
+		{"1.3.6.1.4.1.1466.115.121.1.41", " 1 Main  St $Springfield ", "1 Main St$Springfield"},
+		{"1.3.6.1.4.1.1466.115.121.1.27", "007", "7"},
+	}
+	for _, test := range tests {
+		if normalized, ok := NormalizeSyntaxValue(test.syntax, test.value); !ok || normalized != test.normalized {
+			t.Errorf("Invalid normalization of %q, got %q (%v)", test.value, normalized, ok)
+		}
+	}
+	if _, ok := NormalizeSyntaxValue("1.3.6.1.4.1.1466.115.121.1.15", "a"); ok {
+		t.Errorf("Expected Directory String values to be left as they are")
+	}
+}
@@ -0,0 +1,113 @@
+package util
+
+import (
+	"strings"
+)
+
+// the styles of the dn clauses of access rules, as slapd writes them back
+var olcAccessDNStyles = map[string]string{
+	"":           "base",
+	"exact":      "base",
+	"base":       "base",
+	"baseobject": "base",
+	"one":        "one",
+	"onelevel":   "one",
+	"sub":        "subtree",
+	"subtree":    "subtree",
+	"children":   "children",
+}
+
+// NormalizeOlcAccess returns the form of an olcAccess rule (without its {n}
+// index) under which the rules slapd considers equal are identical: slapd
+// rewrites the rules it is given, e.g. "to dn=\"dc=Example, dc=com\"" into
+// "to dn.base=\"dc=example,dc=com\"", so the written and read back forms of a
+// rule must be compared once normalized.
+func NormalizeOlcAccess(rule string) string {
+	tokens := splitOlcAccess(rule)
+	normalized := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		key, value, ok := splitOlcAccessClause(token)
+		switch {
+		case !ok:
+			token = strings.ToLower(token)
+			if token == "stop" {
+				// the default control of by clauses
+				continue
+			}
+		case key == "dn" || strings.HasPrefix(key, "dn."):
+			style, isDN := olcAccessDNStyles[strings.TrimPrefix(strings.TrimPrefix(key, "dn"), ".")]
+			if isDN {
+				token = "dn." + style + "=\"" + normalizeOlcAccessDN(value) + "\""
+			} else {
+				token = key + "=\"" + value + "\""
+			}
+		case key == "attr" || key == "attrs":
+			token = "attrs=" + strings.ToLower(value)
+		default:
+			token = key + "=" + value
+		}
+		normalized = append(normalized, token)
+	}
+	return strings.Join(normalized, " ")
+}
+
+// splitOlcAccess splits a rule on the spaces outside of double quotes.
+func splitOlcAccess(rule string) []string {
+	tokens := []string{}
+	var token strings.Builder
+	quoted, escaped := false, false
+	for _, r := range rule {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+			continue
+		}
+		token.WriteRune(r)
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+// splitOlcAccessClause splits a key=value clause of a rule, lowercasing the
+// key and unquoting the value.
+func splitOlcAccessClause(token string) (key, value string, ok bool) {
+	i := strings.Index(token, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+	key, value = strings.ToLower(token[:i]), token[i+1:]
+	if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, true
+}
+
+// normalizeOlcAccessDN lowercases a DN and drops the spaces around its
+// separators, as most naming attributes compare case-insensitively.
+func normalizeOlcAccessDN(dn string) string {
+	rdns := []string{}
+	for rest := strings.TrimSpace(dn); rest != ""; {
+		var rdn string
+		rdn, rest = SplitRDN(rest)
+		components := strings.Split(strings.TrimSpace(rdn), "+")
+		for i, component := range components {
+			if j := strings.Index(component, "="); j >= 0 {
+				component = strings.TrimSpace(component[:j]) + "=" + strings.TrimSpace(component[j+1:])
+			}
+			components[i] = component
+		}
+		rdns = append(rdns, strings.Join(components, "+"))
+	}
+	return strings.ToLower(strings.Join(rdns, ","))
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestNormalizeOlcAccess(t *testing.T) {
+	equal := map[string]string{
+		"to * by * read": "to *  by * read",
+		`to dn="dc=Example, dc=com" by self write`:                                                `to dn.base="dc=example,dc=com" by self write`,
+		`to dn.sub="ou=People,dc=example,dc=com" by dn.exact="cn=Admin,dc=example,dc=com" manage`: `to dn.subtree="ou=people,dc=example,dc=com" by dn.base="cn=admin,dc=example,dc=com" manage`,
+		"to attr=userPassword,shadowLastChange by self write by anonymous auth by * none stop":    "to attrs=userPassword,shadowLastChange by self write by anonymous auth by * none",
+		"TO *\n  BY users read": "to * by users read",
+	}
+	for written, read := range equal {
+		if NormalizeOlcAccess(written) != NormalizeOlcAccess(read) {
+			t.Errorf("Expected %q and %q to normalize alike, got %q and %q", written, read, NormalizeOlcAccess(written), NormalizeOlcAccess(read))
+		}
+	}
+
+	different := map[string]string{
+		"to * by * read": "to * by * write",
+		`to dn.base="dc=example,dc=com" by * read`: `to dn.subtree="dc=example,dc=com" by * read`,
+		`to dn.regex="^uid=([^,]+)" by * read`:     `to dn.regex="^UID=([^,]+)" by * read`,
+		"to * by * read continue":                  "to * by * read",
+	}
+	for a, b := range different {
+		if NormalizeOlcAccess(a) == NormalizeOlcAccess(b) {
+			t.Errorf("Expected %q and %q to normalize differently, got %q", a, b, NormalizeOlcAccess(a))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var orderedIndex = regexp.MustCompile(`^\{(-?\d+)\}`)
+
+// SplitOrderedValue splits a value of an X-ORDERED 'VALUES' attribute (as
+// used by OpenLDAP's cn=config, e.g. "{0}to * by * read") into its index and
+// its actual content; values without an index prefix are returned with an
+// index of -1.
+func SplitOrderedValue(value string) (int, string) {
+	m := orderedIndex.FindStringSubmatch(value)
+	if m == nil {
+		return -1, value
+	}
+	i, err := strconv.Atoi(m[1])
+	if err != nil {
+		return -1, value
+	}
+	return i, value[len(m[0]):]
+}
+
+// SortOrderedValues sorts the values of an X-ORDERED 'VALUES' attribute by
+// their index and returns them with the index prefix removed.
+func SortOrderedValues(values []string) []string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := SplitOrderedValue(sorted[i])
+		b, _ := SplitOrderedValue(sorted[j])
+		return a < b
+	})
+	for i, value := range sorted {
+		_, sorted[i] = SplitOrderedValue(value)
+	}
+	return sorted
+}
+
+// OrderedInsert is a value to be added at a given position of an ordered
+// attribute.
+type OrderedInsert struct {
+	Index int
+	Value string
+}
+
+// OrderedDiff computes the minimal set of index-based deletions and
+// insertions that turn the old list of values into the new one, keeping the
+// longest common subsequence untouched. Deletions are returned in descending
+// order and insertions in ascending order, so that applying all deletions
+// first and then all insertions, one after the other, never invalidates the
+// indexes of the operations still to be applied.
+func OrderedDiff(old, new []string) ([]int, []OrderedInsert) {
+	// classic dynamic programming LCS table
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	keptOld := make([]bool, len(old))
+	keptNew := make([]bool, len(new))
+	for i, j := 0, 0; i < len(old) && j < len(new); {
+		switch {
+		case old[i] == new[j]:
+			keptOld[i], keptNew[j] = true, true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	deletes := []int{}
+	for i := len(old) - 1; i >= 0; i-- {
+		if !keptOld[i] {
+			deletes = append(deletes, i)
+		}
+	}
+	inserts := []OrderedInsert{}
+	for j := range new {
+		if !keptNew[j] {
+			inserts = append(inserts, OrderedInsert{Index: j, Value: new[j]})
+		}
+	}
+	return deletes, inserts
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOrderedValue(t *testing.T) {
+	if i, v := SplitOrderedValue("{12}to * by * read"); i != 12 || v != "to * by * read" {
+		t.Errorf("Invalid split, got %d and %q", i, v)
+	}
+	if i, v := SplitOrderedValue("to * by * read"); i != -1 || v != "to * by * read" {
+		t.Errorf("Invalid split of unindexed value, got %d and %q", i, v)
+	}
+}
+
+func TestSortOrderedValues(t *testing.T) {
+	sorted := SortOrderedValues([]string{"{2}c", "{0}a", "{1}b"})
+	if !reflect.DeepEqual(sorted, []string{"a", "b", "c"}) {
+		t.Errorf("Invalid sort, got %v", sorted)
+	}
+}
+
+// apply replays the operations returned by OrderedDiff on a copy of old.
+func apply(old []string, deletes []int, inserts []OrderedInsert) []string {
+	result := append([]string{}, old...)
+	for _, i := range deletes {
+		result = append(result[:i], result[i+1:]...)
+	}
+	for _, insert := range inserts {
+		result = append(result[:insert.Index], append([]string{insert.Value}, result[insert.Index:]...)...)
+	}
+	return result
+}
+
+func TestOrderedDiff(t *testing.T) {
+	cases := []struct {
+		old, new []string
+		changes  int
+	}{
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{[]string{}, []string{"a", "b"}, 2},
+		{[]string{"a", "b"}, []string{}, 2},
+		{[]string{"a", "b", "c", "d"}, []string{"a", "c", "e", "d"}, 2},
+		{[]string{"a", "b", "c"}, []string{"c", "a", "b"}, 2},
+		{[]string{"x", "a", "y", "b"}, []string{"a", "z", "b", "w"}, 4},
+	}
+	for _, c := range cases {
+		deletes, inserts := OrderedDiff(c.old, c.new)
+		if len(deletes)+len(inserts) != c.changes {
+			t.Errorf("Invalid number of changes from %v to %v, expected %d got %d", c.old, c.new, c.changes, len(deletes)+len(inserts))
+		}
+		if result := apply(c.old, deletes, inserts); !reflect.DeepEqual(result, c.new) {
+			t.Errorf("Invalid diff from %v to %v, got %v", c.old, c.new, result)
+		}
+	}
+}
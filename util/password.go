@@ -0,0 +1,149 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PasswordSchemes lists the schemes supported by HashPassword.
+var PasswordSchemes = []string{"SSHA", "SSHA256", "SSHA512", "PBKDF2-SHA256", "PBKDF2-SHA512", "CRYPT", "ARGON2"}
+
+const (
+	saltSize         = 16
+	pbkdf2Iterations = 10000
+)
+
+// ab64 is the base64 encoding underlying the "adapted base64" of the PBKDF2
+// schemes of OpenLDAP's pw-pbkdf2 module and 389-ds, which have no padding;
+// callers swap '+' for '.' themselves.
+var ab64 = base64.RawStdEncoding
+
+// HashPassword returns the salted hash of a password in the given scheme,
+// prefixed with the scheme in braces (e.g. {SSHA}...), as accepted by the
+// userPassword-like attributes of OpenLDAP (olcRootPW) and 389-ds
+// (nsslapd-rootpw).
+func HashPassword(scheme, password string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	scheme = strings.ToUpper(scheme)
+	if scheme == "CRYPT" {
+		// crypt(3) salts are made of the characters of its base64 alphabet
+		for i := range salt {
+			salt[i] = cryptAlphabet[int(salt[i])%len(cryptAlphabet)]
+		}
+	}
+	return hashPassword(scheme, password, salt, defaultIterations(scheme))
+}
+
+func defaultIterations(scheme string) int {
+	if scheme == "CRYPT" {
+		return sha512CryptRounds
+	}
+	return pbkdf2Iterations
+}
+
+// VerifyPassword reports whether a hash produced by HashPassword (or by the
+// server, in one of the supported schemes) matches the password.
+func VerifyPassword(hashed, password string) bool {
+	if !strings.HasPrefix(hashed, "{") {
+		return false
+	}
+	i := strings.Index(hashed, "}")
+	if i < 0 {
+		return false
+	}
+	scheme, value := strings.ToUpper(hashed[1:i]), hashed[i+1:]
+
+	var salt []byte
+	iterations := 0
+	switch scheme {
+	case "SSHA", "SSHA256", "SSHA512":
+		raw, err := base64.StdEncoding.DecodeString(value)
+		size := saltedHash(scheme).Size()
+		if err != nil || len(raw) <= size {
+			return false
+		}
+		salt = raw[size:]
+	case "PBKDF2-SHA256", "PBKDF2-SHA512":
+		parts := strings.Split(value, "$")
+		if len(parts) != 3 {
+			return false
+		}
+		n, err := strconv.Atoi(parts[0])
+		if err != nil || n <= 0 {
+			return false
+		}
+		s, err := ab64.DecodeString(strings.ReplaceAll(parts[1], ".", "+"))
+		if err != nil {
+			return false
+		}
+		salt, iterations = s, n
+	case "CRYPT":
+		s, rounds, ok := parseSHA512Crypt(value)
+		if !ok {
+			return false
+		}
+		salt, iterations = []byte(s), rounds
+	case "ARGON2":
+		s, params, ok := parseArgon2(value)
+		if !ok {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(argon2Hash(password, s, params)), []byte(value)) == 1
+	default:
+		return false
+	}
+
+	expected, err := hashPassword(scheme, password, salt, iterations)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected[i+1:]), []byte(value)) == 1
+}
+
+func hashPassword(scheme, password string, salt []byte, iterations int) (string, error) {
+	switch scheme {
+	case "SSHA", "SSHA256", "SSHA512":
+		h := saltedHash(scheme)
+		h.Write([]byte(password))
+		h.Write(salt)
+		return fmt.Sprintf("{%s}%s", scheme, base64.StdEncoding.EncodeToString(append(h.Sum(nil), salt...))), nil
+	case "PBKDF2-SHA256", "PBKDF2-SHA512":
+		newHash, size := sha256.New, sha256.Size
+		if scheme == "PBKDF2-SHA512" {
+			newHash, size = sha512.New, sha512.Size
+		}
+		key := pbkdf2.Key([]byte(password), salt, iterations, size, newHash)
+		encode := func(b []byte) string {
+			return strings.ReplaceAll(ab64.EncodeToString(b), "+", ".")
+		}
+		return fmt.Sprintf("{%s}%d$%s$%s", scheme, iterations, encode(salt), encode(key)), nil
+	case "CRYPT":
+		return "{CRYPT}" + sha512Crypt(password, string(salt), iterations), nil
+	case "ARGON2":
+		return "{ARGON2}" + argon2Hash(password, salt, defaultArgon2Params), nil
+	}
+	return "", fmt.Errorf("unsupported password scheme %q, expected one of %s", scheme, strings.Join(PasswordSchemes, ", "))
+}
+
+func saltedHash(scheme string) hash.Hash {
+	switch scheme {
+	case "SSHA256":
+		return sha256.New()
+	case "SSHA512":
+		return sha512.New()
+	}
+	return sha1.New()
+}
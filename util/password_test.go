@@ -0,0 +1,56 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	for _, scheme := range PasswordSchemes {
+		hashed, err := HashPassword(scheme, "secret")
+		if err != nil {
+			t.Fatalf("Unexpected error hashing with %s: %v", scheme, err)
+		}
+		if !strings.HasPrefix(hashed, "{"+scheme+"}") {
+			t.Errorf("Missing scheme prefix for %s, got %s", scheme, hashed)
+		}
+		if !VerifyPassword(hashed, "secret") {
+			t.Errorf("Expected %s to match its password", hashed)
+		}
+		if VerifyPassword(hashed, "Secret") {
+			t.Errorf("Expected %s not to match another password", hashed)
+		}
+		if other, _ := HashPassword(scheme, "secret"); other == hashed {
+			t.Errorf("Expected %s hashes to be salted", scheme)
+		}
+	}
+	if _, err := HashPassword("MD5", "secret"); err == nil {
+		t.Errorf("Expected an error for an unsupported scheme")
+	}
+}
+
+func TestHashPasswordKnownSalt(t *testing.T) {
+	hashed, err := hashPassword("CRYPT", "password", []byte("saltsalt"), sha512CryptRounds)
+	if err != nil || hashed != "{CRYPT}$6$saltsalt$qFmFH.bQmmtXzyBY0s9v7Oicd2z4XSIecDzlB5KiA2/jctKu9YterLp8wwnSq.qc.eoxqOmSuNp2xS0ktL3nh/" {
+		t.Errorf("Unexpected CRYPT hash %s (%v)", hashed, err)
+	}
+	if hashed, _ := hashPassword("SSHA", "secret", []byte("12345678"), pbkdf2Iterations); hashed != "{SSHA}tCNGqyJLk/uvKpCa4vga5GB2gWoxMjM0NTY3OA==" {
+		t.Errorf("Unexpected SSHA hash %s", hashed)
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	// "secret" with salt "12345678"
+	if !VerifyPassword("{SSHA}tCNGqyJLk/uvKpCa4vga5GB2gWoxMjM0NTY3OA==", "secret") {
+		t.Errorf("Expected the SSHA hash to match")
+	}
+	// "password" with salt "salt", as computed by hashlib.pbkdf2_hmac
+	if !VerifyPassword("{PBKDF2-SHA256}4096$c2FsdA$xeR41ZKIyEGqUw22hFxMjZYok6ABzk4RpJY4c6qYE0o", "password") {
+		t.Errorf("Expected the PBKDF2 hash to match")
+	}
+	for _, invalid := range []string{"", "secret", "{SSHA}", "{SSHA}!!", "{MD5}Xr4ilOzQ4PCOq3aQ0qbuaQ==", "{PBKDF2-SHA256}x$c2FsdA$AA"} {
+		if VerifyPassword(invalid, "secret") {
+			t.Errorf("Expected %q not to match", invalid)
+		}
+	}
+}
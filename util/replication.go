@@ -0,0 +1,72 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseContextCSN parses an OpenLDAP change sequence number such as
+// 20210401120000.123456Z#000000#001#000000, as found in contextCSN and
+// entryCSN, into its time and the (hexadecimal) ID of the server the change
+// originates from.
+func ParseContextCSN(csn string) (time.Time, string, error) {
+	parts := strings.Split(csn, "#")
+	if len(parts) != 4 {
+		return time.Time{}, "", fmt.Errorf("invalid CSN %q", csn)
+	}
+	t, err := time.Parse("20060102150405.000000Z", parts[0])
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid time in CSN %q: %v", csn, err)
+	}
+	return t, parts[2], nil
+}
+
+// RUVElement is a replica of a 389 Directory Server replica update vector
+// (nsds50ruv), with the CSNs of the first and last changes it originated.
+type RUVElement struct {
+	ReplicaID string
+	URL       string
+	MinCSN    string
+	MaxCSN    string
+}
+
+// ParseRUVElement parses an nsds50ruv value such as
+// "{replica 1 ldap://host:389} 5f8a1234000000010000 5f8b5678000000010000".
+// The replica generation value, which describes no replica, yields false.
+func ParseRUVElement(value string) (RUVElement, bool) {
+	end := strings.IndexByte(value, '}')
+	if !strings.HasPrefix(value, "{replica ") || end < 0 {
+		return RUVElement{}, false
+	}
+	header := strings.Fields(value[len("{replica "):end])
+	csns := strings.Fields(value[end+1:])
+	if len(header) == 0 {
+		return RUVElement{}, false
+	}
+	element := RUVElement{ReplicaID: header[0]}
+	if len(header) > 1 {
+		element.URL = header[1]
+	}
+	if len(csns) > 0 {
+		element.MinCSN = csns[0]
+	}
+	if len(csns) > 1 {
+		element.MaxCSN = csns[1]
+	}
+	return element, true
+}
+
+// DS389CSNTime returns the time of a 389 Directory Server CSN, whose first
+// 8 hexadecimal digits are a Unix timestamp.
+func DS389CSNTime(csn string) (time.Time, error) {
+	if len(csn) < 8 {
+		return time.Time{}, fmt.Errorf("invalid CSN %q", csn)
+	}
+	seconds, err := strconv.ParseInt(csn[:8], 16, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid CSN %q: %v", csn, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
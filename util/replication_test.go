@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseContextCSN(t *testing.T) {
+	parsed, serverID, err := ParseContextCSN("20210401120000.123456Z#000000#001#000000")
+	if err != nil || serverID != "001" || !parsed.Equal(time.Date(2021, 4, 1, 12, 0, 0, 123456000, time.UTC)) {
+		t.Errorf("Invalid parsing, got %v %q (%v)", parsed, serverID, err)
+	}
+	for _, csn := range []string{"", "20210401120000Z", "yesterday#000000#001#000000"} {
+		if _, _, err := ParseContextCSN(csn); err == nil {
+			t.Errorf("Expected an error for %q", csn)
+		}
+	}
+}
+
+func TestParseRUVElement(t *testing.T) {
+	element, ok := ParseRUVElement("{replica 1 ldap://ds1.example.com:389} 5f8a1234000000010000 5f8b5678000000010000")
+	expected := RUVElement{ReplicaID: "1", URL: "ldap://ds1.example.com:389", MinCSN: "5f8a1234000000010000", MaxCSN: "5f8b5678000000010000"}
+	if !ok || element != expected {
+		t.Errorf("Invalid parsing, got %+v", element)
+	}
+	if element, ok := ParseRUVElement("{replica 2 ldap://ds2.example.com:389}"); !ok || element.ReplicaID != "2" || element.MaxCSN != "" {
+		t.Errorf("Invalid parsing of a replica without changes, got %+v", element)
+	}
+	if _, ok := ParseRUVElement("{replicageneration} 5f8a1234000000010000"); ok {
+		t.Errorf("Expected the replica generation to be skipped")
+	}
+}
+
+func TestDS389CSNTime(t *testing.T) {
+	if parsed, err := DS389CSNTime("5f8b5678000000010000"); err != nil || parsed.Unix() != 0x5f8b5678 {
+		t.Errorf("Invalid parsing, got %v (%v)", parsed, err)
+	}
+	if _, err := DS389CSNTime("zz"); err == nil {
+		t.Errorf("Expected an error for an invalid CSN")
+	}
+}
@@ -0,0 +1,210 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ldifLine = regexp.MustCompile(`(?m)^[A-Za-z][A-Za-z0-9-]*:`)
+
+// ParseSchema extracts the attribute type and object class definitions from
+// either an OpenLDAP .schema file (attributetype/objectclass directives) or
+// an LDIF file (attributeTypes/olcAttributeTypes and objectClasses/
+// olcObjectClasses attributes), in the order in which they appear.
+func ParseSchema(text string) ([]string, []string, error) {
+	attributeTypes, objectClasses := []string{}, []string{}
+
+	// first unfold the text into logical lines: in .schema files a
+	// definition continues on lines starting with whitespace, while in LDIF
+	// a line is folded by inserting a newline and exactly one space
+	ldif := ldifLine.MatchString(text)
+	lines := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			if ldif {
+				lines[len(lines)-1] += strings.TrimPrefix(line, " ")
+			} else {
+				lines[len(lines)-1] += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		keyword, definition := splitSchemaLine(line)
+		switch strings.ToLower(keyword) {
+		case "attributetype", "attributetypes", "olcattributetypes":
+			_, definition = SplitOrderedValue(definition)
+			if DefinitionOID(definition) == "" {
+				return nil, nil, fmt.Errorf("invalid attribute type definition %q", definition)
+			}
+			attributeTypes = append(attributeTypes, NormalizeDefinition(definition))
+		case "objectclass", "objectclasses", "olcobjectclasses":
+			_, definition = SplitOrderedValue(definition)
+			if DefinitionOID(definition) == "" {
+				// in LDIF "objectClass: olcSchemaConfig" is the class of the
+				// entry itself, not a definition
+				if !strings.HasPrefix(definition, "(") && strings.EqualFold(keyword, "objectclass") {
+					continue
+				}
+				return nil, nil, fmt.Errorf("invalid object class definition %q", definition)
+			}
+			objectClasses = append(objectClasses, NormalizeDefinition(definition))
+		}
+	}
+	return attributeTypes, objectClasses, nil
+}
+
+// splitSchemaLine splits a line into its leading keyword (either a .schema
+// directive or an LDIF attribute name) and the rest of the line.
+func splitSchemaLine(line string) (string, string) {
+	i := strings.IndexAny(line, ": \t")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[i:]), ":"))
+}
+
+// DefinitionOID returns the numeric OID (or OID macro) of an attribute type
+// or object class definition, i.e. the first token after the opening
+// parenthesis, or an empty string if the definition is malformed.
+func DefinitionOID(definition string) string {
+	_, definition = SplitOrderedValue(strings.TrimSpace(definition))
+	if !strings.HasPrefix(definition, "(") {
+		return ""
+	}
+	fields := strings.Fields(definition[1:])
+	if len(fields) == 0 || fields[0] == ")" {
+		return ""
+	}
+	return fields[0]
+}
+
+// NormalizeDefinition collapses all the whitespace in a schema definition,
+// so that definitions differing only in layout compare equal.
+func NormalizeDefinition(definition string) string {
+	return strings.Join(strings.Fields(definition), " ")
+}
+
+// ParseDefinition parses an RFC 4512 schema definition (attribute type,
+// object class, matching rule, syntax...) into its numeric OID and its fields
+// by keyword, e.g. NAME, SUP, MUST or SINGLE-VALUE. Quoted strings are
+// unescaped, lists such as ( cn $ sn ) or ( 'a' 'b' ) yield several values
+// and flags yield no value.
+func ParseDefinition(definition string) (string, map[string][]string, error) {
+	_, definition = SplitOrderedValue(strings.TrimSpace(definition))
+	tokens, err := definitionTokens(definition)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) < 3 || tokens[0] != "(" || tokens[len(tokens)-1] != ")" {
+		return "", nil, fmt.Errorf("malformed definition %q", definition)
+	}
+	oid := tokens[1]
+	tokens = tokens[2 : len(tokens)-1]
+
+	fields := map[string][]string{}
+	for len(tokens) > 0 {
+		keyword := tokens[0]
+		tokens = tokens[1:]
+		values := []string{}
+		switch {
+		case len(tokens) > 0 && tokens[0] == "(":
+			end := 1
+			for end < len(tokens) && tokens[end] != ")" {
+				if tokens[end] != "$" {
+					values = append(values, unquoteDefinitionToken(tokens[end]))
+				}
+				end++
+			}
+			if end == len(tokens) {
+				return "", nil, fmt.Errorf("unterminated list in definition %q", definition)
+			}
+			tokens = tokens[end+1:]
+		case len(tokens) > 0 && !isDefinitionKeyword(tokens[0]):
+			values = append(values, unquoteDefinitionToken(tokens[0]))
+			tokens = tokens[1:]
+		}
+		if existing, ok := fields[keyword]; ok {
+			values = append(existing, values...)
+		}
+		fields[keyword] = values
+	}
+	return oid, fields, nil
+}
+
+// definitionTokens splits a schema definition into parentheses, dollar
+// signs, quoted strings (quotes included) and bare words.
+func definitionTokens(definition string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(definition); {
+		switch c := definition[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '$':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'':
+			end := strings.IndexByte(definition[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in definition %q", definition)
+			}
+			tokens = append(tokens, definition[i:i+end+2])
+			i += end + 2
+		default:
+			end := i
+			for end < len(definition) && !strings.ContainsRune(" \t\n\r()$'", rune(definition[end])) {
+				end++
+			}
+			tokens = append(tokens, definition[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// isDefinitionKeyword reports whether a token is a keyword (e.g. NAME,
+// SINGLE-VALUE or X-ORDERED) rather than a value: values are quoted, OIDs or
+// names, which may not be all uppercase.
+func isDefinitionKeyword(token string) bool {
+	if strings.HasPrefix(token, "'") {
+		return false
+	}
+	for _, r := range token {
+		if !(r >= 'A' && r <= 'Z' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+var definitionEscape = regexp.MustCompile(`\\(?i:27|5c)`)
+
+// unquoteDefinitionToken removes the quotes around a quoted string, and the
+// \27 and \5C escapes of quotes and backslashes it may contain.
+func unquoteDefinitionToken(token string) string {
+	if len(token) < 2 || token[0] != '\'' {
+		return token
+	}
+	return definitionEscape.ReplaceAllStringFunc(token[1:len(token)-1], func(escape string) string {
+		if escape == `\27` {
+			return "'"
+		}
+		return `\`
+	})
+}
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSchemaFile(t *testing.T) {
+	text := `# example schema
+attributetype ( 1.3.6.1.4.1.99999.1.1 NAME 'exampleBadge'
+ DESC 'Badge number'
+	EQUALITY caseIgnoreMatch
+	SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 SINGLE-VALUE )
+
+objectclass ( 1.3.6.1.4.1.99999.2.1 NAME 'examplePerson'
+	SUP inetOrgPerson STRUCTURAL
+	MAY exampleBadge )
+`
+	attributeTypes, objectClasses, err := ParseSchema(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(attributeTypes, []string{"( 1.3.6.1.4.1.99999.1.1 NAME 'exampleBadge' DESC 'Badge number' EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 SINGLE-VALUE )"}) {
+		t.Errorf("Invalid attribute types, got %v", attributeTypes)
+	}
+	if !reflect.DeepEqual(objectClasses, []string{"( 1.3.6.1.4.1.99999.2.1 NAME 'examplePerson' SUP inetOrgPerson STRUCTURAL MAY exampleBadge )"}) {
+		t.Errorf("Invalid object classes, got %v", objectClasses)
+	}
+}
+
+func TestParseSchemaLDIF(t *testing.T) {
+	text := `dn: cn=example,cn=schema,cn=config
+objectClass: olcSchemaConfig
+cn: example
+olcAttributeTypes: {0}( 1.3.6.1.4.1.99999.1.1 NAME 'exampleBadge' SYNTAX 1.3.
+ 6.1.4.1.1466.115.121.1.15 )
+olcObjectClasses: ( 1.3.6.1.4.1.99999.2.1 NAME 'examplePerson' AUXILIARY MAY e
+ xampleBadge )
+`
+	attributeTypes, objectClasses, err := ParseSchema(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(attributeTypes, []string{"( 1.3.6.1.4.1.99999.1.1 NAME 'exampleBadge' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )"}) {
+		t.Errorf("Invalid attribute types, got %v", attributeTypes)
+	}
+	if !reflect.DeepEqual(objectClasses, []string{"( 1.3.6.1.4.1.99999.2.1 NAME 'examplePerson' AUXILIARY MAY exampleBadge )"}) {
+		t.Errorf("Invalid object classes, got %v", objectClasses)
+	}
+}
+
+func TestParseSchemaInvalid(t *testing.T) {
+	if _, _, err := ParseSchema("attributetype NAME 'broken'"); err == nil {
+		t.Error("Expected an error parsing an invalid definition")
+	}
+}
+
+func TestDefinitionOID(t *testing.T) {
+	if oid := DefinitionOID("{3}( 2.5.4.3 NAME 'cn' )"); oid != "2.5.4.3" {
+		t.Errorf("Invalid OID, got %q", oid)
+	}
+	if oid := DefinitionOID("NAME 'cn'"); oid != "" {
+		t.Errorf("Expected no OID, got %q", oid)
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	oid, fields, err := ParseDefinition("( 2.5.6.6 NAME 'person' DESC 'RFC2256: a person' SUP top STRUCTURAL MUST ( sn $ cn ) MAY ( userPassword $ telephoneNumber ) )")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string][]string{
+		"NAME":       {"person"},
+		"DESC":       {"RFC2256: a person"},
+		"SUP":        {"top"},
+		"STRUCTURAL": {},
+		"MUST":       {"sn", "cn"},
+		"MAY":        {"userPassword", "telephoneNumber"},
+	}
+	if oid != "2.5.6.6" || !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Invalid object class, got %q and %v", oid, fields)
+	}
+
+	oid, fields, err = ParseDefinition(`{3}( 1.2.3 NAME ( 'a' 'b' ) DESC 'it\27s \5C' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{256} SINGLE-VALUE X-ORDERED 'VALUES' )`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = map[string][]string{
+		"NAME":         {"a", "b"},
+		"DESC":         {`it's \`},
+		"SYNTAX":       {"1.3.6.1.4.1.1466.115.121.1.15{256}"},
+		"SINGLE-VALUE": {},
+		"X-ORDERED":    {"VALUES"},
+	}
+	if oid != "1.2.3" || !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Invalid attribute type, got %q and %v", oid, fields)
+	}
+
+	for _, definition := range []string{"1.2.3 NAME 'a'", "( 1.2.3 NAME 'a )", "( 1.2.3 MUST ( a $ b )"} {
+		if _, _, err := ParseDefinition(definition); err == nil {
+			t.Errorf("Expected an error for %q", definition)
+		}
+	}
+}
@@ -188,3 +188,28 @@ func (s *Set) String() string {
 	buffer.WriteRune('}')
 	return buffer.String()
 }
+
+// DiffValues returns the values only in old and those only in new, in their
+// order of appearance, hashing the values so that lists of thousands of
+// values (e.g. the members of a large group) are compared in linear time.
+func DiffValues(old, new []string) (removed, added []string) {
+	oldValues := make(map[string]struct{}, len(old))
+	for _, value := range old {
+		oldValues[value] = struct{}{}
+	}
+	newValues := make(map[string]struct{}, len(new))
+	for _, value := range new {
+		newValues[value] = struct{}{}
+	}
+	for _, value := range old {
+		if _, ok := newValues[value]; !ok {
+			removed = append(removed, value)
+		}
+	}
+	for _, value := range new {
+		if _, ok := oldValues[value]; !ok {
+			added = append(added, value)
+		}
+	}
+	return removed, added
+}
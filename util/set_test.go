@@ -1,6 +1,9 @@
 package util
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func setup() (*Set, *Set) {
 	s1 := &Set{
@@ -116,3 +119,14 @@ func TestToString(t *testing.T) {
 		t.Errorf("Invalid string, got %s", s1.String())
 	}
 }
+
+func TestDiffValues(t *testing.T) {
+	removed, added := DiffValues([]string{"a", "b", "c", "d"}, []string{"d", "e", "b", "f"})
+	if !reflect.DeepEqual(removed, []string{"a", "c"}) || !reflect.DeepEqual(added, []string{"e", "f"}) {
+		t.Errorf("Invalid diff, removed %v and added %v", removed, added)
+	}
+	removed, added = DiffValues([]string{"a"}, []string{"a"})
+	if len(removed) != 0 || len(added) != 0 {
+		t.Errorf("Expected no difference, removed %v and added %v", removed, added)
+	}
+}
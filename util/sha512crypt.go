@@ -0,0 +1,142 @@
+package util
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	cryptAlphabet       = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	sha512CryptRounds   = 5000
+	sha512CryptSaltSize = 16
+)
+
+// the order in which sha512-crypt encodes the bytes of the final digest,
+// three at a time
+var sha512CryptPermutation = [][3]int{
+	{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+	{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+	{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+}
+
+// sha512Crypt returns the $6$ crypt(3) hash of a password, as specified by
+// Ulrich Drepper's "Unix crypt using SHA-256 and SHA-512". The salt is
+// truncated to 16 characters; rounds other than the default 5000 are
+// recorded in the hash.
+func sha512Crypt(password, salt string, rounds int) string {
+	if len(salt) > sha512CryptSaltSize {
+		salt = salt[:sha512CryptSaltSize]
+	}
+	pw, s := []byte(password), []byte(salt)
+
+	b := sha512.New()
+	b.Write(pw)
+	b.Write(s)
+	b.Write(pw)
+	digestB := b.Sum(nil)
+
+	a := sha512.New()
+	a.Write(pw)
+	a.Write(s)
+	i := len(pw)
+	for ; i > 64; i -= 64 {
+		a.Write(digestB)
+	}
+	a.Write(digestB[:i])
+	for i = len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(pw)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := sha512.New()
+	for i = 0; i < len(pw); i++ {
+		dp.Write(pw)
+	}
+	p := repeatBytes(dp.Sum(nil), len(pw))
+
+	ds := sha512.New()
+	for i = 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(s)
+	}
+	sp := repeatBytes(ds.Sum(nil), len(s))
+
+	c := digestA
+	for i = 0; i < rounds; i++ {
+		h := sha512.New()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sp)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString("$6$")
+	if rounds != sha512CryptRounds {
+		fmt.Fprintf(&out, "rounds=%d$", rounds)
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	encode := func(b2, b1, b0 byte, n int) {
+		w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+		for ; n > 0; n-- {
+			out.WriteByte(cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	for _, t := range sha512CryptPermutation {
+		encode(c[t[0]], c[t[1]], c[t[2]], 4)
+	}
+	encode(0, 0, c[63], 2)
+	return out.String()
+}
+
+// parseSHA512Crypt returns the salt and rounds of a $6$ crypt(3) hash.
+func parseSHA512Crypt(hashed string) (string, int, bool) {
+	if !strings.HasPrefix(hashed, "$6$") {
+		return "", 0, false
+	}
+	parts := strings.Split(hashed[3:], "$")
+	rounds := sha512CryptRounds
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "rounds=") {
+		n, err := strconv.Atoi(strings.TrimPrefix(parts[0], "rounds="))
+		if err != nil || n <= 0 {
+			return "", 0, false
+		}
+		rounds, parts = n, parts[1:]
+	}
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+	return parts[0], rounds, true
+}
+
+// repeatBytes returns b repeated up to n bytes.
+func repeatBytes(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		if n-len(out) < len(b) {
+			return append(out, b[:n-len(out)]...)
+		}
+		out = append(out, b...)
+	}
+	return out
+}
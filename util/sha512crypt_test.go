@@ -0,0 +1,33 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSHA512Crypt(t *testing.T) {
+	// as computed by openssl passwd -6 and crypt(3)
+	cases := []struct {
+		password, salt string
+		rounds         int
+		expected       string
+	}{
+		{"password", "saltsalt", 5000, "$6$saltsalt$qFmFH.bQmmtXzyBY0s9v7Oicd2z4XSIecDzlB5KiA2/jctKu9YterLp8wwnSq.qc.eoxqOmSuNp2xS0ktL3nh/"},
+		{"Hello world!", "saltstringsaltstring", 10000, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{strings.Repeat("x", 100), "abc", 5000, "$6$abc$hJifFvspkMHRMTXYpDbrug4nrXgZmPzTx/f9.5eCxJlDdjBTMZHgOVcLnSdECLAAJM9qZtSos/rWPoZcqcKDx0"},
+	}
+	for _, c := range cases {
+		if actual := sha512Crypt(c.password, c.salt, c.rounds); actual != c.expected {
+			t.Errorf("Expected %s for %q, got %s", c.expected, c.password, actual)
+		}
+		salt, rounds, ok := parseSHA512Crypt(c.expected)
+		if !ok || rounds != c.rounds || salt != c.salt[:len(salt)] {
+			t.Errorf("Unexpected salt %q and rounds %d parsing %s", salt, rounds, c.expected)
+		}
+	}
+	for _, invalid := range []string{"", "$5$salt$hash", "$6$rounds=x$salt$hash", "$6$salt"} {
+		if _, _, ok := parseSHA512Crypt(invalid); ok {
+			t.Errorf("Expected %q not to parse", invalid)
+		}
+	}
+}
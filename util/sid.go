@@ -0,0 +1,80 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSID converts the textual representation of a security identifier
+// (e.g. S-1-5-21-1004336348-1177238915-682003330-512) into the binary form
+// stored by Active Directory in the objectSid attribute.
+func ParseSID(sid string) ([]byte, error) {
+	parts := strings.Split(sid, "-")
+	if len(parts) < 3 || !strings.EqualFold(parts[0], "S") {
+		return nil, fmt.Errorf("invalid SID %q", sid)
+	}
+	revision, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid revision in SID %q: %v", sid, err)
+	}
+	authority, err := strconv.ParseUint(parts[2], 10, 48)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identifier authority in SID %q: %v", sid, err)
+	}
+	subAuthorities := parts[3:]
+	if len(subAuthorities) > 15 {
+		return nil, fmt.Errorf("too many sub-authorities in SID %q", sid)
+	}
+
+	b := make([]byte, 8+4*len(subAuthorities))
+	b[0] = byte(revision)
+	b[1] = byte(len(subAuthorities))
+	// the identifier authority is a 48-bit big-endian value
+	for i := 0; i < 6; i++ {
+		b[2+i] = byte(authority >> (8 * uint(5-i)))
+	}
+	// while sub-authorities are 32-bit little-endian values
+	for i, s := range subAuthorities {
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sub-authority %q in SID %q: %v", s, sid, err)
+		}
+		binary.LittleEndian.PutUint32(b[8+4*i:], uint32(v))
+	}
+	return b, nil
+}
+
+// SIDToString converts the binary form of a security identifier, as found in
+// the objectSid attribute, into its textual representation.
+func SIDToString(b []byte) (string, error) {
+	if len(b) < 8 {
+		return "", fmt.Errorf("invalid SID: expected at least 8 bytes, got %d", len(b))
+	}
+	count := int(b[1])
+	if len(b) != 8+4*count {
+		return "", fmt.Errorf("invalid SID: expected %d bytes for %d sub-authorities, got %d", 8+4*count, count, len(b))
+	}
+	var authority uint64
+	for i := 0; i < 6; i++ {
+		authority = authority<<8 | uint64(b[2+i])
+	}
+	var buffer strings.Builder
+	buffer.WriteString(fmt.Sprintf("S-%d-%d", b[0], authority))
+	for i := 0; i < count; i++ {
+		buffer.WriteString(fmt.Sprintf("-%d", binary.LittleEndian.Uint32(b[8+4*i:])))
+	}
+	return buffer.String(), nil
+}
+
+// EscapeFilterBytes escapes every byte of a binary value so that it can be
+// used as an assertion value in an LDAP search filter, e.g. when looking up
+// an object by objectSid or objectGUID.
+func EscapeFilterBytes(b []byte) string {
+	var buffer strings.Builder
+	for _, c := range b {
+		buffer.WriteString(fmt.Sprintf("\\%02x", c))
+	}
+	return buffer.String()
+}
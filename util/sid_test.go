@@ -0,0 +1,65 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+var domainAdmins = []byte{
+	0x01, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+	0x15, 0x00, 0x00, 0x00,
+	0xdc, 0xf4, 0xdc, 0x3b,
+	0x83, 0x3d, 0x2b, 0x46,
+	0x82, 0x8b, 0xa6, 0x28,
+	0x00, 0x02, 0x00, 0x00,
+}
+
+func TestParseSID(t *testing.T) {
+	b, err := ParseSID("S-1-5-21-1004336348-1177238915-682003330-512")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing SID: %v", err)
+	}
+	if !bytes.Equal(b, domainAdmins) {
+		t.Errorf("Invalid binary SID, got %x", b)
+	}
+	for _, invalid := range []string{"", "S-1", "X-1-5-21", "S-1-5-abc", "S-1-5-4294967296"} {
+		if _, err := ParseSID(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestSIDToString(t *testing.T) {
+	s, err := SIDToString(domainAdmins)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding SID: %v", err)
+	}
+	if s != "S-1-5-21-1004336348-1177238915-682003330-512" {
+		t.Errorf("Invalid SID string, got %s", s)
+	}
+	if _, err := SIDToString(domainAdmins[:10]); err == nil {
+		t.Error("Expected an error decoding a truncated SID")
+	}
+}
+
+func TestSIDRoundTrip(t *testing.T) {
+	for _, sid := range []string{"S-1-1-0", "S-1-5-32-544", "S-1-5-18"} {
+		b, err := ParseSID(sid)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", sid, err)
+		}
+		s, err := SIDToString(b)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding %q: %v", sid, err)
+		}
+		if s != sid {
+			t.Errorf("Invalid round trip, expected %s got %s", sid, s)
+		}
+	}
+}
+
+func TestEscapeFilterBytes(t *testing.T) {
+	if s := EscapeFilterBytes([]byte{0x01, 0xab, 0x2a}); s != "\\01\\ab\\2a" {
+		t.Errorf("Invalid escaped value, got %s", s)
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSortKey parses a server-side sort key of the form [-]attribute[:rule],
+// e.g. "sn", "-uidNumber" or "cn:caseIgnoreOrderingMatch", where a leading
+// minus sign reverses the order and rule is the ordering rule to sort with.
+func ParseSortKey(key string) (attribute, orderingRule string, reverse bool, err error) {
+	if strings.HasPrefix(key, "-") {
+		reverse = true
+		key = key[1:]
+	}
+	attribute = key
+	if i := strings.Index(key, ":"); i >= 0 {
+		attribute, orderingRule = key[:i], key[i+1:]
+		if orderingRule == "" {
+			return "", "", false, fmt.Errorf("empty ordering rule in sort key %q", key)
+		}
+	}
+	if attribute == "" {
+		return "", "", false, fmt.Errorf("missing attribute in sort key %q", key)
+	}
+	return attribute, orderingRule, reverse, nil
+}
@@ -0,0 +1,26 @@
+package util
+
+import "testing"
+
+func TestParseSortKey(t *testing.T) {
+	tests := []struct {
+		key, attribute, orderingRule string
+		reverse                      bool
+	}{
+		{"sn", "sn", "", false},
+		{"-uidNumber", "uidNumber", "", true},
+		{"cn:caseIgnoreOrderingMatch", "cn", "caseIgnoreOrderingMatch", false},
+		{"-cn:2.5.13.3", "cn", "2.5.13.3", true},
+	}
+	for _, test := range tests {
+		attribute, orderingRule, reverse, err := ParseSortKey(test.key)
+		if err != nil || attribute != test.attribute || orderingRule != test.orderingRule || reverse != test.reverse {
+			t.Errorf("Invalid parsing of %q, got %q %q %t (%v)", test.key, attribute, orderingRule, reverse, err)
+		}
+	}
+	for _, key := range []string{"", "-", ":caseIgnoreOrderingMatch", "cn:"} {
+		if _, _, _, err := ParseSortKey(key); err == nil {
+			t.Errorf("Expected an error for %q", key)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// the offset of Active Directory's FILETIME epoch (1601-01-01) from the Unix
+// one, in seconds
+const fileTimeEpochOffset = 11644473600
+
+// ParseTimestamp parses an LDAP GeneralizedTime (e.g. 20210401120000Z or
+// 20210401120000.0+0200) or an Active Directory FILETIME, the number of
+// 100-nanosecond ticks since 1601 stored in attributes such as
+// lastLogonTimestamp or accountExpires. The FILETIME values meaning "never",
+// 0 and the maximum int64, yield the zero time.
+func ParseTimestamp(value string) (time.Time, error) {
+	if ticks, err := strconv.ParseInt(value, 10, 64); err == nil && len(value) != 14 {
+		if ticks == 0 || ticks == math.MaxInt64 {
+			return time.Time{}, nil
+		}
+		return time.Unix(ticks/10000000-fileTimeEpochOffset, ticks%10000000*100).UTC(), nil
+	}
+	t, err := time.Parse("20060102150405Z0700", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %v", value, err)
+	}
+	return t, nil
+}
+
+// FormatGeneralizedTime formats a time as an LDAP GeneralizedTime in UTC,
+// e.g. 20210401120000Z, keeping fractions of a second when there are any.
+func FormatGeneralizedTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.999999999Z")
+}
+
+// FileTime converts a time into an Active Directory FILETIME, the number of
+// 100-nanosecond ticks since 1601-01-01.
+func FileTime(t time.Time) int64 {
+	return (t.Unix()+fileTimeEpochOffset)*10000000 + int64(t.Nanosecond()/100)
+}
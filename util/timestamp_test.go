@@ -0,0 +1,48 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := map[string]time.Time{
+		"20210401120000Z":     time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC),
+		"20210401120000.0Z":   time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC),
+		"20210401140000+0200": time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC),
+		"132617520000000000":  time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC),
+		"0":                   {},
+		"9223372036854775807": {},
+	}
+	for value, expected := range tests {
+		if parsed, err := ParseTimestamp(value); err != nil || !parsed.Equal(expected) {
+			t.Errorf("Invalid parsing of %q, got %v (%v)", value, parsed, err)
+		}
+	}
+	if _, err := ParseTimestamp("yesterday"); err == nil {
+		t.Errorf("Expected an error for an invalid timestamp")
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	noon := time.Date(2021, 4, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	if formatted := FormatGeneralizedTime(noon); formatted != "20210401120000Z" {
+		t.Errorf("Unexpected GeneralizedTime %s", formatted)
+	}
+	if formatted := FormatGeneralizedTime(noon.Add(250 * time.Millisecond)); formatted != "20210401120000.25Z" {
+		t.Errorf("Unexpected GeneralizedTime %s", formatted)
+	}
+	if ticks := FileTime(noon); ticks != 132617520000000000 {
+		t.Errorf("Unexpected FILETIME %d", ticks)
+	}
+	if ticks := FileTime(time.Unix(-fileTimeEpochOffset, 100)); ticks != 1 {
+		t.Errorf("Unexpected FILETIME %d at the epoch", ticks)
+	}
+	for _, value := range []string{"20210401120000.25Z", "132617520001234567"} {
+		parsed, _ := ParseTimestamp(value)
+		if FormatGeneralizedTime(parsed) != value && fmt.Sprint(FileTime(parsed)) != value {
+			t.Errorf("Expected %q to round-trip", value)
+		}
+	}
+}
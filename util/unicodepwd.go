@@ -0,0 +1,11 @@
+package util
+
+import "golang.org/x/text/encoding/unicode"
+
+// EncodeUnicodePwd encodes a password as Active Directory expects it in the
+// unicodePwd attribute: enclosed in double quotes and encoded in UTF-16LE,
+// without a byte order mark.
+func EncodeUnicodePwd(password string) (string, error) {
+	utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	return utf16.NewEncoder().String("\"" + password + "\"")
+}
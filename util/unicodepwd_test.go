@@ -0,0 +1,13 @@
+package util
+
+import "testing"
+
+func TestEncodeUnicodePwd(t *testing.T) {
+	encoded, err := EncodeUnicodePwd("Pä1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "\"\x00P\x00\xe4\x001\x00\"\x00"; encoded != expected {
+		t.Errorf("Expected %q, got %q", expected, encoded)
+	}
+}